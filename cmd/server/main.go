@@ -7,11 +7,13 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"domaincheck/internal/config"
 	"domaincheck/internal/handlers"
+	"domaincheck/internal/models"
 	"domaincheck/internal/services"
 
 	"github.com/gin-contrib/cors"
@@ -34,12 +36,60 @@ func main() {
 		log.Fatalf("Failed to initialize domain service: %v", err)
 	}
 
+	jobManager, err := services.NewJobManager(domainService, cfg.Jobs)
+	if err != nil {
+		log.Fatalf("Failed to initialize job manager: %v", err)
+	}
+
+	watchlistManager, err := services.NewWatchlistManager(domainService, cfg.Watchlist)
+	if err != nil {
+		log.Fatalf("Failed to initialize watchlist: %v", err)
+	}
+
+	webhookManager, err := services.NewWebhookManager(domainService, cfg.Webhooks)
+	if err != nil {
+		log.Fatalf("Failed to initialize webhooks: %v", err)
+	}
+
+	portfolioManager, err := services.NewPortfolioManager(domainService, cfg.Portfolio)
+	if err != nil {
+		log.Fatalf("Failed to initialize portfolio: %v", err)
+	}
+
 	// Initialize handlers
-	domainHandler := handlers.NewDomainHandler(domainService)
-	wsHandler := handlers.NewWebSocketHandler(domainService)
+	domainHandler := handlers.NewDomainHandler(domainService, jobManager)
+	wsHandler := handlers.NewWebSocketHandler(domainService, jobManager)
+	jobHandler := handlers.NewJobHandler(jobManager)
+	watchlistHandler := handlers.NewWatchlistHandler(watchlistManager)
+	webhookHandler := handlers.NewWebhookHandler(webhookManager)
+	portfolioHandler := handlers.NewPortfolioHandler(portfolioManager)
+
+	// Push watchlist events to connected WebSocket clients
+	watchlistManager.OnEvent(func(event models.WatchEvent) {
+		wsHandler.BroadcastToAll(models.WebSocketMessage{
+			Type: "watchlist_event",
+			Data: event,
+		})
+	})
+
+	// Push renewal reminders to connected WebSocket clients
+	portfolioManager.OnReminder(func(reminder models.PortfolioReminder) {
+		wsHandler.BroadcastToAll(models.WebSocketMessage{
+			Type: "portfolio_reminder",
+			Data: reminder,
+		})
+	})
+
+	// Notify webhooks of watchlist events, renewal reminders and finished jobs
+	watchlistManager.OnEvent(webhookManager.HandleWatchEvent)
+	portfolioManager.OnReminder(webhookManager.HandleRenewalReminder)
+	jobManager.OnFinish(webhookManager.HandleJobFinished)
+
+	// Resume interrupted jobs now that their listeners are registered
+	jobManager.Start()
 
 	// Setup router
-	router := setupRouter(cfg, domainHandler, wsHandler)
+	router := setupRouter(cfg, domainHandler, wsHandler, jobHandler, watchlistHandler, webhookHandler, portfolioHandler)
 
 	// Setup server
 	srv := &http.Server{
@@ -47,12 +97,13 @@ func main() {
 		Handler:      router,
 		ReadTimeout:  cfg.Server.ReadTimeout,
 		WriteTimeout: cfg.Server.WriteTimeout,
+		ConnContext:  handlers.ConnContext, // Lets streaming routes move the deadlines above
 	}
 
 	// Start server in goroutine
 	go func() {
 		log.Printf("🚀 Domain Check API Server starting on %s", cfg.Server.Port)
-		log.Printf("📁 Domain extensions loaded from: %s", cfg.Domain.ExtensionsFile)
+		log.Printf("📁 %d domain extensions loaded from: %s", len(domainService.GetValidExtensions()), cfg.Domain.ExtensionsFile)
 		log.Printf("⚙️  Max concurrent domain checks: %d", cfg.Domain.MaxConcurrentChecks)
 		log.Printf("⏱️  Domain check timeout: %s", cfg.Domain.Timeout)
 
@@ -76,10 +127,32 @@ func main() {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
 
+	// Stop jobs and scheduled checks before the services they use; checkpointed jobs resume on the next start
+	if err := jobManager.Close(); err != nil {
+		log.Printf("Failed to close job manager: %v", err)
+	}
+
+	if err := watchlistManager.Close(); err != nil {
+		log.Printf("Failed to close watchlist: %v", err)
+	}
+
+	if err := portfolioManager.Close(); err != nil {
+		log.Printf("Failed to close portfolio: %v", err)
+	}
+
+	// Pending deliveries are retried on the next start
+	if err := webhookManager.Close(); err != nil {
+		log.Printf("Failed to close webhooks: %v", err)
+	}
+
+	if err := domainService.Close(); err != nil {
+		log.Printf("Failed to close domain service: %v", err)
+	}
+
 	log.Println("✅ Server exited")
 }
 
-func setupRouter(cfg *config.Config, domainHandler *handlers.DomainHandler, wsHandler *handlers.WebSocketHandler) *gin.Engine {
+func setupRouter(cfg *config.Config, domainHandler *handlers.DomainHandler, wsHandler *handlers.WebSocketHandler, jobHandler *handlers.JobHandler, watchlistHandler *handlers.WatchlistHandler, webhookHandler *handlers.WebhookHandler, portfolioHandler *handlers.PortfolioHandler) *gin.Engine {
 	router := gin.New()
 
 	// Middleware
@@ -105,6 +178,16 @@ func setupRouter(cfg *config.Config, domainHandler *handlers.DomainHandler, wsHa
 		c.Next()
 	})
 
+	// Cache bypass middleware: "Cache-Control: no-cache" forces fresh domain checks
+	router.Use(func(c *gin.Context) {
+		cacheControl := strings.ToLower(c.GetHeader("Cache-Control"))
+		if strings.Contains(cacheControl, "no-cache") || strings.Contains(cacheControl, "max-age=0") ||
+			strings.EqualFold(c.GetHeader("Pragma"), "no-cache") {
+			c.Request = c.Request.WithContext(services.WithoutCache(c.Request.Context()))
+		}
+		c.Next()
+	})
+
 	// Static files for frontend (optional)
 	if _, err := os.Stat("./frontend/dist"); err == nil {
 		router.Static("/static", "./frontend/dist/static")
@@ -125,6 +208,11 @@ func setupRouter(cfg *config.Config, domainHandler *handlers.DomainHandler, wsHa
 					"check":      "POST /api/check-domain",
 					"history":    "GET /api/domains",
 					"extensions": "GET /api/v1/extensions",
+					"presets":    "GET /api/v1/extensions/presets",
+					"jobs":       "POST /api/v1/jobs",
+					"watchlist":  "GET /api/v1/watchlist",
+					"webhooks":   "GET /api/v1/webhooks",
+					"portfolio":  "GET /api/v1/portfolio",
 					"websocket":  "WS /ws",
 				},
 			})
@@ -132,7 +220,7 @@ func setupRouter(cfg *config.Config, domainHandler *handlers.DomainHandler, wsHa
 	}
 
 	// Setup all API routes
-	handlers.SetupRoutes(router, cfg, domainHandler, wsHandler)
+	handlers.SetupRoutes(router, cfg, domainHandler, wsHandler, jobHandler, watchlistHandler, webhookHandler, portfolioHandler)
 
 	return router
 }
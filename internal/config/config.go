@@ -10,10 +10,20 @@ import (
 
 // Config represents the application configuration
 type Config struct {
-	Server ServerConfig `yaml:"server"`
-	CORS   CORSConfig   `yaml:"cors"`
-	Domain DomainConfig `yaml:"domain"`
-	Log    LogConfig    `yaml:"logging"`
+	Server           ServerConfig           `yaml:"server"`
+	CORS             CORSConfig             `yaml:"cors"`
+	Domain           DomainConfig           `yaml:"domain"`
+	ExtensionPresets ExtensionPresetsConfig `yaml:"extension_presets"`
+	Resolvers        ResolverConfig         `yaml:"resolvers"`
+	Cache            CacheConfig            `yaml:"cache"`
+	RateLimit        RateLimitConfig        `yaml:"rate_limit"`
+	Retry            RetryConfig            `yaml:"retry"`
+	History          HistoryConfig          `yaml:"history"`
+	Jobs             JobsConfig             `yaml:"jobs"`
+	Watchlist        WatchlistConfig        `yaml:"watchlist"`
+	Webhooks         WebhooksConfig         `yaml:"webhooks"`
+	Portfolio        PortfolioConfig        `yaml:"portfolio"`
+	Log              LogConfig              `yaml:"logging"`
 }
 
 // ServerConfig represents server configuration
@@ -22,6 +32,10 @@ type ServerConfig struct {
 	Host         string        `yaml:"host"`
 	ReadTimeout  time.Duration `yaml:"read_timeout"`
 	WriteTimeout time.Duration `yaml:"write_timeout"`
+	// StreamTimeout replaces the read and write timeouts on streaming routes:
+	// each read of the request and each write of the response gets this long,
+	// so a stream is not cut off while it makes progress. 0 disables it.
+	StreamTimeout time.Duration `yaml:"stream_timeout"`
 }
 
 // CORSConfig represents CORS configuration
@@ -36,6 +50,181 @@ type DomainConfig struct {
 	ExtensionsFile      string        `yaml:"extensions_file"`
 	Timeout             time.Duration `yaml:"timeout"`
 	MaxConcurrentChecks int           `yaml:"max_concurrent_checks"`
+	// MaxUploadDomains limits how many domains an uploaded list may contain
+	MaxUploadDomains int `yaml:"max_upload_domains"`
+	// Strategy is the ordered list of checkers tried for every domain
+	Strategy []string `yaml:"strategy"`
+	// TLDStrategies overrides Strategy for specific extensions (e.g. ".com")
+	TLDStrategies map[string][]string `yaml:"tld_strategies"`
+	// RDAPBootstrapFile is the IANA RDAP bootstrap registry for domain names
+	RDAPBootstrapFile string `yaml:"rdap_bootstrap_file"`
+	// PublicSuffixFile is the Public Suffix List used to split domains into name and
+	// extension (e.g. ".co.uk"); without it the extension is the last label
+	PublicSuffixFile string `yaml:"public_suffix_file"`
+}
+
+// ResolverConfig represents the upstream DNS resolver pool configuration
+type ResolverConfig struct {
+	Upstreams []UpstreamConfig `yaml:"upstreams"`
+	Timeout   time.Duration    `yaml:"timeout"`
+	// MaxFailureRate is the failure rate above which a resolver is ejected
+	MaxFailureRate float64 `yaml:"max_failure_rate"`
+	// MinSamples is the number of queries observed before a resolver can be ejected
+	MinSamples int `yaml:"min_samples"`
+	// EjectionDuration is how long an unhealthy resolver is kept out of rotation
+	EjectionDuration time.Duration `yaml:"ejection_duration"`
+}
+
+// UpstreamConfig represents a single upstream DNS resolver
+type UpstreamConfig struct {
+	Address    string `yaml:"address"`     // host:port, or the endpoint URL for DoH
+	Protocol   string `yaml:"protocol"`    // "udp", "tcp", "dot" or "doh"
+	ServerName string `yaml:"server_name"` // TLS server name for DoT
+}
+
+// CacheConfig represents the domain check result cache configuration
+type CacheConfig struct {
+	// Enabled turns the cache on; repeated checks of a domain are answered from memory
+	Enabled bool `yaml:"enabled"`
+	// PositiveTTL is how long registered (and other taken) results are kept
+	PositiveTTL time.Duration `yaml:"positive_ttl"`
+	// NegativeTTL is how long available results are kept
+	NegativeTTL time.Duration `yaml:"negative_ttl"`
+	// MaxEntries limits the number of cached results; the least recently used are evicted
+	MaxEntries int `yaml:"max_entries"`
+}
+
+// RateLimitConfig represents outbound query rate limiting configuration
+type RateLimitConfig struct {
+	// Enabled turns the limits on; servers signalling rate limiting are backed off either way
+	Enabled bool `yaml:"enabled"`
+	// TLDs limits lookups per extension (e.g. ".com")
+	TLDs RateLimitScope `yaml:"tlds"`
+	// Servers limits queries per WHOIS or RDAP server host
+	Servers RateLimitScope `yaml:"servers"`
+	// Resolvers limits queries per upstream resolver address
+	Resolvers RateLimitScope `yaml:"resolvers"`
+	// MaxWait is how long a check waits for its turn before it is reported as rate limited
+	MaxWait time.Duration `yaml:"max_wait"`
+	// InitialBackoff is how long a server that signals rate limiting is left alone; it doubles while the server keeps doing so
+	InitialBackoff time.Duration `yaml:"initial_backoff"`
+	// MaxBackoff caps the backoff, unless the server asks for a longer one
+	MaxBackoff time.Duration `yaml:"max_backoff"`
+}
+
+// RateLimitScope represents the limits of one kind of upstream
+type RateLimitScope struct {
+	// Default applies to every key without an override
+	Default RateLimit `yaml:"default"`
+	// Overrides holds the limits of specific TLDs, server hosts or resolver addresses
+	Overrides map[string]RateLimit `yaml:"overrides"`
+}
+
+// RateLimit represents a token bucket; a zero rate means unlimited
+type RateLimit struct {
+	Rate  float64 `yaml:"rate"`  // Requests per second
+	Burst int     `yaml:"burst"` // Requests allowed at once after a quiet period
+}
+
+// RetryConfig represents how checker lookups that fail for transient reasons are retried
+type RetryConfig struct {
+	// MaxAttempts is how often a checker is tried for a domain, including the first attempt
+	MaxAttempts int `yaml:"max_attempts"`
+	// InitialBackoff is the wait before the first retry; it doubles with every further attempt
+	InitialBackoff time.Duration `yaml:"initial_backoff"`
+	// MaxBackoff caps the wait between attempts
+	MaxBackoff time.Duration `yaml:"max_backoff"`
+	// Jitter is the share of every wait that is randomized, between 0 and 1
+	Jitter float64 `yaml:"jitter"`
+	// Classes overrides MaxAttempts per error class (e.g. "timeout", "servfail", "refused"); 1 disables retries
+	Classes map[string]int `yaml:"classes"`
+}
+
+// HistoryConfig represents check history storage configuration
+type HistoryConfig struct {
+	Backend    string        `yaml:"backend"`     // "memory" or "bolt"
+	Path       string        `yaml:"path"`        // Database file for the bolt backend
+	MaxEntries int           `yaml:"max_entries"` // Zero keeps any number of entries
+	MaxAge     time.Duration `yaml:"max_age"`     // Zero keeps entries forever
+}
+
+// JobsConfig represents asynchronous bulk check job configuration
+type JobsConfig struct {
+	// Retention is how long finished jobs and their results are kept
+	Retention time.Duration `yaml:"retention"`
+	// Dir is where jobs are checkpointed so they resume after a restart; empty keeps them in memory
+	Dir string `yaml:"dir"`
+}
+
+// WatchlistConfig represents watched domain monitoring configuration
+type WatchlistConfig struct {
+	// Path is the database file for the watchlist and its transitions; empty keeps them in memory
+	Path string `yaml:"path"`
+	// DefaultInterval is how often a domain is re-checked unless its entry sets an interval
+	DefaultInterval time.Duration `yaml:"default_interval"`
+	// MinInterval is the shortest interval an entry may set
+	MinInterval time.Duration `yaml:"min_interval"`
+	// MaxConcurrentChecks limits how many watched domains are re-checked at once
+	MaxConcurrentChecks int `yaml:"max_concurrent_checks"`
+	// ExpiryWarning is how long before its expiration date a watched domain triggers an event
+	ExpiryWarning time.Duration `yaml:"expiry_warning"`
+}
+
+// WebhooksConfig represents outbound webhook delivery configuration
+type WebhooksConfig struct {
+	// Path is the database file for webhooks and their delivery log; empty keeps them in memory
+	Path string `yaml:"path"`
+	// Timeout limits a single delivery attempt
+	Timeout time.Duration `yaml:"timeout"`
+	// MaxAttempts is how often a delivery is tried before it is marked failed
+	MaxAttempts int `yaml:"max_attempts"`
+	// InitialBackoff is the wait before the first retry; it doubles with every further attempt
+	InitialBackoff time.Duration `yaml:"initial_backoff"`
+	// MaxBackoff caps the wait between retries
+	MaxBackoff time.Duration `yaml:"max_backoff"`
+	// DeliveryRetention is how long finished deliveries are kept in the log
+	DeliveryRetention time.Duration `yaml:"delivery_retention"`
+	// ErrorRateThreshold is the checker failure rate that triggers an event
+	ErrorRateThreshold float64 `yaml:"error_rate_threshold"`
+	// ErrorRateMinChecks is the number of checks a checker must make before its error rate counts
+	ErrorRateMinChecks int `yaml:"error_rate_min_checks"`
+	// ErrorRateWindow is the period the checker error rate is measured over
+	ErrorRateWindow time.Duration `yaml:"error_rate_window"`
+}
+
+// PortfolioConfig represents owned domain expiration tracking configuration
+type PortfolioConfig struct {
+	// Path is the database file for owned domains; empty keeps them in memory
+	Path string `yaml:"path"`
+	// RefreshInterval is how often the expiration date of an owned domain is looked up again
+	RefreshInterval time.Duration `yaml:"refresh_interval"`
+	// ReminderDays are the days before expiration at which a renewal reminder is sent
+	ReminderDays []int `yaml:"reminder_days"`
+	// MaxConcurrentChecks limits how many owned domains are looked up at once
+	MaxConcurrentChecks int `yaml:"max_concurrent_checks"`
+}
+
+// ExtensionPresetsConfig represents the named extension sets bulk checks can be limited to
+type ExtensionPresetsConfig struct {
+	// Path is the database file for presets created via the API; empty keeps them in memory
+	Path string `yaml:"path"`
+	// Presets are built in; they cannot be changed or deleted via the API
+	Presets map[string]ExtensionPresetConfig `yaml:"presets"`
+}
+
+// ExtensionPresetConfig defines a preset by a list of extensions, by filters on
+// the extension metadata, or both; extensions matching the filters join the list
+type ExtensionPresetConfig struct {
+	Description string   `yaml:"description"`
+	Extensions  []string `yaml:"extensions"`
+	Types       []string `yaml:"types"`      // gTLD, ccTLD or new gTLD
+	Categories  []string `yaml:"categories"` // e.g. "technology" or "europe"
+	// MaxPopularity selects the ranked extensions within the top N
+	MaxPopularity int `yaml:"max_popularity"`
+	// MaxPrice selects the extensions with a typical price up to this amount
+	MaxPrice   float64 `yaml:"max_price"`
+	IDN        *bool   `yaml:"idn"`
+	Restricted *bool   `yaml:"restricted"`
 }
 
 // LogConfig represents logging configuration
@@ -80,20 +269,104 @@ func Get() *Config {
 		// Load default config if not loaded
 		defaultConfig := &Config{
 			Server: ServerConfig{
-				Port:         ":8080",
-				Host:         "localhost",
-				ReadTimeout:  10 * time.Second,
-				WriteTimeout: 10 * time.Second,
+				Port:          ":8080",
+				Host:          "localhost",
+				ReadTimeout:   10 * time.Second,
+				WriteTimeout:  10 * time.Second,
+				StreamTimeout: time.Minute,
 			},
 			CORS: CORSConfig{
 				AllowedOrigins: []string{"http://localhost:3000", "http://localhost:8080"},
-				AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-				AllowedHeaders: []string{"Origin", "Content-Type", "Accept", "Authorization"},
+				AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+				AllowedHeaders: []string{"Origin", "Content-Type", "Accept", "Authorization", "Cache-Control"},
 			},
 			Domain: DomainConfig{
-				ExtensionsFile:      "./data/domain_extensions.txt",
+				ExtensionsFile:      "./data/domain_extensions.yaml",
 				Timeout:             5 * time.Second,
 				MaxConcurrentChecks: 10,
+				MaxUploadDomains:    500000,
+				Strategy:            []string{"dns"},
+				RDAPBootstrapFile:   "./data/rdap_bootstrap.json",
+				PublicSuffixFile:    "./data/public_suffix_list.dat",
+			},
+			Resolvers: ResolverConfig{
+				Upstreams: []UpstreamConfig{
+					{Address: "8.8.8.8:53", Protocol: "udp"},
+					{Address: "1.1.1.1:53", Protocol: "udp"},
+					{Address: "208.67.222.222:53", Protocol: "udp"},
+				},
+				Timeout:          2 * time.Second,
+				MaxFailureRate:   0.5,
+				MinSamples:       5,
+				EjectionDuration: 30 * time.Second,
+			},
+			Cache: CacheConfig{
+				Enabled:     true,
+				PositiveTTL: 10 * time.Minute,
+				NegativeTTL: time.Minute,
+				MaxEntries:  10000,
+			},
+			RateLimit: RateLimitConfig{
+				Enabled:        true,
+				Servers:        RateLimitScope{Default: RateLimit{Rate: 5, Burst: 10}},
+				MaxWait:        10 * time.Second,
+				InitialBackoff: 30 * time.Second,
+				MaxBackoff:     10 * time.Minute,
+			},
+			Retry: RetryConfig{
+				MaxAttempts:    3,
+				InitialBackoff: 100 * time.Millisecond,
+				MaxBackoff:     time.Second,
+				Jitter:         0.5,
+			},
+			History: HistoryConfig{
+				Backend:    "memory",
+				MaxEntries: 1000,
+			},
+			Jobs: JobsConfig{
+				Retention: time.Hour,
+			},
+			Watchlist: WatchlistConfig{
+				DefaultInterval:     6 * time.Hour,
+				MinInterval:         5 * time.Minute,
+				MaxConcurrentChecks: 5,
+				ExpiryWarning:       30 * 24 * time.Hour,
+			},
+			Webhooks: WebhooksConfig{
+				Timeout:            10 * time.Second,
+				MaxAttempts:        6,
+				InitialBackoff:     10 * time.Second,
+				MaxBackoff:         time.Hour,
+				DeliveryRetention:  7 * 24 * time.Hour,
+				ErrorRateThreshold: 0.5,
+				ErrorRateMinChecks: 20,
+				ErrorRateWindow:    5 * time.Minute,
+			},
+			Portfolio: PortfolioConfig{
+				RefreshInterval:     24 * time.Hour,
+				ReminderDays:        []int{60, 30, 7, 1},
+				MaxConcurrentChecks: 3,
+			},
+			ExtensionPresets: ExtensionPresetsConfig{
+				Presets: map[string]ExtensionPresetConfig{
+					"popular": {
+						Description:   "The 20 most registered extensions",
+						MaxPopularity: 20,
+					},
+					"tech": {
+						Description: "Extensions popular with technology companies",
+						Extensions:  []string{".ai", ".co", ".xyz"},
+						Categories:  []string{"technology"},
+					},
+					"europe": {
+						Description: "European country code extensions",
+						Categories:  []string{"europe"},
+					},
+					"cheap": {
+						Description: "Extensions typically registered for 10 USD or less",
+						MaxPrice:    10,
+					},
+				},
 			},
 			Log: LogConfig{
 				Level:  "info",
@@ -111,6 +384,10 @@ func validateConfig(cfg *Config) error {
 		return fmt.Errorf("server port is required")
 	}
 
+	if cfg.Server.StreamTimeout < 0 {
+		return fmt.Errorf("server stream timeout must not be negative")
+	}
+
 	if cfg.Domain.ExtensionsFile == "" {
 		return fmt.Errorf("domain extensions file path is required")
 	}
@@ -123,5 +400,125 @@ func validateConfig(cfg *Config) error {
 		return fmt.Errorf("max concurrent checks must be positive")
 	}
 
+	if cfg.Domain.MaxUploadDomains < 0 {
+		return fmt.Errorf("max upload domains must not be negative")
+	}
+
+	for _, upstream := range cfg.Resolvers.Upstreams {
+		if upstream.Address == "" {
+			return fmt.Errorf("resolver address is required")
+		}
+		switch upstream.Protocol {
+		case "", "udp", "tcp", "dot", "doh":
+		default:
+			return fmt.Errorf("unsupported resolver protocol %q for %s", upstream.Protocol, upstream.Address)
+		}
+	}
+
+	if cfg.Resolvers.MaxFailureRate < 0 || cfg.Resolvers.MaxFailureRate > 1 {
+		return fmt.Errorf("resolver max failure rate must be between 0 and 1")
+	}
+
+	if cfg.Cache.PositiveTTL < 0 || cfg.Cache.NegativeTTL < 0 || cfg.Cache.MaxEntries < 0 {
+		return fmt.Errorf("cache TTLs and max entries must not be negative")
+	}
+
+	for name, scope := range map[string]RateLimitScope{
+		"tld": cfg.RateLimit.TLDs, "server": cfg.RateLimit.Servers, "resolver": cfg.RateLimit.Resolvers,
+	} {
+		if scope.Default.Rate < 0 || scope.Default.Burst < 0 {
+			return fmt.Errorf("default %s rate limit must not be negative", name)
+		}
+		for key, limit := range scope.Overrides {
+			if limit.Rate < 0 || limit.Burst < 0 {
+				return fmt.Errorf("%s rate limit for %s must not be negative", name, key)
+			}
+		}
+	}
+
+	if cfg.RateLimit.MaxWait < 0 || cfg.RateLimit.InitialBackoff < 0 || cfg.RateLimit.MaxBackoff < 0 {
+		return fmt.Errorf("rate limit durations must not be negative")
+	}
+
+	if cfg.Retry.MaxAttempts < 0 || cfg.Retry.InitialBackoff < 0 || cfg.Retry.MaxBackoff < 0 {
+		return fmt.Errorf("retry attempts and backoffs must not be negative")
+	}
+
+	if cfg.Retry.Jitter < 0 || cfg.Retry.Jitter > 1 {
+		return fmt.Errorf("retry jitter must be between 0 and 1")
+	}
+
+	for class, attempts := range cfg.Retry.Classes {
+		if attempts <= 0 {
+			return fmt.Errorf("retry attempts for %s must be positive", class)
+		}
+	}
+
+	switch cfg.History.Backend {
+	case "", "memory":
+	case "bolt":
+		if cfg.History.Path == "" {
+			return fmt.Errorf("history path is required for the bolt backend")
+		}
+	default:
+		return fmt.Errorf("unsupported history backend: %s", cfg.History.Backend)
+	}
+
+	if cfg.History.MaxEntries < 0 || cfg.History.MaxAge < 0 {
+		return fmt.Errorf("history retention limits must not be negative")
+	}
+
+	if cfg.Jobs.Retention < 0 {
+		return fmt.Errorf("job retention must not be negative")
+	}
+
+	if cfg.Watchlist.DefaultInterval < 0 || cfg.Watchlist.MinInterval < 0 {
+		return fmt.Errorf("watchlist intervals must not be negative")
+	}
+
+	if cfg.Watchlist.MinInterval > 0 && cfg.Watchlist.DefaultInterval > 0 && cfg.Watchlist.DefaultInterval < cfg.Watchlist.MinInterval {
+		return fmt.Errorf("watchlist default interval must not be shorter than the minimum interval")
+	}
+
+	if cfg.Watchlist.MaxConcurrentChecks < 0 {
+		return fmt.Errorf("watchlist max concurrent checks must not be negative")
+	}
+
+	if cfg.Watchlist.ExpiryWarning < 0 {
+		return fmt.Errorf("watchlist expiry warning must not be negative")
+	}
+
+	if cfg.Webhooks.Timeout < 0 || cfg.Webhooks.InitialBackoff < 0 || cfg.Webhooks.MaxBackoff < 0 ||
+		cfg.Webhooks.DeliveryRetention < 0 || cfg.Webhooks.ErrorRateWindow < 0 {
+		return fmt.Errorf("webhook durations must not be negative")
+	}
+
+	if cfg.Webhooks.MaxAttempts < 0 || cfg.Webhooks.ErrorRateMinChecks < 0 {
+		return fmt.Errorf("webhook limits must not be negative")
+	}
+
+	if cfg.Webhooks.ErrorRateThreshold < 0 || cfg.Webhooks.ErrorRateThreshold > 1 {
+		return fmt.Errorf("webhook error rate threshold must be between 0 and 1")
+	}
+
+	if cfg.Portfolio.RefreshInterval < 0 || cfg.Portfolio.MaxConcurrentChecks < 0 {
+		return fmt.Errorf("portfolio refresh interval and max concurrent checks must not be negative")
+	}
+
+	for _, days := range cfg.Portfolio.ReminderDays {
+		if days <= 0 {
+			return fmt.Errorf("portfolio reminder days must be positive")
+		}
+	}
+
+	for name, preset := range cfg.ExtensionPresets.Presets {
+		if name == "" {
+			return fmt.Errorf("extension preset names must not be empty")
+		}
+		if preset.MaxPopularity < 0 || preset.MaxPrice < 0 {
+			return fmt.Errorf("extension preset %s: max popularity and max price must not be negative", name)
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,62 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+
+	"domaincheck/internal/models"
+)
+
+// CSVWriter writes results as CSV with a header row
+type CSVWriter struct {
+	writer *csv.Writer
+	record []string
+}
+
+// NewCSVWriter creates a new CSV writer and writes the header row
+func NewCSVWriter(w io.Writer) (*CSVWriter, error) {
+	writer := csv.NewWriter(w)
+
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = col.name
+	}
+	if err := writer.Write(header); err != nil {
+		return nil, err
+	}
+
+	return &CSVWriter{writer: writer, record: make([]string, len(columns))}, nil
+}
+
+// Write appends one result as a CSV record
+func (w *CSVWriter) Write(domain *models.Domain) error {
+	for i, col := range columns {
+		value := col.value(domain)
+		if col.kind == cellString {
+			value = escapeFormula(value)
+		}
+		w.record[i] = value
+	}
+	return w.writer.Write(w.record)
+}
+
+// Flush pushes buffered records to the underlying writer
+func (w *CSVWriter) Flush() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// Close flushes the remaining records
+func (w *CSVWriter) Close() error {
+	return w.Flush()
+}
+
+// escapeFormula prevents spreadsheets from evaluating text that came from
+// remote servers (e.g. WHOIS error messages) as a formula
+func escapeFormula(value string) string {
+	if value != "" && strings.ContainsAny(value[:1], "=+-@\t\r") {
+		return "'" + value
+	}
+	return value
+}
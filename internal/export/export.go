@@ -0,0 +1,131 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"domaincheck/internal/models"
+)
+
+// Format identifies an export file format
+type Format string
+
+// Supported export formats
+const (
+	FormatCSV   Format = "csv"
+	FormatJSONL Format = "jsonl"
+	FormatXLSX  Format = "xlsx"
+)
+
+// Writer streams domain check results into an export file
+type Writer interface {
+	// Write appends one result
+	Write(domain *models.Domain) error
+	// Flush pushes buffered rows to the underlying writer
+	Flush() error
+	// Close finishes the file; it does not close the underlying writer
+	Close() error
+}
+
+// ParseFormat validates an export format name
+func ParseFormat(name string) (Format, error) {
+	switch format := Format(strings.ToLower(name)); format {
+	case FormatCSV, FormatJSONL, FormatXLSX:
+		return format, nil
+	case "":
+		return FormatCSV, nil
+	default:
+		return "", fmt.Errorf("unsupported export format: %s", name)
+	}
+}
+
+// ContentType returns the MIME type of the format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatJSONL:
+		return "application/x-ndjson"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+// NewWriter creates a writer for the format; sheet names the XLSX worksheet
+func NewWriter(format Format, w io.Writer, sheet string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return NewCSVWriter(w)
+	case FormatJSONL:
+		return NewJSONLWriter(w), nil
+	case FormatXLSX:
+		return NewXLSXWriter(w, sheet)
+	default:
+		return nil, fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+// cellKind is the spreadsheet type of a column
+type cellKind int
+
+const (
+	cellString cellKind = iota
+	cellNumber
+	cellBool
+)
+
+// column describes one exported field of models.Domain
+type column struct {
+	name  string
+	kind  cellKind
+	value func(d *models.Domain) string
+}
+
+// columns lists every models.Domain field in export order
+var columns = []column{
+	{"id", cellNumber, func(d *models.Domain) string { return strconv.Itoa(d.ID) }},
+	{"name", cellString, func(d *models.Domain) string { return d.Name }},
+	{"unicode_name", cellString, func(d *models.Domain) string { return d.UnicodeName }},
+	{"extension", cellString, func(d *models.Domain) string { return d.Extension }},
+	{"subdomain", cellString, func(d *models.Domain) string { return d.Subdomain }},
+	{"available", cellBool, func(d *models.Domain) string { return strconv.FormatBool(d.Available) }},
+	{"status", cellString, func(d *models.Domain) string { return d.Status }},
+	{"availability", cellString, func(d *models.Domain) string { return string(d.Availability) }},
+	{"confidence", cellString, func(d *models.Domain) string { return string(d.Confidence) }},
+	{"evidence", cellString, formatEvidence},
+	{"attempts", cellString, formatAttempts},
+	{"ip", cellString, func(d *models.Domain) string { return d.IP }},
+	{"dns_resolved", cellBool, func(d *models.Domain) string { return strconv.FormatBool(d.DNSResolved) }},
+	{"checker", cellString, func(d *models.Domain) string { return d.Checker }},
+	{"delegation", cellString, func(d *models.Domain) string { return d.Delegation }},
+	{"checked_at", cellString, func(d *models.Domain) string { return d.CheckedAt.UTC().Format(time.RFC3339) }},
+	{"response_time_ms", cellNumber, func(d *models.Domain) string { return strconv.FormatInt(d.ResponseTime, 10) }},
+	{"error", cellString, func(d *models.Domain) string { return d.Error }},
+	{"cached", cellBool, func(d *models.Domain) string { return strconv.FormatBool(d.Cached) }},
+	{"cache_age", cellNumber, func(d *models.Domain) string { return strconv.FormatInt(d.CacheAge, 10) }},
+}
+
+// formatEvidence flattens the evidence list into a single cell
+func formatEvidence(d *models.Domain) string {
+	parts := make([]string, len(d.Evidence))
+	for i, evidence := range d.Evidence {
+		parts[i] = evidence.Source + ": " + evidence.Detail
+	}
+	return strings.Join(parts, "; ")
+}
+
+// formatAttempts flattens the checker attempts into a single cell
+func formatAttempts(d *models.Domain) string {
+	parts := make([]string, len(d.Attempts))
+	for i, attempt := range d.Attempts {
+		outcome := "ok"
+		if attempt.Error != "" {
+			outcome = attempt.Error
+		}
+		parts[i] = attempt.Checker + " #" + strconv.Itoa(attempt.Attempt) + ": " + outcome
+	}
+	return strings.Join(parts, "; ")
+}
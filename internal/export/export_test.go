@@ -0,0 +1,31 @@
+package export
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"domaincheck/internal/models"
+)
+
+// TestColumnsCoverDomain keeps the export columns in step with the JSON
+// fields of models.Domain, so a new field cannot be left out of CSV and XLSX
+func TestColumnsCoverDomain(t *testing.T) {
+	var fields []string
+	domainType := reflect.TypeOf(models.Domain{})
+	for i := 0; i < domainType.NumField(); i++ {
+		name := strings.Split(domainType.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			fields = append(fields, name)
+		}
+	}
+
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = column.name
+	}
+
+	if !reflect.DeepEqual(names, fields) {
+		t.Errorf("export columns = %v\nwant the models.Domain fields %v", names, fields)
+	}
+}
@@ -0,0 +1,36 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+
+	"domaincheck/internal/models"
+)
+
+// JSONLWriter writes results as JSON Lines, one models.Domain object per line
+type JSONLWriter struct {
+	buffer  *bufio.Writer
+	encoder *json.Encoder
+}
+
+// NewJSONLWriter creates a new JSON Lines writer
+func NewJSONLWriter(w io.Writer) *JSONLWriter {
+	buffer := bufio.NewWriter(w)
+	return &JSONLWriter{buffer: buffer, encoder: json.NewEncoder(buffer)}
+}
+
+// Write appends one result; the encoder terminates each object with a newline
+func (w *JSONLWriter) Write(domain *models.Domain) error {
+	return w.encoder.Encode(domain)
+}
+
+// Flush pushes buffered lines to the underlying writer
+func (w *JSONLWriter) Flush() error {
+	return w.buffer.Flush()
+}
+
+// Close flushes the remaining lines
+func (w *JSONLWriter) Close() error {
+	return w.Flush()
+}
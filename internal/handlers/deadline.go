@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// connContextKey is the request context key of the connection a request arrived on
+type connContextKey struct{}
+
+// ConnContext stores the connection of a request in its context, so
+// streaming routes can replace the deadlines the server set on it.
+// Use it as http.Server.ConnContext.
+func ConnContext(ctx context.Context, conn net.Conn) context.Context {
+	return context.WithValue(ctx, connContextKey{}, conn)
+}
+
+// streamDeadlines exempts a streaming route from the server-wide read and
+// write timeouts: instead, every read of the request body and every write
+// of the response must complete within timeout, so a stream lasts as long
+// as it makes progress. A zero timeout removes the deadlines.
+func streamDeadlines(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		conn, ok := c.Request.Context().Value(connContextKey{}).(net.Conn)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		d := &connDeadline{conn: conn, timeout: timeout}
+		d.extendRead()
+		d.extendWrite()
+		c.Request.Body = &deadlineReader{ReadCloser: c.Request.Body, deadline: d}
+		c.Writer = &deadlineWriter{ResponseWriter: c.Writer, deadline: d}
+		c.Next()
+	}
+}
+
+// connDeadline moves the deadlines of a connection forward
+type connDeadline struct {
+	conn    net.Conn
+	timeout time.Duration
+}
+
+// next returns the deadline of an operation starting now
+func (d *connDeadline) next() time.Time {
+	if d.timeout <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(d.timeout)
+}
+
+func (d *connDeadline) extendRead() {
+	d.conn.SetReadDeadline(d.next())
+}
+
+func (d *connDeadline) extendWrite() {
+	d.conn.SetWriteDeadline(d.next())
+}
+
+// deadlineReader extends the read deadline before every read of a request body
+type deadlineReader struct {
+	io.ReadCloser
+	deadline *connDeadline
+}
+
+func (r *deadlineReader) Read(p []byte) (int, error) {
+	r.deadline.extendRead()
+	return r.ReadCloser.Read(p)
+}
+
+// deadlineWriter extends the write deadline before every write or flush of a
+// response, as either may send buffered data to the client
+type deadlineWriter struct {
+	gin.ResponseWriter
+	deadline *connDeadline
+}
+
+func (w *deadlineWriter) Write(data []byte) (int, error) {
+	w.deadline.extendWrite()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *deadlineWriter) WriteString(s string) (int, error) {
+	w.deadline.extendWrite()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *deadlineWriter) Flush() {
+	w.deadline.extendWrite()
+	w.ResponseWriter.Flush()
+}
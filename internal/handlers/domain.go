@@ -1,8 +1,8 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
-	"strconv"
 	"time"
 
 	"domaincheck/internal/models"
@@ -14,13 +14,15 @@ import (
 // DomainHandler handles domain-related HTTP requests
 type DomainHandler struct {
 	domainService *services.DomainService
+	jobManager    *services.JobManager
 	startTime     time.Time
 }
 
 // NewDomainHandler creates a new domain handler
-func NewDomainHandler(domainService *services.DomainService) *DomainHandler {
+func NewDomainHandler(domainService *services.DomainService, jobManager *services.JobManager) *DomainHandler {
 	return &DomainHandler{
 		domainService: domainService,
+		jobManager:    jobManager,
 		startTime:     time.Now(),
 	}
 }
@@ -87,9 +89,7 @@ func (h *DomainHandler) CheckDomain(c *gin.Context) {
 func (h *DomainHandler) CheckAllExtensions(c *gin.Context) {
 	startTime := time.Now()
 
-	var request struct {
-		DomainName string `json:"domain_name" binding:"required"`
-	}
+	var request models.CheckAllExtensionsRequest
 
 	if err := c.ShouldBindJSON(&request); err != nil {
 		c.JSON(http.StatusBadRequest, models.APIResponse{
@@ -100,8 +100,8 @@ func (h *DomainHandler) CheckAllExtensions(c *gin.Context) {
 		return
 	}
 
-	// Check domain name with all extensions
-	result, err := h.domainService.CheckAllExtensions(c.Request.Context(), request.DomainName)
+	// Check domain name with the selected extensions as a job and wait for it
+	result, err := h.jobManager.CheckAllExtensions(c.Request.Context(), request.DomainName, request.ExtensionSelection)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, models.APIResponse{
 			Success: false,
@@ -169,60 +169,68 @@ func (h *DomainHandler) CheckMultipleDomains(c *gin.Context) {
 	})
 }
 
-// GetDomainHistory returns domain check history
+// GetDomainHistory returns domain check history, optionally filtered and sorted
 func (h *DomainHandler) GetDomainHistory(c *gin.Context) {
-	// Parse query parameters
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "20"))
+	var query models.HistoryQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid query parameters",
+			Error:   err.Error(),
+		})
+		return
+	}
 
-	if page < 1 {
-		page = 1
+	if query.Page < 1 {
+		query.Page = 1
 	}
-	if perPage < 1 || perPage > 100 {
-		perPage = 20
+	if query.PerPage < 1 || query.PerPage > 100 {
+		query.PerPage = 20
 	}
 
-	// Get history
-	history := h.domainService.GetDomainHistory()
-	total := len(history)
-
-	// Calculate pagination
-	totalPages := (total + perPage - 1) / perPage
-	start := (page - 1) * perPage
-	end := start + perPage
-
-	if start > total {
-		start = total
-	}
-	if end > total {
-		end = total
+	// Search history
+	result, err := h.domainService.SearchHistory(query)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrInvalidHistoryQuery) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, models.APIResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
 	}
 
-	// Get page data
-	var pageData []models.Domain
-	if start < end {
-		pageData = history[start:end]
-	} else {
-		pageData = []models.Domain{}
+	meta := &models.Meta{
+		Total:      result.Total,
+		PerPage:    query.PerPage,
+		TotalPages: (result.Total + query.PerPage - 1) / query.PerPage,
+		NextCursor: result.NextCursor,
+		RequestID:  c.GetHeader("X-Request-ID"),
+	}
+	// Page numbers are meaningless when paging by cursor
+	if query.Cursor == "" {
+		meta.Page = query.Page
 	}
 
 	c.JSON(http.StatusOK, models.APIResponse{
 		Success: true,
-		Data:    pageData,
+		Data:    result.Items,
 		Message: "Domain history retrieved successfully",
-		Meta: &models.Meta{
-			Total:      total,
-			Page:       page,
-			PerPage:    perPage,
-			TotalPages: totalPages,
-			RequestID:  c.GetHeader("X-Request-ID"),
-		},
+		Meta:    meta,
 	})
 }
 
 // ClearHistory clears domain check history
 func (h *DomainHandler) ClearHistory(c *gin.Context) {
-	h.domainService.ClearHistory()
+	if err := h.domainService.ClearHistory(); err != nil {
+		c.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
 
 	c.JSON(http.StatusOK, models.APIResponse{
 		Success: true,
@@ -230,9 +238,31 @@ func (h *DomainHandler) ClearHistory(c *gin.Context) {
 	})
 }
 
-// GetValidExtensions returns list of valid domain extensions
+// GetValidExtensions returns the valid domain extensions and their metadata, optionally filtered and sorted
 func (h *DomainHandler) GetValidExtensions(c *gin.Context) {
-	extensions := h.domainService.GetValidExtensions()
+	var query models.ExtensionQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid query parameters",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	extensions, err := h.domainService.ListExtensions(query)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrInvalidExtensionQuery) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, models.APIResponse{
+			Success: false,
+			Message: "Failed to list extensions",
+			Error:   err.Error(),
+		})
+		return
+	}
 
 	c.JSON(http.StatusOK, models.APIResponse{
 		Success: true,
@@ -275,6 +305,22 @@ func (h *DomainHandler) GetWhoisInfo(c *gin.Context) {
 
 	// Get WHOIS information
 	whoisInfo, err := h.domainService.GetWhoisInfo(c.Request.Context(), domain)
+	if errors.Is(err, services.ErrRDAPNotFound) || errors.Is(err, services.ErrWhoisNotFound) {
+		c.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Message: "Domain is not registered",
+			Error:   err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, services.ErrWhoisUnrecognized) {
+		c.JSON(http.StatusBadGateway, models.APIResponse{
+			Success: false,
+			Message: "Registry answer could not be understood",
+			Error:   err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusBadRequest, models.APIResponse{
 			Success: false,
@@ -290,3 +336,134 @@ func (h *DomainHandler) GetWhoisInfo(c *gin.Context) {
 		Message: "WHOIS information retrieved successfully",
 	})
 }
+
+// GetResolverStatus returns the health of the upstream DNS resolver pool
+func (h *DomainHandler) GetResolverStatus(c *gin.Context) {
+	statuses := h.domainService.GetResolverStatus()
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    statuses,
+		Message: "Resolver status retrieved successfully",
+		Meta: &models.Meta{
+			Total:     len(statuses),
+			RequestID: c.GetHeader("X-Request-ID"),
+		},
+	})
+}
+
+// GetRateLimitStatus returns the token buckets and backoffs of the TLDs, servers and resolvers queried so far
+func (h *DomainHandler) GetRateLimitStatus(c *gin.Context) {
+	statuses := h.domainService.RateLimitStatus()
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    statuses,
+		Message: "Rate limit status retrieved successfully",
+		Meta: &models.Meta{
+			Total:     len(statuses),
+			RequestID: c.GetHeader("X-Request-ID"),
+		},
+	})
+}
+
+// GetCacheStats returns the size and hit rate of the check result cache
+func (h *DomainHandler) GetCacheStats(c *gin.Context) {
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    h.domainService.CacheStats(),
+		Message: "Cache statistics retrieved successfully",
+	})
+}
+
+// GetInFlightStats returns how many lookups concurrent identical checks shared
+func (h *DomainHandler) GetInFlightStats(c *gin.Context) {
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    h.domainService.InFlightStats(),
+		Message: "In-flight check statistics retrieved successfully",
+	})
+}
+
+// ClearCache discards every cached check result
+func (h *DomainHandler) ClearCache(c *gin.Context) {
+	h.domainService.ClearCache()
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Cache cleared successfully",
+	})
+}
+
+// InspectDNS returns the DNS records of a domain
+func (h *DomainHandler) InspectDNS(c *gin.Context) {
+	startTime := time.Now()
+
+	domain := c.Param("domain")
+	if domain == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Domain parameter is required",
+		})
+		return
+	}
+
+	// Record types can be given as ?types=A,MX or repeated ?types=A&types=MX
+	types := c.QueryArray("types")
+
+	inspection, err := h.domainService.InspectDNS(c.Request.Context(), domain, types)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "DNS inspection failed",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    inspection,
+		Message: "DNS records retrieved successfully",
+		Meta: &models.Meta{
+			ProcessTime: time.Since(startTime).Milliseconds(),
+			RequestID:   c.GetHeader("X-Request-ID"),
+		},
+	})
+}
+
+// InspectDNSMultiple returns the DNS records of several domains
+func (h *DomainHandler) InspectDNSMultiple(c *gin.Context) {
+	startTime := time.Now()
+
+	var request models.DNSInspectionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request format",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	inspections, err := h.domainService.InspectDNSMultiple(c.Request.Context(), request.Domains, request.Types)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "DNS inspection failed",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    inspections,
+		Message: "DNS records retrieved successfully",
+		Meta: &models.Meta{
+			Total:       len(inspections),
+			ProcessTime: time.Since(startTime).Milliseconds(),
+			RequestID:   c.GetHeader("X-Request-ID"),
+		},
+	})
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"domaincheck/internal/export"
+	"domaincheck/internal/models"
+	"domaincheck/internal/services"
+	"domaincheck/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// exportFlushInterval is how many rows are written between flushes to the client
+const exportFlushInterval = 100
+
+// ExportHistory streams the (filtered) domain check history as CSV, JSON Lines or XLSX
+func (h *DomainHandler) ExportHistory(c *gin.Context) {
+	format, err := export.ParseFormat(c.Query("format"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid export format",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	var query models.HistoryQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid query parameters",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	historyExport, err := h.domainService.NewHistoryExport(query)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrInvalidHistoryQuery) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, models.APIResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	streamExport(c, format, "domain-history", "History", historyExport.Each)
+}
+
+// ExportAllExtensions checks a domain name with all extensions and streams the results
+func (h *DomainHandler) ExportAllExtensions(c *gin.Context) {
+	format, err := export.ParseFormat(c.Query("format"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid export format",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	var request models.CheckAllExtensionsRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request format",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	// Check domain name with the selected extensions as a job and wait for it
+	result, err := h.jobManager.CheckAllExtensions(c.Request.Context(), request.DomainName, request.ExtensionSelection)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Domain extensions check failed",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	name := utils.SanitizeDomain(request.DomainName)
+	streamExport(c, format, name+"-extensions", name, func(fn func(*models.Domain) error) error {
+		for _, item := range result.AllResults {
+			if err := fn(item.Domain); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// streamExport writes the rows produced by each as a downloadable export file
+func streamExport(c *gin.Context, format export.Format, filename, sheet string, each func(fn func(*models.Domain) error) error) {
+	filename = fmt.Sprintf("%s-%s.%s", filename, time.Now().UTC().Format("20060102T150405Z"), format)
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+
+	writer, err := export.NewWriter(format, c.Writer, sheet)
+	if err != nil {
+		log.Printf("Failed to start %s export: %v", format, err)
+		return
+	}
+
+	rows := 0
+	err = each(func(domain *models.Domain) error {
+		if err := writer.Write(domain); err != nil {
+			return err
+		}
+		rows++
+		if rows%exportFlushInterval == 0 {
+			if err := writer.Flush(); err != nil {
+				return err
+			}
+			c.Writer.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		// Headers are already sent, so the client sees a truncated file
+		log.Printf("Failed to export %s: %v", filename, err)
+		return
+	}
+
+	if err := writer.Close(); err != nil {
+		log.Printf("Failed to finish %s: %v", filename, err)
+	}
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"domaincheck/internal/models"
+	"domaincheck/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JobHandler handles asynchronous bulk check job requests
+type JobHandler struct {
+	jobManager *services.JobManager
+}
+
+// NewJobHandler creates a new job handler
+func NewJobHandler(jobManager *services.JobManager) *JobHandler {
+	return &JobHandler{jobManager: jobManager}
+}
+
+// SubmitJob starts a bulk check job and returns its ID right away
+func (h *JobHandler) SubmitJob(c *gin.Context) {
+	var request models.JobRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request format",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if (request.DomainName == "") == (len(request.Domains) == 0) {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request format",
+			Error:   "exactly one of domain_name and domains is required",
+		})
+		return
+	}
+
+	if request.DomainName == "" && (request.Preset != "" || len(request.Extensions) > 0 || len(request.Include) > 0 || len(request.Exclude) > 0) {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request format",
+			Error:   "preset, extensions, include and exclude require domain_name",
+		})
+		return
+	}
+
+	var job *models.Job
+	var err error
+	if request.DomainName != "" {
+		job, err = h.jobManager.SubmitCheckAllExtensions(request.DomainName, request.ExtensionSelection)
+	} else {
+		job, err = h.jobManager.SubmitDomains(request.Domains)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Failed to submit job",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.Header("Location", "/api/v1/jobs/"+job.ID)
+	c.JSON(http.StatusAccepted, models.APIResponse{
+		Success: true,
+		Data:    job,
+		Message: "Job submitted successfully",
+	})
+}
+
+// ListJobs returns all retained jobs without their results
+func (h *JobHandler) ListJobs(c *gin.Context) {
+	jobs := h.jobManager.List()
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    jobs,
+		Message: "Jobs retrieved successfully",
+		Meta: &models.Meta{
+			Total:     len(jobs),
+			RequestID: c.GetHeader("X-Request-ID"),
+		},
+	})
+}
+
+// GetJob returns the progress and results of a job. The offset query
+// parameter returns only the results recorded after the first offset ones,
+// and results=false omits them entirely.
+func (h *JobHandler) GetJob(c *gin.Context) {
+	id := c.Param("id")
+
+	withResults, err := strconv.ParseBool(c.DefaultQuery("results", "true"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid query parameters",
+			Error:   "results must be true or false",
+		})
+		return
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid query parameters",
+			Error:   "offset must be a non-negative integer",
+		})
+		return
+	}
+
+	var job *models.Job
+	if withResults {
+		var results []models.JobResult
+		job, results, err = h.jobManager.ResultsSince(id, offset)
+		if job != nil {
+			job.Results = results
+		}
+	} else {
+		job, err = h.jobManager.Get(id, false)
+	}
+	if err != nil {
+		respondJobError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    job,
+		Message: "Job retrieved successfully",
+	})
+}
+
+// CancelJob stops a running job
+func (h *JobHandler) CancelJob(c *gin.Context) {
+	job, err := h.jobManager.Cancel(c.Param("id"))
+	if err != nil {
+		respondJobError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    job,
+		Message: "Job cancellation requested",
+	})
+}
+
+// respondJobError maps job manager errors to HTTP responses
+func respondJobError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrJobNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrJobFinished):
+		status = http.StatusConflict
+	}
+
+	c.JSON(status, models.APIResponse{
+		Success: false,
+		Error:   err.Error(),
+	})
+}
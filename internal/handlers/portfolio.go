@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"domaincheck/internal/models"
+	"domaincheck/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultExpiringWithin is the period listed as expiring unless asked otherwise
+const defaultExpiringWithin = "30d"
+
+// PortfolioHandler handles owned domain requests
+type PortfolioHandler struct {
+	portfolio *services.PortfolioManager
+}
+
+// NewPortfolioHandler creates a new portfolio handler
+func NewPortfolioHandler(portfolio *services.PortfolioManager) *PortfolioHandler {
+	return &PortfolioHandler{portfolio: portfolio}
+}
+
+// AddDomain marks a domain as owned
+func (h *PortfolioHandler) AddDomain(c *gin.Context) {
+	var request models.PortfolioRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request format",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	domain, err := h.portfolio.Add(request)
+	if err != nil {
+		respondPortfolioError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success: true,
+		Data:    domain,
+		Message: "Domain added to portfolio",
+	})
+}
+
+// ListDomains returns all owned domains
+func (h *PortfolioHandler) ListDomains(c *gin.Context) {
+	domains, err := h.portfolio.List()
+	if err != nil {
+		respondPortfolioError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    domains,
+		Message: "Portfolio retrieved successfully",
+		Meta: &models.Meta{
+			Total:     len(domains),
+			RequestID: c.GetHeader("X-Request-ID"),
+		},
+	})
+}
+
+// GetExpiring returns the owned domains expiring within a period, soonest first
+func (h *PortfolioHandler) GetExpiring(c *gin.Context) {
+	within, err := parseWithin(c.DefaultQuery("within", defaultExpiringWithin))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid query parameters",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	domains, err := h.portfolio.Expiring(within)
+	if err != nil {
+		respondPortfolioError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    domains,
+		Message: "Expiring domains retrieved successfully",
+		Meta: &models.Meta{
+			Total:     len(domains),
+			RequestID: c.GetHeader("X-Request-ID"),
+		},
+	})
+}
+
+// GetDomain returns one owned domain
+func (h *PortfolioHandler) GetDomain(c *gin.Context) {
+	id, ok := portfolioDomainID(c)
+	if !ok {
+		return
+	}
+
+	domain, err := h.portfolio.Get(id)
+	if err != nil {
+		respondPortfolioError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    domain,
+		Message: "Portfolio domain retrieved successfully",
+	})
+}
+
+// UpdateDomain changes the note of an owned domain
+func (h *PortfolioHandler) UpdateDomain(c *gin.Context) {
+	id, ok := portfolioDomainID(c)
+	if !ok {
+		return
+	}
+
+	var request models.PortfolioUpdateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request format",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	domain, err := h.portfolio.Update(id, request)
+	if err != nil {
+		respondPortfolioError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    domain,
+		Message: "Portfolio domain updated successfully",
+	})
+}
+
+// DeleteDomain stops tracking an owned domain
+func (h *PortfolioHandler) DeleteDomain(c *gin.Context) {
+	id, ok := portfolioDomainID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.portfolio.Remove(id); err != nil {
+		respondPortfolioError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Domain removed from portfolio",
+	})
+}
+
+// RefreshDomain looks up the expiration date of an owned domain immediately
+func (h *PortfolioHandler) RefreshDomain(c *gin.Context) {
+	id, ok := portfolioDomainID(c)
+	if !ok {
+		return
+	}
+
+	domain, err := h.portfolio.RefreshNow(c.Request.Context(), id)
+	if err != nil {
+		respondPortfolioError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    domain,
+		Message: "Portfolio domain refreshed successfully",
+	})
+}
+
+// parseWithin parses a period given in days ("30d") or as a Go duration ("720h")
+func parseWithin(value string) (time.Duration, error) {
+	var within time.Duration
+	if strings.HasSuffix(value, "d") {
+		n, err := strconv.Atoi(strings.TrimSuffix(value, "d"))
+		if err != nil {
+			return 0, fmt.Errorf("within must be a number of days such as 30d or a duration such as 720h")
+		}
+		within = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if within, err = time.ParseDuration(value); err != nil {
+			return 0, fmt.Errorf("within must be a number of days such as 30d or a duration such as 720h")
+		}
+	}
+
+	if within <= 0 {
+		return 0, fmt.Errorf("within must be positive")
+	}
+	return within, nil
+}
+
+// portfolioDomainID parses the domain ID path parameter, responding with an error if it is invalid
+func portfolioDomainID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid portfolio domain ID",
+			Error:   "id must be a positive integer",
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// respondPortfolioError maps portfolio errors to HTTP responses
+func respondPortfolioError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrPortfolioDomainNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrPortfolioDomainExists):
+		status = http.StatusConflict
+	case errors.Is(err, services.ErrInvalidPortfolioDomain):
+		status = http.StatusBadRequest
+	}
+
+	c.JSON(status, models.APIResponse{
+		Success: false,
+		Error:   err.Error(),
+	})
+}
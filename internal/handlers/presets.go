@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"domaincheck/internal/models"
+	"domaincheck/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListPresets returns the built-in and custom extension presets
+func (h *DomainHandler) ListPresets(c *gin.Context) {
+	presets, err := h.domainService.ListPresets()
+	if err != nil {
+		respondPresetError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    presets,
+		Message: "Extension presets retrieved successfully",
+		Meta: &models.Meta{
+			Total:     len(presets),
+			RequestID: c.GetHeader("X-Request-ID"),
+		},
+	})
+}
+
+// GetPreset returns one extension preset with the extensions it selects
+func (h *DomainHandler) GetPreset(c *gin.Context) {
+	preset, err := h.domainService.GetPreset(c.Param("name"))
+	if err != nil {
+		respondPresetError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    preset,
+		Message: "Extension preset retrieved successfully",
+	})
+}
+
+// CreatePreset adds a custom extension preset
+func (h *DomainHandler) CreatePreset(c *gin.Context) {
+	var request models.ExtensionPresetRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request format",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	preset, err := h.domainService.CreatePreset(request)
+	if err != nil {
+		respondPresetError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success: true,
+		Data:    preset,
+		Message: "Extension preset created successfully",
+	})
+}
+
+// UpdatePreset changes a custom extension preset
+func (h *DomainHandler) UpdatePreset(c *gin.Context) {
+	var request models.ExtensionPresetUpdateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request format",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	preset, err := h.domainService.UpdatePreset(c.Param("name"), request)
+	if err != nil {
+		respondPresetError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    preset,
+		Message: "Extension preset updated successfully",
+	})
+}
+
+// DeletePreset removes a custom extension preset
+func (h *DomainHandler) DeletePreset(c *gin.Context) {
+	if err := h.domainService.DeletePreset(c.Param("name")); err != nil {
+		respondPresetError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Extension preset deleted successfully",
+	})
+}
+
+// respondPresetError maps extension preset errors to HTTP responses
+func respondPresetError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrPresetNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrPresetExists), errors.Is(err, services.ErrPresetReadOnly):
+		status = http.StatusConflict
+	case errors.Is(err, services.ErrInvalidPreset):
+		status = http.StatusBadRequest
+	}
+
+	c.JSON(status, models.APIResponse{
+		Success: false,
+		Error:   err.Error(),
+	})
+}
@@ -7,7 +7,7 @@ import (
 )
 
 // SetupRoutes configures all API routes
-func SetupRoutes(router *gin.Engine, cfg *config.Config, domainHandler *DomainHandler, wsHandler *WebSocketHandler) {
+func SetupRoutes(router *gin.Engine, cfg *config.Config, domainHandler *DomainHandler, wsHandler *WebSocketHandler, jobHandler *JobHandler, watchlistHandler *WatchlistHandler, webhookHandler *WebhookHandler, portfolioHandler *PortfolioHandler) {
 	// Health check route
 	router.GET("/api/v1/health", domainHandler.HealthCheck)
 	router.GET("/api/health", domainHandler.HealthCheck) // Backward compatibility
@@ -16,23 +16,56 @@ func SetupRoutes(router *gin.Engine, cfg *config.Config, domainHandler *DomainHa
 	router.GET("/ws", wsHandler.HandleWebSocket)
 
 	// Domain routes
-	setupDomainRoutes(router, domainHandler)
+	setupDomainRoutes(router, cfg, domainHandler)
 
 	// Extension routes
 	setupExtensionRoutes(router, domainHandler)
+
+	// Resolver routes
+	router.GET("/api/v1/resolvers", domainHandler.GetResolverStatus)
+
+	// Rate limit routes
+	router.GET("/api/v1/rate-limits", domainHandler.GetRateLimitStatus)
+
+	// Result cache routes
+	router.GET("/api/v1/cache", domainHandler.GetCacheStats)
+	router.DELETE("/api/v1/cache", domainHandler.ClearCache)
+	router.GET("/api/v1/cache/inflight", domainHandler.GetInFlightStats)
+
+	// Job routes
+	setupJobRoutes(router, jobHandler)
+
+	// Watchlist routes
+	setupWatchlistRoutes(router, watchlistHandler)
+
+	// Webhook routes
+	setupWebhookRoutes(router, webhookHandler)
+
+	// Portfolio routes
+	setupPortfolioRoutes(router, portfolioHandler)
 }
 
 // setupDomainRoutes configures domain-related routes
-func setupDomainRoutes(router *gin.Engine, domainHandler *DomainHandler) {
+func setupDomainRoutes(router *gin.Engine, cfg *config.Config, domainHandler *DomainHandler) {
+	// Streaming routes outlive the server-wide timeouts
+	stream := streamDeadlines(cfg.Server.StreamTimeout)
+
 	// v1 API routes
 	domainsV1 := router.Group("/api/v1/domains")
 	{
 		domainsV1.POST("/check", domainHandler.CheckDomain)
 		domainsV1.POST("/check-all-extensions", domainHandler.CheckAllExtensions)
+		domainsV1.POST("/check-all-extensions/export", domainHandler.ExportAllExtensions)
+		domainsV1.POST("/check-all-extensions/stream", stream, domainHandler.StreamAllExtensions)
 		domainsV1.POST("/check-multiple", domainHandler.CheckMultipleDomains)
+		domainsV1.POST("/check-multiple/stream", stream, domainHandler.StreamMultipleDomains)
+		domainsV1.POST("/check-upload", stream, domainHandler.CheckUpload)
 		domainsV1.GET("/history", domainHandler.GetDomainHistory)
+		domainsV1.GET("/history/export", domainHandler.ExportHistory)
 		domainsV1.DELETE("/history", domainHandler.ClearHistory)
 		domainsV1.GET("/whois/:domain", domainHandler.GetWhoisInfo)
+		domainsV1.GET("/dns/:domain", domainHandler.InspectDNS)
+		domainsV1.POST("/dns", domainHandler.InspectDNSMultiple)
 	}
 
 	// Backward compatibility routes (v0)
@@ -50,5 +83,65 @@ func setupExtensionRoutes(router *gin.Engine, domainHandler *DomainHandler) {
 	{
 		extensions.GET("/", domainHandler.GetValidExtensions)
 		extensions.POST("/reload", domainHandler.ReloadExtensions)
+		extensions.GET("/presets", domainHandler.ListPresets)
+		extensions.POST("/presets", domainHandler.CreatePreset)
+		extensions.GET("/presets/:name", domainHandler.GetPreset)
+		extensions.PATCH("/presets/:name", domainHandler.UpdatePreset)
+		extensions.DELETE("/presets/:name", domainHandler.DeletePreset)
+	}
+}
+
+// setupJobRoutes configures asynchronous bulk check job routes
+func setupJobRoutes(router *gin.Engine, jobHandler *JobHandler) {
+	jobs := router.Group("/api/v1/jobs")
+	{
+		jobs.POST("", jobHandler.SubmitJob)
+		jobs.GET("", jobHandler.ListJobs)
+		jobs.GET("/:id", jobHandler.GetJob)
+		jobs.DELETE("/:id", jobHandler.CancelJob)
+	}
+}
+
+// setupWatchlistRoutes configures watched domain monitoring routes
+func setupWatchlistRoutes(router *gin.Engine, watchlistHandler *WatchlistHandler) {
+	watchlist := router.Group("/api/v1/watchlist")
+	{
+		watchlist.POST("", watchlistHandler.AddEntry)
+		watchlist.GET("", watchlistHandler.ListEntries)
+		watchlist.GET("/:id", watchlistHandler.GetEntry)
+		watchlist.PATCH("/:id", watchlistHandler.UpdateEntry)
+		watchlist.DELETE("/:id", watchlistHandler.DeleteEntry)
+		watchlist.POST("/:id/check", watchlistHandler.CheckEntry)
+		watchlist.GET("/:id/transitions", watchlistHandler.GetTransitions)
+	}
+}
+
+// setupWebhookRoutes configures webhook registration and delivery log routes
+func setupWebhookRoutes(router *gin.Engine, webhookHandler *WebhookHandler) {
+	webhooks := router.Group("/api/v1/webhooks")
+	{
+		webhooks.POST("", webhookHandler.CreateWebhook)
+		webhooks.GET("", webhookHandler.ListWebhooks)
+		webhooks.GET("/:id", webhookHandler.GetWebhook)
+		webhooks.PATCH("/:id", webhookHandler.UpdateWebhook)
+		webhooks.DELETE("/:id", webhookHandler.DeleteWebhook)
+		webhooks.POST("/:id/ping", webhookHandler.PingWebhook)
+		webhooks.GET("/:id/deliveries", webhookHandler.ListDeliveries)
+		webhooks.GET("/:id/deliveries/:deliveryID", webhookHandler.GetDelivery)
+		webhooks.POST("/:id/deliveries/:deliveryID/redeliver", webhookHandler.RedeliverDelivery)
+	}
+}
+
+// setupPortfolioRoutes configures owned domain expiration tracking routes
+func setupPortfolioRoutes(router *gin.Engine, portfolioHandler *PortfolioHandler) {
+	portfolio := router.Group("/api/v1/portfolio")
+	{
+		portfolio.POST("", portfolioHandler.AddDomain)
+		portfolio.GET("", portfolioHandler.ListDomains)
+		portfolio.GET("/expiring", portfolioHandler.GetExpiring)
+		portfolio.GET("/:id", portfolioHandler.GetDomain)
+		portfolio.PATCH("/:id", portfolioHandler.UpdateDomain)
+		portfolio.DELETE("/:id", portfolioHandler.DeleteDomain)
+		portfolio.POST("/:id/refresh", portfolioHandler.RefreshDomain)
 	}
 }
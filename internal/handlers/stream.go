@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"domaincheck/internal/models"
+	"domaincheck/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Bulk check stream formats
+const (
+	streamFormatNDJSON = "ndjson"
+	streamFormatSSE    = "sse"
+)
+
+// sseKeepAliveInterval is how often an SSE stream gets a comment, so
+// proxies and clients do not drop it as idle between slow results
+const sseKeepAliveInterval = 15 * time.Second
+
+// Bulk check stream event types
+const (
+	streamEventResult   = "bulk_check_result"   // Data is a models.DomainCheckResponse
+	streamEventError    = "bulk_check_error"    // Data is a models.JobResult with the error
+	streamEventComplete = "bulk_check_complete" // Data is a models.WebSocketBulkProgress
+)
+
+// StreamAllExtensions checks a domain name with all extensions and streams each result as it completes
+func (h *DomainHandler) StreamAllExtensions(c *gin.Context) {
+	format, ok := streamFormat(c)
+	if !ok {
+		return
+	}
+
+	var request models.CheckAllExtensionsRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request format",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	domainName := utils.SanitizeDomain(request.DomainName)
+	if domainName == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request format",
+			Error:   "domain name is required",
+		})
+		return
+	}
+
+	domains, err := h.domainService.ExtensionDomains(domainName, request.ExtensionSelection)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request format",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	h.streamBulkCheck(c, format, domainName, domains)
+}
+
+// StreamMultipleDomains checks multiple domains and streams each result as it completes
+func (h *DomainHandler) StreamMultipleDomains(c *gin.Context) {
+	format, ok := streamFormat(c)
+	if !ok {
+		return
+	}
+
+	var request struct {
+		Domains []string `json:"domains" binding:"required,min=1,max=50"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request format",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	h.streamBulkCheck(c, format, "", request.Domains)
+}
+
+// streamFormat picks Server-Sent Events or NDJSON from the format query
+// parameter or the Accept header, responding with an error if it is unknown
+func streamFormat(c *gin.Context) (string, bool) {
+	switch format := strings.ToLower(c.Query("format")); format {
+	case streamFormatNDJSON, streamFormatSSE:
+		return format, true
+	case "":
+		if strings.Contains(c.GetHeader("Accept"), "text/event-stream") {
+			return streamFormatSSE, true
+		}
+		return streamFormatNDJSON, true
+	default:
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid stream format",
+			Error:   "format must be ndjson or sse",
+		})
+		return "", false
+	}
+}
+
+// streamBulkCheck checks domains and writes an event for every result,
+// followed by a summary. Checking stops when the client disconnects.
+func (h *DomainHandler) streamBulkCheck(c *gin.Context, format, domainName string, domains []string) {
+	startTime := time.Now()
+
+	if format == streamFormatSSE {
+		c.Header("Content-Type", "text/event-stream")
+	} else {
+		c.Header("Content-Type", "application/x-ndjson")
+	}
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	// Results and keep-alive comments are written from different goroutines
+	var mutex sync.Mutex
+	encoder := json.NewEncoder(c.Writer)
+	send := func(event string, data interface{}) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if format == streamFormatSSE {
+			c.SSEvent(event, data)
+		} else {
+			encoder.Encode(models.WebSocketMessage{Type: event, Data: data})
+		}
+		c.Writer.Flush()
+	}
+
+	if format == streamFormatSSE {
+		stop := make(chan struct{})
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ticker := time.NewTicker(sseKeepAliveInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-stop:
+					return
+				case <-ticker.C:
+					mutex.Lock()
+					c.Writer.WriteString(": keep-alive\n\n")
+					c.Writer.Flush()
+					mutex.Unlock()
+				}
+			}
+		}()
+		// Nothing may be written once the handler has returned
+		defer func() {
+			close(stop)
+			wg.Wait()
+		}()
+	}
+
+	progress := &models.WebSocketBulkProgress{
+		DomainName:         domainName,
+		TotalExtensions:    len(domains),
+		AvailableDomains:   []models.WebSocketDomainCheck{},
+		UnavailableDomains: []models.WebSocketDomainCheck{},
+	}
+
+	ctx := c.Request.Context()
+	h.domainService.CheckDomainsStream(ctx, domains, func(domain string, result *models.DomainCheckResponse, err error) {
+		if err != nil {
+			jobResult := models.JobResult{Domain: domain, Error: err.Error()}
+			addBulkResult(progress, jobResult)
+			send(streamEventError, jobResult)
+			return
+		}
+
+		addBulkResult(progress, models.JobResult{Domain: domain, Result: result.Domain})
+		send(streamEventResult, result)
+	})
+
+	if ctx.Err() != nil {
+		// The client is gone, so there is nobody to send the summary to
+		return
+	}
+
+	progress.IsComplete = true
+	progress.CurrentDomain = nil
+	progress.TotalTime = time.Since(startTime).Milliseconds()
+	send(streamEventComplete, progress)
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"domaincheck/internal/models"
+	"domaincheck/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// maxUploadBytes limits the size of an uploaded domain list
+	maxUploadBytes = 32 << 20
+	// uploadFlushInterval is how many lines are written between flushes to the client
+	uploadFlushInterval = 50
+)
+
+// CheckUpload checks a plain-text or CSV list of domains, sent as the "file"
+// field of a multipart form or as the raw request body, and streams one
+// JSON object per line: invalid input lines first, then each result as it
+// completes, and finally a summary.
+func (h *DomainHandler) CheckUpload(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxUploadBytes)
+
+	body := io.Reader(c.Request.Body)
+	isCSV := strings.EqualFold(c.Query("format"), "csv") ||
+		strings.HasPrefix(c.ContentType(), "text/csv")
+
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		file, header, err := c.Request.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Message: "Invalid request format",
+				Error:   "a domain list file is required in the \"file\" field",
+			})
+			return
+		}
+		defer file.Close()
+
+		body = file
+		if strings.EqualFold(filepath.Ext(header.Filename), ".csv") ||
+			strings.HasPrefix(header.Header.Get("Content-Type"), "text/csv") {
+			isCSV = true
+		}
+	}
+
+	list, err := h.domainService.ParseDomainList(body, isCSV)
+	if err != nil {
+		status := http.StatusBadRequest
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) || errors.Is(err, services.ErrTooManyDomains) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		c.JSON(status, models.APIResponse{
+			Success: false,
+			Message: "Invalid domain list",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	startTime := time.Now()
+	summary := models.BulkUploadSummary{
+		Type:           models.BulkLineSummary,
+		TotalLines:     list.TotalLines,
+		ValidCount:     len(list.Domains),
+		InvalidCount:   len(list.Invalid),
+		DuplicateCount: list.DuplicateCount,
+	}
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	encoder := json.NewEncoder(c.Writer)
+	lines := 0
+	write := func(v interface{}) {
+		if err := encoder.Encode(v); err != nil {
+			return
+		}
+		lines++
+		if lines%uploadFlushInterval == 0 {
+			c.Writer.Flush()
+		}
+	}
+
+	for _, item := range list.Invalid {
+		write(item)
+	}
+	c.Writer.Flush()
+
+	ctx := c.Request.Context()
+	h.domainService.CheckDomainsStream(ctx, list.Domains, func(domain string, result *models.DomainCheckResponse, err error) {
+		item := models.BulkCheckItem{
+			Type:   models.BulkLineResult,
+			Line:   list.Lines[domain],
+			Domain: domain,
+		}
+
+		summary.CheckedCount++
+		switch {
+		case err != nil:
+			item.Error = err.Error()
+			summary.ErrorCount++
+		case result.Domain.Status == models.LegacyStatusAvailable:
+			item.Result = result.Domain
+			summary.AvailableCount++
+		case result.Domain.Availability == models.StatusRateLimited:
+			item.Result = result.Domain
+			summary.RateLimitedCount++
+		default:
+			item.Result = result.Domain
+			summary.UnavailableCount++
+		}
+
+		write(item)
+	})
+
+	if ctx.Err() != nil {
+		log.Printf("Upload check stopped after %d of %d domains: %v", summary.CheckedCount, summary.ValidCount, ctx.Err())
+	}
+
+	summary.IsComplete = summary.CheckedCount == summary.ValidCount
+	summary.TotalTime = time.Since(startTime).Milliseconds()
+	write(summary)
+	c.Writer.Flush()
+}
@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"domaincheck/internal/models"
+	"domaincheck/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WatchlistHandler handles watched domain requests
+type WatchlistHandler struct {
+	watchlist *services.WatchlistManager
+}
+
+// NewWatchlistHandler creates a new watchlist handler
+func NewWatchlistHandler(watchlist *services.WatchlistManager) *WatchlistHandler {
+	return &WatchlistHandler{watchlist: watchlist}
+}
+
+// AddEntry starts watching a domain
+func (h *WatchlistHandler) AddEntry(c *gin.Context) {
+	var request models.WatchRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request format",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	entry, err := h.watchlist.Add(request)
+	if err != nil {
+		respondWatchlistError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success: true,
+		Data:    entry,
+		Message: "Domain added to watchlist",
+	})
+}
+
+// ListEntries returns all watched domains
+func (h *WatchlistHandler) ListEntries(c *gin.Context) {
+	entries, err := h.watchlist.List()
+	if err != nil {
+		respondWatchlistError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    entries,
+		Message: "Watchlist retrieved successfully",
+		Meta: &models.Meta{
+			Total:     len(entries),
+			RequestID: c.GetHeader("X-Request-ID"),
+		},
+	})
+}
+
+// GetEntry returns one watched domain
+func (h *WatchlistHandler) GetEntry(c *gin.Context) {
+	id, ok := watchEntryID(c)
+	if !ok {
+		return
+	}
+
+	entry, err := h.watchlist.Get(id)
+	if err != nil {
+		respondWatchlistError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    entry,
+		Message: "Watch entry retrieved successfully",
+	})
+}
+
+// UpdateEntry changes the note or check interval of a watched domain
+func (h *WatchlistHandler) UpdateEntry(c *gin.Context) {
+	id, ok := watchEntryID(c)
+	if !ok {
+		return
+	}
+
+	var request models.WatchUpdateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request format",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	entry, err := h.watchlist.Update(id, request)
+	if err != nil {
+		respondWatchlistError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    entry,
+		Message: "Watch entry updated successfully",
+	})
+}
+
+// DeleteEntry stops watching a domain
+func (h *WatchlistHandler) DeleteEntry(c *gin.Context) {
+	id, ok := watchEntryID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.watchlist.Remove(id); err != nil {
+		respondWatchlistError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Domain removed from watchlist",
+	})
+}
+
+// CheckEntry re-checks a watched domain immediately
+func (h *WatchlistHandler) CheckEntry(c *gin.Context) {
+	id, ok := watchEntryID(c)
+	if !ok {
+		return
+	}
+
+	entry, err := h.watchlist.CheckNow(c.Request.Context(), id)
+	if err != nil {
+		respondWatchlistError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    entry,
+		Message: "Watched domain checked successfully",
+	})
+}
+
+// GetTransitions returns the status transitions of a watched domain, newest first
+func (h *WatchlistHandler) GetTransitions(c *gin.Context) {
+	id, ok := watchEntryID(c)
+	if !ok {
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil || limit < 0 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid query parameters",
+			Error:   "limit must be a non-negative integer",
+		})
+		return
+	}
+
+	transitions, err := h.watchlist.Transitions(id, limit)
+	if err != nil {
+		respondWatchlistError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    transitions,
+		Message: "Transitions retrieved successfully",
+		Meta: &models.Meta{
+			Total:     len(transitions),
+			RequestID: c.GetHeader("X-Request-ID"),
+		},
+	})
+}
+
+// watchEntryID parses the entry ID path parameter, responding with an error if it is invalid
+func watchEntryID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid watch entry ID",
+			Error:   "id must be a positive integer",
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// respondWatchlistError maps watchlist errors to HTTP responses
+func respondWatchlistError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrWatchEntryNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrWatchEntryExists):
+		status = http.StatusConflict
+	case errors.Is(err, services.ErrInvalidWatchEntry):
+		status = http.StatusBadRequest
+	}
+
+	c.JSON(status, models.APIResponse{
+		Success: false,
+		Error:   err.Error(),
+	})
+}
@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"domaincheck/internal/models"
+	"domaincheck/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookHandler handles webhook registration and delivery log requests
+type WebhookHandler struct {
+	webhooks *services.WebhookManager
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(webhooks *services.WebhookManager) *WebhookHandler {
+	return &WebhookHandler{webhooks: webhooks}
+}
+
+// CreateWebhook registers a webhook; its secret is only returned here
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var request models.WebhookRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request format",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	webhook, err := h.webhooks.Create(request)
+	if err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success: true,
+		Data:    webhook,
+		Message: "Webhook created successfully",
+	})
+}
+
+// ListWebhooks returns all webhooks
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	webhooks, err := h.webhooks.List()
+	if err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    webhooks,
+		Message: "Webhooks retrieved successfully",
+		Meta: &models.Meta{
+			Total:     len(webhooks),
+			RequestID: c.GetHeader("X-Request-ID"),
+		},
+	})
+}
+
+// GetWebhook returns one webhook
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	id, ok := webhookID(c, "id")
+	if !ok {
+		return
+	}
+
+	webhook, err := h.webhooks.Get(id)
+	if err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    webhook,
+		Message: "Webhook retrieved successfully",
+	})
+}
+
+// UpdateWebhook changes the URL, events, secret or state of a webhook
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	id, ok := webhookID(c, "id")
+	if !ok {
+		return
+	}
+
+	var request models.WebhookUpdateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid request format",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	webhook, err := h.webhooks.Update(id, request)
+	if err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    webhook,
+		Message: "Webhook updated successfully",
+	})
+}
+
+// DeleteWebhook removes a webhook and its delivery log
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	id, ok := webhookID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.webhooks.Delete(id); err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Webhook deleted successfully",
+	})
+}
+
+// PingWebhook queues a test delivery
+func (h *WebhookHandler) PingWebhook(c *gin.Context) {
+	id, ok := webhookID(c, "id")
+	if !ok {
+		return
+	}
+
+	delivery, err := h.webhooks.Ping(id)
+	if err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, models.APIResponse{
+		Success: true,
+		Data:    delivery,
+		Message: "Ping queued",
+	})
+}
+
+// ListDeliveries returns the delivery log of a webhook, newest first
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	id, ok := webhookID(c, "id")
+	if !ok {
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil || limit < 0 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid query parameters",
+			Error:   "limit must be a non-negative integer",
+		})
+		return
+	}
+
+	deliveries, err := h.webhooks.Deliveries(id, limit)
+	if err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    deliveries,
+		Message: "Deliveries retrieved successfully",
+		Meta: &models.Meta{
+			Total:     len(deliveries),
+			RequestID: c.GetHeader("X-Request-ID"),
+		},
+	})
+}
+
+// GetDelivery returns one delivery of a webhook
+func (h *WebhookHandler) GetDelivery(c *gin.Context) {
+	id, ok := webhookID(c, "id")
+	if !ok {
+		return
+	}
+	deliveryID, ok := webhookID(c, "deliveryID")
+	if !ok {
+		return
+	}
+
+	delivery, err := h.webhooks.Delivery(id, deliveryID)
+	if err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    delivery,
+		Message: "Delivery retrieved successfully",
+	})
+}
+
+// RedeliverDelivery queues a delivery again with its original payload
+func (h *WebhookHandler) RedeliverDelivery(c *gin.Context) {
+	id, ok := webhookID(c, "id")
+	if !ok {
+		return
+	}
+	deliveryID, ok := webhookID(c, "deliveryID")
+	if !ok {
+		return
+	}
+
+	delivery, err := h.webhooks.Redeliver(id, deliveryID)
+	if err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, models.APIResponse{
+		Success: true,
+		Data:    delivery,
+		Message: "Redelivery queued",
+	})
+}
+
+// webhookID parses a webhook or delivery ID path parameter, responding with an error if it is invalid
+func webhookID(c *gin.Context, param string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(param))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid " + param,
+			Error:   param + " must be a positive integer",
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// respondWebhookError maps webhook errors to HTTP responses
+func respondWebhookError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrWebhookNotFound), errors.Is(err, services.ErrDeliveryNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrInvalidWebhook):
+		status = http.StatusBadRequest
+	}
+
+	c.JSON(status, models.APIResponse{
+		Success: false,
+		Error:   err.Error(),
+	})
+}
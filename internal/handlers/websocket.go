@@ -22,15 +22,33 @@ var upgrader = websocket.Upgrader{
 // WebSocketHandler handles WebSocket connections
 type WebSocketHandler struct {
 	domainService *services.DomainService
-	clients       map[*websocket.Conn]bool
+	jobManager    *services.JobManager
+	clients       map[*websocket.Conn]*wsClient
 	mutex         sync.RWMutex
 }
 
+// wsClient serializes writes to a connection and tracks the jobs it started,
+// which are cancelled when the connection closes
+type wsClient struct {
+	conn       *websocket.Conn
+	writeMutex sync.Mutex
+	jobs       map[string]bool
+	jobsMutex  sync.Mutex
+}
+
+// WriteJSON sends a message; gorilla/websocket allows only one concurrent writer
+func (c *wsClient) WriteJSON(v interface{}) error {
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+	return c.conn.WriteJSON(v)
+}
+
 // NewWebSocketHandler creates a new WebSocket handler
-func NewWebSocketHandler(domainService *services.DomainService) *WebSocketHandler {
+func NewWebSocketHandler(domainService *services.DomainService, jobManager *services.JobManager) *WebSocketHandler {
 	return &WebSocketHandler{
 		domainService: domainService,
-		clients:       make(map[*websocket.Conn]bool),
+		jobManager:    jobManager,
+		clients:       make(map[*websocket.Conn]*wsClient),
 	}
 }
 
@@ -43,16 +61,24 @@ func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 	}
 	defer conn.Close()
 
+	client := &wsClient{conn: conn, jobs: make(map[string]bool)}
+
 	// Register client
 	h.mutex.Lock()
-	h.clients[conn] = true
+	h.clients[conn] = client
 	h.mutex.Unlock()
 
-	// Remove client when connection closes
+	// Remove client and stop its jobs when connection closes
 	defer func() {
 		h.mutex.Lock()
 		delete(h.clients, conn)
 		h.mutex.Unlock()
+
+		client.jobsMutex.Lock()
+		for id := range client.jobs {
+			h.jobManager.Cancel(id)
+		}
+		client.jobsMutex.Unlock()
 	}()
 
 	// Send welcome message
@@ -60,7 +86,7 @@ func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 		Type:    "connected",
 		Message: "WebSocket connection established",
 	}
-	conn.WriteJSON(welcomeMsg)
+	client.WriteJSON(welcomeMsg)
 
 	// Handle incoming messages
 	for {
@@ -73,14 +99,16 @@ func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 
 		switch msg.Type {
 		case "check_all_extensions":
-			h.handleCheckAllExtensions(conn, msg)
+			h.handleCheckAllExtensions(client, msg)
+		case "cancel_job":
+			h.handleCancelJob(client, msg)
 		case "ping":
-			conn.WriteJSON(models.WebSocketMessage{
+			client.WriteJSON(models.WebSocketMessage{
 				Type: "pong",
 				Data: time.Now().Unix(),
 			})
 		default:
-			conn.WriteJSON(models.WebSocketMessage{
+			client.WriteJSON(models.WebSocketMessage{
 				Type:    "error",
 				Message: "Unknown message type",
 			})
@@ -88,12 +116,12 @@ func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 	}
 }
 
-// handleCheckAllExtensions handles bulk domain extension checks via WebSocket
-func (h *WebSocketHandler) handleCheckAllExtensions(conn *websocket.Conn, msg models.WebSocketMessage) {
+// handleCheckAllExtensions starts a bulk extension check job and streams its progress
+func (h *WebSocketHandler) handleCheckAllExtensions(client *wsClient, msg models.WebSocketMessage) {
 	// Extract domain name from message
 	data, ok := msg.Data.(map[string]interface{})
 	if !ok {
-		conn.WriteJSON(models.WebSocketMessage{
+		client.WriteJSON(models.WebSocketMessage{
 			Type:    "error",
 			Message: "Invalid message format",
 		})
@@ -102,126 +130,191 @@ func (h *WebSocketHandler) handleCheckAllExtensions(conn *websocket.Conn, msg mo
 
 	domainName, ok := data["domain_name"].(string)
 	if !ok || domainName == "" {
-		conn.WriteJSON(models.WebSocketMessage{
+		client.WriteJSON(models.WebSocketMessage{
 			Type:    "error",
 			Message: "Domain name is required",
 		})
 		return
 	}
 
+	preset, _ := data["preset"].(string)
+	selection := models.ExtensionSelection{
+		Preset:     preset,
+		Extensions: stringList(data["extensions"]),
+		Include:    stringList(data["include"]),
+		Exclude:    stringList(data["exclude"]),
+	}
+
+	job, err := h.jobManager.SubmitCheckAllExtensions(domainName, selection)
+	if err != nil {
+		client.WriteJSON(models.WebSocketMessage{
+			Type:    "error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	client.jobsMutex.Lock()
+	client.jobs[job.ID] = true
+	client.jobsMutex.Unlock()
+
 	// Send start message
-	conn.WriteJSON(models.WebSocketMessage{
+	client.WriteJSON(models.WebSocketMessage{
 		Type: "bulk_check_started",
 		Data: map[string]interface{}{
-			"domain_name": domainName,
+			"domain_name": job.DomainName,
+			"job_id":      job.ID,
+			"preset":      job.Preset,
+			"total":       job.Total,
 		},
 	})
 
-	// Start bulk check with progress updates
-	go h.performBulkCheckWithProgress(conn, domainName)
+	// Stream progress updates
+	go h.streamJobProgress(client, job.ID)
 }
 
-// performBulkCheckWithProgress performs bulk check and sends progress updates
-func (h *WebSocketHandler) performBulkCheckWithProgress(conn *websocket.Conn, domainName string) {
-	startTime := time.Now()
-
-	// Get all extensions
-	extensions := h.domainService.GetValidExtensions()
-	totalExtensions := len(extensions)
-
-	progress := models.WebSocketBulkProgress{
-		DomainName:         domainName,
-		TotalExtensions:    totalExtensions,
-		CheckedCount:       0,
-		AvailableCount:     0,
-		UnavailableCount:   0,
-		ErrorCount:         0,
-		AvailableDomains:   []models.WebSocketDomainCheck{},
-		UnavailableDomains: []models.WebSocketDomainCheck{},
-		IsComplete:         false,
+// stringList returns the strings of a JSON array decoded from a message
+func stringList(value interface{}) []string {
+	items, _ := value.([]interface{})
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		if str, ok := item.(string); ok {
+			result = append(result, str)
+		}
 	}
+	return result
+}
 
-	// Create channels for concurrent processing
-	results := make(chan models.WebSocketDomainCheck, totalExtensions)
-	errors := make(chan error, totalExtensions)
+// handleCancelJob cancels a job started by this client
+func (h *WebSocketHandler) handleCancelJob(client *wsClient, msg models.WebSocketMessage) {
+	data, _ := msg.Data.(map[string]interface{})
+	jobID, _ := data["job_id"].(string)
 
-	// Start concurrent domain checks
-	semaphore := make(chan struct{}, 10) // Limit concurrent connections
-	var wg sync.WaitGroup
+	client.jobsMutex.Lock()
+	owned := client.jobs[jobID]
+	client.jobsMutex.Unlock()
+	if !owned {
+		client.WriteJSON(models.WebSocketMessage{
+			Type:    "error",
+			Message: "Unknown job ID",
+		})
+		return
+	}
 
-	for _, ext := range extensions {
-		wg.Add(1)
-		go func(extension string) {
-			defer wg.Done()
-			semaphore <- struct{}{}        // Acquire semaphore
-			defer func() { <-semaphore }() // Release semaphore
+	if _, err := h.jobManager.Cancel(jobID); err != nil {
+		client.WriteJSON(models.WebSocketMessage{
+			Type:    "error",
+			Message: err.Error(),
+		})
+	}
+	// The progress stream reports the cancellation
+}
 
-			fullDomain := domainName + "." + extension
-			start := time.Now()
+// streamJobProgress sends a progress message for every result of a job
+// and a final message once it finishes
+func (h *WebSocketHandler) streamJobProgress(client *wsClient, jobID string) {
+	updates, unwatch, err := h.jobManager.Watch(jobID)
+	if err != nil {
+		client.WriteJSON(models.WebSocketMessage{
+			Type:    "error",
+			Message: err.Error(),
+		})
+		return
+	}
+	defer unwatch()
 
-			result, err := h.domainService.CheckDomain(nil, fullDomain)
-			responseTime := time.Since(start).Milliseconds()
+	var progress *models.WebSocketBulkProgress
+	offset := 0
+	for range updates {
+		job, results, err := h.jobManager.ResultsSince(jobID, offset)
+		if err != nil {
+			return
+		}
+		offset += len(results)
 
-			if err != nil {
-				errors <- err
-				return
-			}
+		if progress == nil {
+			progress = newBulkProgress(job)
+		}
+		progress.Status = job.Status
 
-			wsResult := models.WebSocketDomainCheck{
-				Domain:       fullDomain,
-				Status:       result.Domain.Status,
-				IP:           result.Domain.IP,
-				ResponseTime: responseTime,
-				CheckedAt:    time.Now().Format(time.RFC3339),
+		for _, result := range results {
+			if !addBulkResult(progress, result) {
+				continue
 			}
 
-			results <- wsResult
-		}(ext)
-	}
+			// Send progress update
+			if err := client.WriteJSON(models.WebSocketMessage{
+				Type: "bulk_check_progress",
+				Data: progress,
+			}); err != nil {
+				return
+			}
+		}
 
-	// Close channels when all goroutines complete
-	go func() {
-		wg.Wait()
-		close(results)
-		close(errors)
-	}()
+		if job.Status.IsFinished() {
+			completeBulkProgress(progress, job)
 
-	// Process results
-	for result := range results {
-		progress.CheckedCount++
-		progress.CurrentDomain = &result
-
-		if result.Status == "Available" {
-			progress.AvailableCount++
-			progress.AvailableDomains = append(progress.AvailableDomains, result)
-		} else {
-			progress.UnavailableCount++
-			progress.UnavailableDomains = append(progress.UnavailableDomains, result)
+			// Send final result
+			client.WriteJSON(models.WebSocketMessage{
+				Type: "bulk_check_complete",
+				Data: progress,
+			})
+			return
 		}
+	}
+}
 
-		// Send progress update
-		conn.WriteJSON(models.WebSocketMessage{
-			Type: "bulk_check_progress",
-			Data: progress,
-		})
+// newBulkProgress creates the progress report of a job
+func newBulkProgress(job *models.Job) *models.WebSocketBulkProgress {
+	return &models.WebSocketBulkProgress{
+		JobID:              job.ID,
+		Status:             job.Status,
+		DomainName:         job.DomainName,
+		TotalExtensions:    job.Total,
+		AvailableDomains:   []models.WebSocketDomainCheck{},
+		UnavailableDomains: []models.WebSocketDomainCheck{},
 	}
+}
 
-	// Process errors
-	for range errors {
+// addBulkResult counts a job result and reports whether it was a completed check
+func addBulkResult(progress *models.WebSocketBulkProgress, result models.JobResult) bool {
+	progress.CheckedCount++
+	if result.Result == nil {
 		progress.ErrorCount++
-		progress.CheckedCount++
+		return false
 	}
 
-	// Mark as complete
+	check := models.WebSocketDomainCheck{
+		Domain:       result.Domain,
+		Status:       result.Result.Status,
+		Availability: result.Result.Availability,
+		Confidence:   result.Result.Confidence,
+		IP:           result.Result.IP,
+		ResponseTime: result.Result.ResponseTime,
+		CheckedAt:    result.Result.CheckedAt.Format(time.RFC3339),
+	}
+	progress.CurrentDomain = &check
+
+	if check.Status == models.LegacyStatusAvailable {
+		progress.AvailableCount++
+		progress.AvailableDomains = append(progress.AvailableDomains, check)
+	} else if check.Availability == models.StatusRateLimited {
+		progress.RateLimitedCount++
+	} else {
+		progress.UnavailableCount++
+		progress.UnavailableDomains = append(progress.UnavailableDomains, check)
+	}
+	return true
+}
+
+// completeBulkProgress marks the progress report of a finished job as complete
+func completeBulkProgress(progress *models.WebSocketBulkProgress, job *models.Job) {
+	progress.Status = job.Status
 	progress.IsComplete = true
-	progress.TotalTime = time.Since(startTime).Milliseconds()
 	progress.CurrentDomain = nil
-
-	// Send final result
-	conn.WriteJSON(models.WebSocketMessage{
-		Type: "bulk_check_complete",
-		Data: progress,
-	})
+	if job.StartedAt != nil && job.FinishedAt != nil {
+		progress.TotalTime = job.FinishedAt.Sub(*job.StartedAt).Milliseconds()
+	}
 }
 
 // BroadcastToAll sends a message to all connected clients
@@ -229,12 +322,11 @@ func (h *WebSocketHandler) BroadcastToAll(msg models.WebSocketMessage) {
 	h.mutex.RLock()
 	defer h.mutex.RUnlock()
 
-	for client := range h.clients {
+	for conn, client := range h.clients {
 		err := client.WriteJSON(msg)
 		if err != nil {
 			log.Printf("Failed to send message to client: %v", err)
-			client.Close()
-			delete(h.clients, client)
+			conn.Close()
 		}
 	}
 }
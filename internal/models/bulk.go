@@ -0,0 +1,33 @@
+package models
+
+// Bulk check stream line types
+const (
+	BulkLineResult  = "result"  // A domain was checked, or its check failed
+	BulkLineInvalid = "invalid" // An input line is not a valid domain
+	BulkLineSummary = "summary" // Totals, always the last line
+)
+
+// BulkCheckItem represents the outcome of one domain in a streamed bulk check
+type BulkCheckItem struct {
+	Type   string  `json:"type"`
+	Line   int     `json:"line,omitempty"` // Line of the uploaded list
+	Domain string  `json:"domain"`
+	Result *Domain `json:"result,omitempty"`
+	Error  string  `json:"error,omitempty"`
+}
+
+// BulkUploadSummary represents the totals of a streamed upload check
+type BulkUploadSummary struct {
+	Type             string `json:"type"`
+	TotalLines       int    `json:"total_lines"`
+	ValidCount       int    `json:"valid_count"`
+	InvalidCount     int    `json:"invalid_count"`
+	DuplicateCount   int    `json:"duplicate_count"`
+	CheckedCount     int    `json:"checked_count"`
+	AvailableCount   int    `json:"available_count"`
+	UnavailableCount int    `json:"unavailable_count"`
+	RateLimitedCount int    `json:"rate_limited_count"`
+	ErrorCount       int    `json:"error_count"`
+	IsComplete       bool   `json:"is_complete"` // False if the client went away or the request timed out
+	TotalTime        int64  `json:"total_time_ms"`
+}
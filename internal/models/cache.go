@@ -0,0 +1,23 @@
+package models
+
+// CacheStats represents the state and hit rate of the domain check result cache
+type CacheStats struct {
+	Enabled     bool    `json:"enabled"`
+	Entries     int     `json:"entries"`
+	MaxEntries  int     `json:"max_entries"`
+	Hits        uint64  `json:"hits"`
+	Misses      uint64  `json:"misses"`
+	Bypasses    uint64  `json:"bypasses"` // Checks that skipped the cache on request
+	Evictions   uint64  `json:"evictions"`
+	HitRate     float64 `json:"hit_rate"` // Hits per cache lookup, bypasses excluded
+	PositiveTTL int64   `json:"positive_ttl_seconds"`
+	NegativeTTL int64   `json:"negative_ttl_seconds"`
+}
+
+// InFlightStats represents how many lookups concurrent identical checks shared
+type InFlightStats struct {
+	InFlight   int     `json:"in_flight"` // Lookups running right now
+	Lookups    uint64  `json:"lookups"`   // Lookups started
+	Coalesced  uint64  `json:"coalesced"` // Checks that joined a running lookup instead of starting one
+	SavedRatio float64 `json:"saved_ratio"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+)
+
+// DNSRecord represents a single DNS resource record
+type DNSRecord struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	TTL   uint32 `json:"ttl"`
+	Value string `json:"value"` // Presentation format, e.g. "10 mx.example.com." for MX
+}
+
+// DNSRecordSet represents the records of one type returned for a domain
+type DNSRecordSet struct {
+	Type     string      `json:"type"`
+	Resolver string      `json:"resolver,omitempty"` // Upstream resolver that answered
+	Records  []DNSRecord `json:"records"`
+	Error    string      `json:"error,omitempty"`
+}
+
+// DNSInspection represents the DNS records of a domain
+type DNSInspection struct {
+	Domain       string         `json:"domain"`
+	RecordSets   []DNSRecordSet `json:"record_sets"`
+	CheckedAt    time.Time      `json:"checked_at"`
+	ResponseTime int64          `json:"response_time_ms"`
+}
+
+// DNSInspectionRequest represents the request payload for batch DNS inspection
+type DNSInspectionRequest struct {
+	Domains []string `json:"domains" binding:"required,min=1,max=50"`
+	Types   []string `json:"types"`
+}
@@ -6,16 +6,26 @@ import (
 
 // Domain represents a domain check result
 type Domain struct {
-	ID           int       `json:"id"`
-	Name         string    `json:"name"`
-	Extension    string    `json:"extension"`
-	Available    bool      `json:"available"`
-	Status       string    `json:"status"` // "Available", "Registered", "Error"
-	IP           string    `json:"ip,omitempty"`
-	DNSResolved  bool      `json:"dns_resolved"`
-	CheckedAt    time.Time `json:"checked_at"`
-	ResponseTime int64     `json:"response_time_ms"`
-	Error        string    `json:"error,omitempty"`
+	ID           int                `json:"id"`
+	Name         string             `json:"name"`                   // ASCII form used for lookups; internationalized names as A-labels
+	UnicodeName  string             `json:"unicode_name,omitempty"` // Unicode form (U-labels) of an internationalized name, e.g. "çiçek.com"
+	Extension    string             `json:"extension"`              // Public suffix, e.g. ".co.uk"
+	Subdomain    string             `json:"subdomain,omitempty"`    // Labels of the input below the checked registrable domain
+	Available    bool               `json:"available"`
+	Status       string             `json:"status"` // "Available", "Registered", "Error"; kept for backward compatibility
+	Availability AvailabilityStatus `json:"availability"`
+	Confidence   Confidence         `json:"confidence"`
+	Evidence     []Evidence         `json:"evidence,omitempty"`
+	Attempts     []CheckAttempt     `json:"attempts,omitempty"` // Every checker attempt, listed when any of them failed
+	IP           string             `json:"ip,omitempty"`
+	DNSResolved  bool               `json:"dns_resolved"`
+	Checker      string             `json:"checker,omitempty"`    // Checker that produced the verdict, e.g. "dns"
+	Delegation   string             `json:"delegation,omitempty"` // "delegated", "nxdomain", "not_delegated"
+	CheckedAt    time.Time          `json:"checked_at"`
+	ResponseTime int64              `json:"response_time_ms"`
+	Error        string             `json:"error,omitempty"`
+	Cached       bool               `json:"cached,omitempty"`    // Answered from the result cache
+	CacheAge     int64              `json:"cache_age,omitempty"` // Seconds since the cached result was checked
 }
 
 // DomainCheckRequest represents the request payload for domain checking
@@ -23,6 +33,13 @@ type DomainCheckRequest struct {
 	Domain string `json:"domain" binding:"required" validate:"required,min=1"`
 }
 
+// CheckAllExtensionsRequest represents the request payload for checking a
+// name with every extension, or with the selected ones
+type CheckAllExtensionsRequest struct {
+	DomainName string `json:"domain_name" binding:"required"`
+	ExtensionSelection
+}
+
 // DomainCheckResponse represents the response for domain checking
 type DomainCheckResponse struct {
 	Domain       *Domain `json:"domain"`
@@ -33,9 +50,11 @@ type DomainCheckResponse struct {
 // AllExtensionsCheckResult represents the result for checking all extensions
 type AllExtensionsCheckResult struct {
 	DomainName         string                `json:"domain_name"`
+	Preset             string                `json:"preset,omitempty"` // Extension preset the check was limited to
 	TotalExtensions    int                   `json:"total_extensions"`
 	AvailableCount     int                   `json:"available_count"`
 	UnavailableCount   int                   `json:"unavailable_count"`
+	RateLimitedCount   int                   `json:"rate_limited_count"`
 	ErrorCount         int                   `json:"error_count"`
 	CheckedAt          time.Time             `json:"checked_at"`
 	TotalTime          int64                 `json:"total_time_ms"`
@@ -72,6 +91,7 @@ type Meta struct {
 	TotalPages  int    `json:"total_pages,omitempty"`
 	RequestID   string `json:"request_id,omitempty"`
 	ProcessTime int64  `json:"process_time_ms,omitempty"`
+	NextCursor  string `json:"next_cursor,omitempty"`
 }
 
 // HealthResponse represents health check response
@@ -92,20 +112,25 @@ type WebSocketMessage struct {
 
 // WebSocketDomainCheck represents a domain check result for WebSocket
 type WebSocketDomainCheck struct {
-	Domain       string `json:"domain"`
-	Status       string `json:"status"`
-	IP           string `json:"ip,omitempty"`
-	ResponseTime int64  `json:"response_time_ms"`
-	CheckedAt    string `json:"checked_at"`
+	Domain       string             `json:"domain"`
+	Status       string             `json:"status"`
+	Availability AvailabilityStatus `json:"availability"`
+	Confidence   Confidence         `json:"confidence"`
+	IP           string             `json:"ip,omitempty"`
+	ResponseTime int64              `json:"response_time_ms"`
+	CheckedAt    string             `json:"checked_at"`
 }
 
 // WebSocketBulkProgress represents bulk check progress
 type WebSocketBulkProgress struct {
+	JobID              string                 `json:"job_id,omitempty"`
+	Status             JobStatus              `json:"status,omitempty"`
 	DomainName         string                 `json:"domain_name"`
 	TotalExtensions    int                    `json:"total_extensions"`
 	CheckedCount       int                    `json:"checked_count"`
 	AvailableCount     int                    `json:"available_count"`
 	UnavailableCount   int                    `json:"unavailable_count"`
+	RateLimitedCount   int                    `json:"rate_limited_count"`
 	ErrorCount         int                    `json:"error_count"`
 	CurrentDomain      *WebSocketDomainCheck  `json:"current_domain,omitempty"`
 	AvailableDomains   []WebSocketDomainCheck `json:"available_domains"`
@@ -116,15 +141,15 @@ type WebSocketBulkProgress struct {
 
 // WhoisInfo represents WHOIS information for a domain
 type WhoisInfo struct {
-	Domain         string    `json:"domain"`
-	Registrar      string    `json:"registrar,omitempty"`
-	CreationDate   string    `json:"creation_date,omitempty"`
-	ExpirationDate string    `json:"expiration_date,omitempty"`
-	UpdatedDate    string    `json:"updated_date,omitempty"`
-	NameServers    []string  `json:"name_servers,omitempty"`
-	Status         []string  `json:"status,omitempty"`
-	AdminContact   string    `json:"admin_contact,omitempty"`
-	TechContact    string    `json:"tech_contact,omitempty"`
-	RawData        string    `json:"raw_data,omitempty"`
-	CheckedAt      time.Time `json:"checked_at"`
+	Domain         string     `json:"domain"`
+	Registrar      string     `json:"registrar,omitempty"`
+	CreationDate   *time.Time `json:"creation_date,omitempty"`
+	ExpirationDate *time.Time `json:"expiration_date,omitempty"`
+	UpdatedDate    *time.Time `json:"updated_date,omitempty"`
+	NameServers    []string   `json:"name_servers,omitempty"`
+	Status         []string   `json:"status,omitempty"`
+	AdminContact   string     `json:"admin_contact,omitempty"`
+	TechContact    string     `json:"tech_contact,omitempty"`
+	RawData        string     `json:"raw_data,omitempty"`
+	CheckedAt      time.Time  `json:"checked_at"`
 }
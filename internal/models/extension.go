@@ -0,0 +1,97 @@
+package models
+
+import "time"
+
+// Extension types
+const (
+	ExtensionTypeGeneric     = "gTLD"     // Generic TLDs predating the new gTLD program, e.g. ".com"
+	ExtensionTypeCountryCode = "ccTLD"    // Country code TLDs and their second-level suffixes, e.g. ".co.uk"
+	ExtensionTypeNewGeneric  = "new gTLD" // Generic TLDs delegated since 2013, e.g. ".app"
+)
+
+// ExtensionInfo describes a domain extension and its registry. It is both an
+// entry of the extensions file and an item of the extensions endpoint.
+type ExtensionInfo struct {
+	Extension        string          `json:"extension" yaml:"extension"`                   // With a leading dot; internationalized extensions as A-labels
+	UnicodeExtension string          `json:"unicode_extension,omitempty" yaml:"-"`         // Unicode form of an internationalized extension, e.g. ".рф"
+	Type             string          `json:"type,omitempty" yaml:"type"`                   // gTLD, ccTLD or new gTLD
+	Category         string          `json:"category,omitempty" yaml:"category"`           // e.g. "generic", "technology" or a region for ccTLDs
+	Popularity       int             `json:"popularity,omitempty" yaml:"popularity"`       // Rank by registrations, 1 being the most popular; 0 if unranked
+	WhoisServer      string          `json:"whois_server,omitempty" yaml:"whois_server"`   // Port-43 WHOIS server
+	RDAPBaseURL      string          `json:"rdap_base_url,omitempty" yaml:"rdap_base_url"` // RDAP service overriding the bootstrap file
+	Restrictions     string          `json:"restrictions,omitempty" yaml:"restrictions"`   // Who may register; empty if open to everyone
+	MinLength        int             `json:"min_length" yaml:"min_length"`                 // Shortest label the registry accepts
+	MaxLength        int             `json:"max_length" yaml:"max_length"`                 // Longest label the registry accepts
+	IDN              bool            `json:"idn" yaml:"idn"`                               // Registry accepts internationalized names
+	TypicalPrice     *ExtensionPrice `json:"typical_price,omitempty" yaml:"typical_price"` // Typical yearly registration price
+}
+
+// ExtensionPrice is a registration price
+type ExtensionPrice struct {
+	Amount   float64 `json:"amount" yaml:"amount"`
+	Currency string  `json:"currency" yaml:"currency"` // ISO 4217 code, e.g. "USD"
+}
+
+// ExtensionFilter selects extensions by their metadata
+type ExtensionFilter struct {
+	Type          []string `json:"type,omitempty" form:"type"`                     // gTLD, ccTLD or new gTLD (case-insensitive)
+	Category      []string `json:"category,omitempty" form:"category"`             // Case-insensitive categories
+	Search        string   `json:"q,omitempty" form:"q"`                           // Substring of the extension, ASCII or Unicode
+	IDN           *bool    `json:"idn,omitempty" form:"idn"`                       // Only extensions that do (not) accept internationalized names
+	Restricted    *bool    `json:"restricted,omitempty" form:"restricted"`         // Only extensions with (without) registration restrictions
+	MaxPopularity int      `json:"max_popularity,omitempty" form:"max_popularity"` // Only ranked extensions within the top N
+	MaxPrice      *float64 `json:"max_price,omitempty" form:"max_price"`           // Only extensions with a typical price up to this amount
+	LabelLength   int      `json:"label_length,omitempty" form:"label_length"`     // Only extensions accepting names of this many characters
+}
+
+// IsEmpty reports whether the filter has no criteria
+func (f ExtensionFilter) IsEmpty() bool {
+	return len(f.Type) == 0 && len(f.Category) == 0 && f.Search == "" && f.IDN == nil &&
+		f.Restricted == nil && f.MaxPopularity == 0 && f.MaxPrice == nil && f.LabelLength == 0
+}
+
+// ExtensionQuery filters and sorts the extension list
+type ExtensionQuery struct {
+	ExtensionFilter
+	Sort string `form:"sort"` // extension (default), popularity or price
+}
+
+// ExtensionSelection picks the extensions a bulk check runs with: those of a
+// preset or an explicit list, adjusted by Include and Exclude. Without a
+// preset or list every valid extension is checked.
+type ExtensionSelection struct {
+	Preset     string   `json:"preset,omitempty"`     // Name of an extension preset, e.g. "popular"
+	Extensions []string `json:"extensions,omitempty"` // Explicit extensions instead of a preset
+	Include    []string `json:"include,omitempty"`    // Extensions added to the selection
+	Exclude    []string `json:"exclude,omitempty"`    // Extensions removed from the selection
+}
+
+// ExtensionPreset is a named set of extensions: the listed ones plus those
+// matching the filter
+type ExtensionPreset struct {
+	Name               string           `json:"name"`
+	Description        string           `json:"description,omitempty"`
+	Extensions         []string         `json:"extensions,omitempty"`
+	Filter             *ExtensionFilter `json:"filter,omitempty"`
+	BuiltIn            bool             `json:"built_in"`                      // Defined in configuration and read-only
+	ExtensionCount     int              `json:"extension_count"`               // Valid extensions currently in the preset
+	ResolvedExtensions []string         `json:"resolved_extensions,omitempty"` // Valid extensions currently in the preset, when a single preset is requested
+	CreatedAt          *time.Time       `json:"created_at,omitempty"`
+	UpdatedAt          *time.Time       `json:"updated_at,omitempty"`
+}
+
+// ExtensionPresetRequest represents the request payload for creating an extension preset
+type ExtensionPresetRequest struct {
+	Name        string           `json:"name" binding:"required"`
+	Description string           `json:"description"`
+	Extensions  []string         `json:"extensions"`
+	Filter      *ExtensionFilter `json:"filter"`
+}
+
+// ExtensionPresetUpdateRequest represents the request payload for changing an
+// extension preset; fields left out are not changed
+type ExtensionPresetUpdateRequest struct {
+	Description *string          `json:"description"`
+	Extensions  []string         `json:"extensions"`
+	Filter      *ExtensionFilter `json:"filter"` // An empty filter removes it
+}
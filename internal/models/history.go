@@ -0,0 +1,26 @@
+package models
+
+// HistoryQuery represents the filters, sorting and paging of a history search
+type HistoryQuery struct {
+	Status          []string `form:"status"`            // Typed or legacy statuses, e.g. "available" or "Registered"
+	Extension       []string `form:"extension"`         // Extensions with or without the leading dot
+	Name            string   `form:"name"`              // Case-insensitive name substring
+	NameRegex       string   `form:"name_regex"`        // Regular expression matched against the name
+	From            string   `form:"from"`              // Earliest checked_at, RFC 3339 or YYYY-MM-DD
+	To              string   `form:"to"`                // Latest checked_at, RFC 3339 or YYYY-MM-DD (whole day)
+	MinResponseTime *int64   `form:"min_response_time"` // Milliseconds
+	MaxResponseTime *int64   `form:"max_response_time"` // Milliseconds
+	DNSResolved     *bool    `form:"dns_resolved"`
+	Sort            string   `form:"sort"`  // id, name, extension, status, checked_at, response_time or dns_resolved
+	Order           string   `form:"order"` // asc or desc
+	Cursor          string   `form:"cursor"`
+	Page            int      `form:"page"`
+	PerPage         int      `form:"per_page"`
+}
+
+// HistoryPage represents one page of a history search
+type HistoryPage struct {
+	Items      []Domain
+	Total      int    // Number of entries matching the filters
+	NextCursor string // Empty on the last page
+}
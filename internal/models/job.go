@@ -0,0 +1,80 @@
+package models
+
+import "time"
+
+// JobStatus represents the lifecycle state of a bulk check job
+type JobStatus string
+
+// Job states
+const (
+	JobStatusQueued    JobStatus = "queued"
+	JobStatusRunning   JobStatus = "running"
+	JobStatusCompleted JobStatus = "completed"
+	JobStatusCancelled JobStatus = "cancelled"
+	JobStatusFailed    JobStatus = "failed"
+)
+
+// IsFinished reports whether the job will make no further progress
+func (s JobStatus) IsFinished() bool {
+	return s == JobStatusCompleted || s == JobStatusCancelled || s == JobStatusFailed
+}
+
+// Job types
+const (
+	JobTypeCheckAllExtensions = "check_all_extensions"
+	JobTypeCheckDomains       = "check_domains"
+)
+
+// Job represents an asynchronous bulk domain check
+type Job struct {
+	ID               string      `json:"id"`
+	Type             string      `json:"type"`
+	DomainName       string      `json:"domain_name,omitempty"` // Name checked against every extension, or the selected ones
+	Preset           string      `json:"preset,omitempty"`      // Extension preset the name is checked with
+	Status           JobStatus   `json:"status"`
+	Total            int         `json:"total"`
+	CheckedCount     int         `json:"checked_count"`
+	AvailableCount   int         `json:"available_count"`
+	UnavailableCount int         `json:"unavailable_count"`
+	RateLimitedCount int         `json:"rate_limited_count"`
+	ErrorCount       int         `json:"error_count"`
+	CreatedAt        time.Time   `json:"created_at"`
+	StartedAt        *time.Time  `json:"started_at,omitempty"`
+	FinishedAt       *time.Time  `json:"finished_at,omitempty"`
+	ExpiresAt        *time.Time  `json:"expires_at,omitempty"` // When a finished job is discarded
+	Error            string      `json:"error,omitempty"`
+	Results          []JobResult `json:"results,omitempty"`
+}
+
+// JobResult represents the outcome of one domain in a job
+type JobResult struct {
+	Domain string  `json:"domain"`
+	Result *Domain `json:"result,omitempty"`
+	Error  string  `json:"error,omitempty"`
+}
+
+// JobRequest represents the request payload for submitting a bulk check job.
+// Exactly one of DomainName and Domains must be set; the extension selection
+// applies to DomainName.
+type JobRequest struct {
+	DomainName string   `json:"domain_name"` // Check this name with all or the selected extensions
+	Domains    []string `json:"domains"`     // Check these fully-qualified domains
+	ExtensionSelection
+}
+
+// JobConfig is the snapshot of the checking configuration a job runs with,
+// so that a resumed job keeps behaving the way it was submitted
+type JobConfig struct {
+	TimeoutMs           int64               `json:"timeout_ms"`
+	MaxConcurrentChecks int                 `json:"max_concurrent_checks"`
+	Strategy            []string            `json:"strategy,omitempty"`
+	TLDStrategies       map[string][]string `json:"tld_strategies,omitempty"`
+}
+
+// JobState is the persisted form of a job. Results are stored separately
+// as they complete and are filled into Job.Results when loading.
+type JobState struct {
+	Job     Job       `json:"job"`
+	Domains []string  `json:"domains"`
+	Config  JobConfig `json:"config"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// PortfolioDomain represents an owned domain whose expiration is tracked
+type PortfolioDomain struct {
+	ID              int        `json:"id"`
+	Domain          string     `json:"domain"`
+	Note            string     `json:"note,omitempty"`
+	Registrar       string     `json:"registrar,omitempty"`
+	ExpirationDate  *time.Time `json:"expiration_date,omitempty"`
+	DaysLeft        *int       `json:"days_left,omitempty"`     // Whole days until expiration at the time of the response
+	RemindedFor     *time.Time `json:"reminded_for,omitempty"`  // Expiration date the reminders below were sent for
+	RemindedDays    int        `json:"reminded_days,omitempty"` // Smallest threshold a reminder was sent for
+	LastError       string     `json:"last_error,omitempty"`    // Why the last refresh failed
+	LastRefreshedAt *time.Time `json:"last_refreshed_at,omitempty"`
+	NextRefreshAt   time.Time  `json:"next_refresh_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// PortfolioReminder is emitted when an owned domain crosses a reminder threshold
+type PortfolioReminder struct {
+	Domain         PortfolioDomain `json:"domain"`
+	ThresholdDays  int             `json:"threshold_days"`
+	DaysLeft       int             `json:"days_left"`
+	ExpirationDate time.Time       `json:"expiration_date"`
+}
+
+// PortfolioRequest represents the request payload for adding an owned domain
+type PortfolioRequest struct {
+	Domain string `json:"domain" binding:"required"`
+	Note   string `json:"note"`
+}
+
+// PortfolioUpdateRequest represents the request payload for changing an owned domain;
+// fields left out are not changed
+type PortfolioUpdateRequest struct {
+	Note *string `json:"note"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+)
+
+// Rate limit scopes
+const (
+	RateLimitScopeTLD      = "tld"      // Lookups per extension
+	RateLimitScopeServer   = "server"   // Queries per WHOIS or RDAP server host
+	RateLimitScopeResolver = "resolver" // Queries per upstream resolver
+)
+
+// RateLimitStatus represents the state of the token bucket of one TLD, server or resolver
+type RateLimitStatus struct {
+	Scope           string     `json:"scope"`
+	Key             string     `json:"key"`
+	Rate            float64    `json:"rate"` // Requests per second; 0 means unlimited
+	Burst           int        `json:"burst"`
+	Tokens          float64    `json:"tokens"`
+	Requests        uint64     `json:"requests"`
+	Delayed         uint64     `json:"delayed"`   // Requests that waited for their turn
+	Rejected        uint64     `json:"rejected"`  // Requests reported as rate limited without being sent
+	Throttled       uint64     `json:"throttled"` // Times the server signalled rate limiting
+	BackoffUntil    *time.Time `json:"backoff_until,omitempty"`
+	LastThrottledAt *time.Time `json:"last_throttled_at,omitempty"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+)
+
+// ResolverStatus represents the health of an upstream DNS resolver
+type ResolverStatus struct {
+	Address      string     `json:"address"`
+	Protocol     string     `json:"protocol"` // "udp", "tcp", "dot", "doh"
+	Healthy      bool       `json:"healthy"`
+	Queries      int64      `json:"queries"`
+	Failures     int64      `json:"failures"`
+	FailureRate  float64    `json:"failure_rate"`
+	LatencyMs    float64    `json:"latency_ms"`
+	LastError    string     `json:"last_error,omitempty"`
+	LastUsed     *time.Time `json:"last_used,omitempty"`
+	EjectedUntil *time.Time `json:"ejected_until,omitempty"`
+}
@@ -0,0 +1,24 @@
+package models
+
+// Lookup error classes, used to decide whether a failed check is retried
+const (
+	ErrorClassTimeout           = "timeout"            // The server did not answer in time
+	ErrorClassServFail          = "servfail"           // A DNS server answered SERVFAIL
+	ErrorClassRefused           = "refused"            // A DNS server answered REFUSED
+	ErrorClassConnectionRefused = "connection_refused" // Nothing listens at the server's address
+	ErrorClassNetwork           = "network"            // Other connection failures, e.g. resets
+	ErrorClassServerError       = "server_error"       // An RDAP server answered with a 5xx status
+	ErrorClassRateLimited       = "rate_limited"       // Held back by a rate limit
+	ErrorClassOther             = "other"              // Anything else, e.g. malformed responses
+)
+
+// CheckAttempt records one attempt of a checker to reach a verdict
+type CheckAttempt struct {
+	Checker    string `json:"checker"`
+	Attempt    int    `json:"attempt"` // Counts from 1 for every checker
+	Error      string `json:"error,omitempty"`
+	ErrorClass string `json:"error_class,omitempty"`
+	Retried    bool   `json:"retried"`              // Whether another attempt followed
+	BackoffMs  int64  `json:"backoff_ms,omitempty"` // Wait before the next attempt
+	DurationMs int64  `json:"duration_ms"`
+}
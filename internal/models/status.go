@@ -0,0 +1,71 @@
+package models
+
+// AvailabilityStatus represents the typed availability state of a domain
+type AvailabilityStatus string
+
+// Availability states
+const (
+	StatusAvailable     AvailabilityStatus = "available"
+	StatusRegistered    AvailabilityStatus = "registered"
+	StatusReserved      AvailabilityStatus = "reserved"       // Blocked by the registry, cannot be registered
+	StatusPremium       AvailabilityStatus = "premium"        // Available at a premium price
+	StatusRedemption    AvailabilityStatus = "redemption"     // Expired, recoverable by the previous owner
+	StatusPendingDelete AvailabilityStatus = "pending_delete" // About to be released by the registry
+	StatusServerHold    AvailabilityStatus = "server_hold"    // Registered but withheld from the DNS
+	StatusUnknown       AvailabilityStatus = "unknown"
+	StatusRateLimited   AvailabilityStatus = "rate_limited"
+	StatusTimeout       AvailabilityStatus = "timeout"
+)
+
+// Legacy status strings kept for backward compatibility
+const (
+	LegacyStatusAvailable  = "Available"
+	LegacyStatusRegistered = "Registered"
+	LegacyStatusError      = "Error"
+)
+
+// IsAvailable reports whether the domain can be registered now
+func (s AvailabilityStatus) IsAvailable() bool {
+	return s == StatusAvailable || s == StatusPremium
+}
+
+// IsConclusive reports whether the status is a verdict rather than a failure to reach one
+func (s AvailabilityStatus) IsConclusive() bool {
+	switch s {
+	case StatusUnknown, StatusRateLimited, StatusTimeout, "":
+		return false
+	default:
+		return true
+	}
+}
+
+// LegacyStatus maps the typed status to the "Available"/"Registered"/"Error"
+// string. Only these values are ever returned, so older clients keep working;
+// states without a legacy value, like rate_limited, show as "Error".
+func (s AvailabilityStatus) LegacyStatus() string {
+	switch {
+	case s.IsAvailable():
+		return LegacyStatusAvailable
+	case s.IsConclusive():
+		return LegacyStatusRegistered
+	default:
+		return LegacyStatusError
+	}
+}
+
+// Confidence represents how trustworthy an availability verdict is
+type Confidence string
+
+// Confidence levels
+const (
+	ConfidenceHigh   Confidence = "high"   // Authoritative registry answer (RDAP, WHOIS, TLD nameservers)
+	ConfidenceMedium Confidence = "medium" // Strong indirect signal, e.g. NXDOMAIN from a recursive resolver
+	ConfidenceLow    Confidence = "low"    // Weak signal, e.g. no address records
+	ConfidenceNone   Confidence = "none"   // No verdict could be reached
+)
+
+// Evidence records an observation that contributed to an availability verdict
+type Evidence struct {
+	Source string `json:"source"` // Checker that made the observation, e.g. "rdap"
+	Detail string `json:"detail"`
+}
@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+// WatchEntry represents a domain that is re-checked on a schedule
+type WatchEntry struct {
+	ID              int                `json:"id"`
+	Domain          string             `json:"domain"`
+	Note            string             `json:"note,omitempty"`
+	IntervalSeconds int64              `json:"interval_seconds"`
+	Availability    AvailabilityStatus `json:"availability,omitempty"` // Last conclusive status, empty until checked
+	Status          string             `json:"status,omitempty"`       // Legacy status string of Availability
+	RegistryStatus  []string           `json:"registry_status,omitempty"`
+	ExpirationDate  *time.Time         `json:"expiration_date,omitempty"`
+	ExpiryWarnedFor *time.Time         `json:"expiry_warned_for,omitempty"` // Expiration date an expiry event was sent for
+	LastError       string             `json:"last_error,omitempty"`        // Why the last check reached no verdict
+	LastCheckedAt   *time.Time         `json:"last_checked_at,omitempty"`
+	LastChangedAt   *time.Time         `json:"last_changed_at,omitempty"`
+	NextCheckAt     time.Time          `json:"next_check_at"`
+	CreatedAt       time.Time          `json:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at"`
+}
+
+// Interval returns how often the entry is re-checked
+func (e *WatchEntry) Interval() time.Duration {
+	return time.Duration(e.IntervalSeconds) * time.Second
+}
+
+// WatchTransition records a change of a watched domain's status
+type WatchTransition struct {
+	ID      int                `json:"id"`
+	EntryID int                `json:"entry_id"`
+	Domain  string             `json:"domain"`
+	From    AvailabilityStatus `json:"from,omitempty"` // Empty for the first check
+	To      AvailabilityStatus `json:"to"`
+	At      time.Time          `json:"at"`
+}
+
+// Watchlist event types
+const (
+	WatchEventDomainAvailable     = "domain_available"          // A registered domain became available
+	WatchEventDomainPendingDelete = "domain_pending_delete"     // A domain entered pending delete
+	WatchEventExpiryApproaching   = "domain_expiry_approaching" // A registered domain expires soon
+)
+
+// WatchEvent is emitted when a watched domain changes in a way worth acting on
+type WatchEvent struct {
+	Type       string           `json:"type"`
+	Entry      WatchEntry       `json:"entry"`
+	Transition *WatchTransition `json:"transition,omitempty"` // Not set for expiry events
+	Result     *Domain          `json:"result,omitempty"`     // Check that triggered the event
+}
+
+// WatchRequest represents the request payload for watching a domain
+type WatchRequest struct {
+	Domain          string `json:"domain" binding:"required"`
+	Note            string `json:"note"`
+	IntervalSeconds int64  `json:"interval_seconds"` // Zero uses the configured default
+}
+
+// WatchUpdateRequest represents the request payload for changing a watch entry;
+// fields left out are not changed
+type WatchUpdateRequest struct {
+	Note            *string `json:"note"`
+	IntervalSeconds *int64  `json:"interval_seconds"`
+}
@@ -0,0 +1,130 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Webhook event types
+const (
+	WebhookEventDomainAvailable     = "domain.available"          // A watched domain became available
+	WebhookEventDomainPendingDelete = "domain.pending_delete"     // A watched domain entered pending delete
+	WebhookEventExpiryApproaching   = "domain.expiry_approaching" // A watched domain expires soon
+	WebhookEventRenewalReminder     = "domain.renewal_reminder"   // An owned domain crossed a reminder threshold
+	WebhookEventJobCompleted        = "job.completed"             // A bulk check job finished
+	WebhookEventCheckerErrorRate    = "checker.error_rate_high"   // A checker keeps failing
+	WebhookEventPing                = "webhook.ping"              // Sent on request to test a webhook
+	WebhookEventAll                 = "*"                         // Subscribes to every event
+)
+
+// WebhookEvents lists the event types a webhook can subscribe to
+var WebhookEvents = []string{
+	WebhookEventDomainAvailable,
+	WebhookEventDomainPendingDelete,
+	WebhookEventExpiryApproaching,
+	WebhookEventRenewalReminder,
+	WebhookEventJobCompleted,
+	WebhookEventCheckerErrorRate,
+}
+
+// Webhook represents a registered receiver of event notifications
+type Webhook struct {
+	ID          int       `json:"id"`
+	URL         string    `json:"url"`
+	Events      []string  `json:"events"`
+	Secret      string    `json:"secret,omitempty"` // Only returned when the webhook is created
+	Description string    `json:"description,omitempty"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Subscribes reports whether the webhook wants events of the given type
+func (w *Webhook) Subscribes(event string) bool {
+	if event == WebhookEventPing {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == event || e == WebhookEventAll {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookRequest represents the request payload for registering a webhook.
+// A missing secret is generated.
+type WebhookRequest struct {
+	URL         string   `json:"url" binding:"required"`
+	Events      []string `json:"events" binding:"required,min=1"`
+	Secret      string   `json:"secret"`
+	Description string   `json:"description"`
+	Active      *bool    `json:"active"` // Defaults to true
+}
+
+// WebhookUpdateRequest represents the request payload for changing a webhook;
+// fields left out are not changed
+type WebhookUpdateRequest struct {
+	URL         *string  `json:"url"`
+	Events      []string `json:"events"`
+	Secret      *string  `json:"secret"`
+	Description *string  `json:"description"`
+	Active      *bool    `json:"active"`
+}
+
+// WebhookPayload is the body posted to a webhook
+type WebhookPayload struct {
+	ID        string      `json:"id"` // Unique per event, identical across retries and redeliveries
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// WebhookDomainEvent is the payload data of watched domain events
+type WebhookDomainEvent struct {
+	Domain         *Domain          `json:"domain,omitempty"` // Result of the check that triggered the event
+	Entry          *WatchEntry      `json:"entry"`
+	Transition     *WatchTransition `json:"transition,omitempty"`
+	ExpirationDate *time.Time       `json:"expiration_date,omitempty"`
+	DaysLeft       *int             `json:"days_left,omitempty"`
+}
+
+// WebhookJobEvent is the payload data of job events
+type WebhookJobEvent struct {
+	Job    *Job                      `json:"job"`
+	Result *AllExtensionsCheckResult `json:"result"`
+}
+
+// WebhookCheckerEvent is the payload data of checker error rate events
+type WebhookCheckerEvent struct {
+	Checker   string  `json:"checker"`
+	Checks    int     `json:"checks"`
+	Failures  int     `json:"failures"`
+	ErrorRate float64 `json:"error_rate"`
+	Threshold float64 `json:"threshold"`
+	WindowMs  int64   `json:"window_ms"`
+}
+
+// Webhook delivery states
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusSucceeded = "succeeded"
+	DeliveryStatusFailed    = "failed" // Every attempt failed
+)
+
+// WebhookDelivery records the attempts to post one event to one webhook
+type WebhookDelivery struct {
+	ID             int             `json:"id"`
+	WebhookID      int             `json:"webhook_id"`
+	EventID        string          `json:"event_id"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	ResponseStatus int             `json:"response_status,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	RedeliveryOf   int             `json:"redelivery_of,omitempty"` // Delivery this one manually repeats
+	CreatedAt      time.Time       `json:"created_at"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at,omitempty"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+}
@@ -0,0 +1,322 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"domaincheck/internal/models"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// Delegation states reported by the authoritative checker
+const (
+	// DelegationDelegated means the parent zone delegates the name to nameservers
+	DelegationDelegated = "delegated"
+	// DelegationNXDomain means the registry answered NXDOMAIN for the name
+	DelegationNXDomain = "nxdomain"
+	// DelegationNotDelegated means the name exists in the registry without NS records (e.g. serverHold)
+	DelegationNotDelegated = "not_delegated"
+)
+
+const (
+	// maxDelegationReferrals limits how many zone cuts are followed below the discovered zone
+	maxDelegationReferrals = 3
+	// minZoneServersTTL is the shortest time zone nameservers are cached
+	minZoneServersTTL = 5 * time.Minute
+)
+
+// zoneServers holds the cached nameserver addresses of a zone
+type zoneServers struct {
+	addresses []string
+	expires   time.Time // zero for static entries
+}
+
+// AuthoritativeChecker asks the parent zone's authoritative servers whether
+// a domain is delegated, instead of relying on recursive A/AAAA resolution
+type AuthoritativeChecker struct {
+	client *DNSClient
+	pool   *ResolverPool // recursive resolvers used to discover zone nameservers
+	port   string        // port of the nameservers found via glue or lookups
+	zones  map[string]zoneServers
+	mutex  sync.RWMutex
+}
+
+// NewAuthoritativeChecker creates a new authoritative delegation checker
+func NewAuthoritativeChecker(client *DNSClient, pool *ResolverPool) *AuthoritativeChecker {
+	return &AuthoritativeChecker{
+		client: client,
+		pool:   pool,
+		port:   "53",
+		zones:  make(map[string]zoneServers),
+	}
+}
+
+// Name returns the checker name
+func (c *AuthoritativeChecker) Name() string {
+	return "authoritative"
+}
+
+// SetZoneServers pins the nameserver addresses used for a zone (e.g. "com")
+func (c *AuthoritativeChecker) SetZoneServers(zone string, addresses []string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.zones[normalizeZone(zone)] = zoneServers{addresses: addresses}
+}
+
+// Check maps the registry's delegation state to a verdict
+func (c *AuthoritativeChecker) Check(ctx context.Context, domain string) (*CheckResult, error) {
+	delegation, ttl, err := c.delegation(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CheckResult{
+		Confidence: models.ConfidenceHigh,
+		Delegation: delegation,
+		TTL:        ttl,
+	}
+	switch delegation {
+	case DelegationNXDomain:
+		result.Availability = models.StatusAvailable
+		result.Evidence = []string{"parent zone nameservers answered NXDOMAIN"}
+	case DelegationNotDelegated:
+		// Registered names without NS records are usually on serverHold, but
+		// registries also park reserved names this way
+		result.Availability = models.StatusServerHold
+		result.Confidence = models.ConfidenceMedium
+		result.Evidence = []string{"name exists in parent zone without NS delegation"}
+	default:
+		result.Availability = models.StatusRegistered
+		result.Evidence = []string{"parent zone delegates the name to nameservers"}
+	}
+	return result, nil
+}
+
+// Delegation queries the parent zone's authoritative servers for the NS records of a domain
+func (c *AuthoritativeChecker) Delegation(ctx context.Context, domain string) (string, error) {
+	delegation, _, err := c.delegation(ctx, domain)
+	return delegation, err
+}
+
+// delegation returns the delegation state of a domain and how long the
+// answer it is based on may be cached
+func (c *AuthoritativeChecker) delegation(ctx context.Context, domain string) (string, time.Duration, error) {
+	domain = normalizeZone(domain)
+
+	zone, addresses, err := c.parentZoneServers(ctx, domain)
+	if err != nil {
+		return "", 0, err
+	}
+
+	for i := 0; i <= maxDelegationReferrals; i++ {
+		response, err := c.queryServers(ctx, addresses, domain)
+		if err != nil {
+			return "", 0, err
+		}
+
+		if response.RCode == dnsmessage.RCodeNameError {
+			return DelegationNXDomain, negativeTTL(response), nil
+		}
+
+		// An NS answer or a referral for the domain itself means it is delegated.
+		// A referral to a deeper zone (e.g. "co.uk" below "uk") is followed.
+		var referralZone string
+		var referralHosts []string
+		for _, rr := range append(response.Answers, response.Authorities...) {
+			ns, ok := rr.Body.(*dnsmessage.NSResource)
+			if !ok {
+				continue
+			}
+			owner := normalizeZone(rr.Header.Name.String())
+			if owner == domain {
+				return DelegationDelegated, time.Duration(rr.Header.TTL) * time.Second, nil
+			}
+			if isSubdomain(owner, zone) && isSubdomain(domain, owner) {
+				referralZone = owner
+				referralHosts = append(referralHosts, normalizeZone(ns.NS.String()))
+			}
+		}
+
+		if referralZone == "" {
+			// NOERROR without NS records: the name exists but is not delegated
+			return DelegationNotDelegated, negativeTTL(response), nil
+		}
+
+		addresses = glueAddresses(response, referralHosts, c.port)
+		if len(addresses) == 0 {
+			addresses, err = c.resolveHosts(ctx, referralHosts)
+			if err != nil {
+				return "", 0, err
+			}
+		}
+		zone = referralZone
+	}
+
+	return "", 0, fmt.Errorf("too many referrals while checking %s", domain)
+}
+
+// queryServers asks each authoritative server in turn until one gives a usable answer
+func (c *AuthoritativeChecker) queryServers(ctx context.Context, addresses []string, domain string) (*dnsmessage.Message, error) {
+	var lastErr error
+	for _, address := range addresses {
+		response, err := c.client.Query(ctx, address, domain, dnsmessage.TypeNS, false)
+		if err != nil {
+			lastErr = err
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+
+		switch response.RCode {
+		case dnsmessage.RCodeSuccess, dnsmessage.RCodeNameError:
+			return response, nil
+		default:
+			lastErr = &RCodeError{Server: address, Authoritative: true, RCode: response.RCode}
+		}
+	}
+
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no authoritative servers to query for %s", domain)
+	}
+	return nil, lastErr
+}
+
+// parentZoneServers finds the closest enclosing zone of a domain and its nameserver addresses
+func (c *AuthoritativeChecker) parentZoneServers(ctx context.Context, domain string) (string, []string, error) {
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return "", nil, fmt.Errorf("domain must have an extension")
+	}
+
+	var lastErr error
+	for i := 1; i < len(labels); i++ {
+		zone := strings.Join(labels[i:], ".")
+		addresses, err := c.zoneServers(ctx, zone)
+		if err != nil {
+			lastErr = err
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		if len(addresses) > 0 {
+			return zone, addresses, nil
+		}
+	}
+
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no parent zone found for %s", domain)
+	}
+	return "", nil, lastErr
+}
+
+// zoneServers returns the nameserver addresses of a zone, or none if the name is not a zone cut
+func (c *AuthoritativeChecker) zoneServers(ctx context.Context, zone string) ([]string, error) {
+	c.mutex.RLock()
+	cached, ok := c.zones[zone]
+	c.mutex.RUnlock()
+	if ok && (cached.expires.IsZero() || time.Now().Before(cached.expires)) {
+		return cached.addresses, nil
+	}
+
+	response, err := c.queryResolvers(ctx, zone, dnsmessage.TypeNS)
+	if err != nil {
+		return nil, err
+	}
+
+	var hosts []string
+	ttl := minZoneServersTTL
+	for _, rr := range response.Answers {
+		ns, ok := rr.Body.(*dnsmessage.NSResource)
+		if !ok || normalizeZone(rr.Header.Name.String()) != zone {
+			continue
+		}
+		hosts = append(hosts, normalizeZone(ns.NS.String()))
+		if recordTTL := time.Duration(rr.Header.TTL) * time.Second; recordTTL > ttl {
+			ttl = recordTTL
+		}
+	}
+
+	var addresses []string
+	if len(hosts) > 0 {
+		addresses = glueAddresses(response, hosts, c.port)
+		if len(addresses) == 0 {
+			addresses, err = c.resolveHosts(ctx, hosts)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	c.mutex.Lock()
+	c.zones[zone] = zoneServers{addresses: addresses, expires: time.Now().Add(ttl)}
+	c.mutex.Unlock()
+
+	return addresses, nil
+}
+
+// queryResolvers sends a recursive query through the resolver pool
+func (c *AuthoritativeChecker) queryResolvers(ctx context.Context, name string, qtype dnsmessage.Type) (*dnsmessage.Message, error) {
+	response, _, err := c.pool.Query(ctx, name, qtype)
+	return response, err
+}
+
+// resolveHosts looks up the IPv4 addresses of nameserver host names
+func (c *AuthoritativeChecker) resolveHosts(ctx context.Context, hosts []string) ([]string, error) {
+	var addresses []string
+	var lastErr error
+	for _, host := range hosts {
+		response, err := c.queryResolvers(ctx, host, dnsmessage.TypeA)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		for _, rr := range response.Answers {
+			if a, ok := rr.Body.(*dnsmessage.AResource); ok {
+				addresses = append(addresses, net.JoinHostPort(net.IP(a.A[:]).String(), c.port))
+			}
+		}
+	}
+
+	if len(addresses) == 0 {
+		if lastErr == nil {
+			lastErr = fmt.Errorf("no addresses found for nameservers %s", strings.Join(hosts, ", "))
+		}
+		return nil, lastErr
+	}
+	return addresses, nil
+}
+
+// glueAddresses extracts IPv4 glue records for the given hosts from a response
+func glueAddresses(response *dnsmessage.Message, hosts []string, port string) []string {
+	wanted := make(map[string]bool, len(hosts))
+	for _, host := range hosts {
+		wanted[host] = true
+	}
+
+	var addresses []string
+	for _, rr := range response.Additionals {
+		a, ok := rr.Body.(*dnsmessage.AResource)
+		if !ok || !wanted[normalizeZone(rr.Header.Name.String())] {
+			continue
+		}
+		addresses = append(addresses, net.JoinHostPort(net.IP(a.A[:]).String(), port))
+	}
+	return addresses
+}
+
+// normalizeZone lowercases a DNS name and strips the trailing dot
+func normalizeZone(name string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimPrefix(name, "."), "."))
+}
+
+// isSubdomain reports whether child is strictly below parent
+func isSubdomain(child, parent string) bool {
+	return child != parent && strings.HasSuffix(child, "."+parent)
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"domaincheck/internal/config"
+	"domaincheck/internal/models"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// newTestAuthoritativeChecker returns a checker that treats the stand-in as
+// the nameserver of the "test" zone and as its recursive resolver
+func newTestAuthoritativeChecker(standIn *dnsStandIn) *AuthoritativeChecker {
+	pool := NewResolverPool(config.ResolverConfig{
+		Upstreams: []config.UpstreamConfig{{Address: standIn.addr, Protocol: ProtocolUDP}},
+		Timeout:   time.Second,
+	})
+	checker := NewAuthoritativeChecker(NewDNSClient(time.Second), pool)
+	checker.port = standIn.port
+	checker.SetZoneServers("test", []string{standIn.addr})
+	return checker
+}
+
+func TestAuthoritativeCheckerDelegationStates(t *testing.T) {
+	tests := []struct {
+		name       string
+		response   dnsmessage.Message
+		delegation string
+		status     models.AvailabilityStatus
+		ttl        time.Duration
+	}{
+		{
+			name: "delegated",
+			response: dnsmessage.Message{
+				Authorities: []dnsmessage.Resource{
+					nsRecord("example.test", "ns1.example.net", 172800),
+					nsRecord("example.test", "ns2.example.net", 172800),
+				},
+			},
+			delegation: DelegationDelegated,
+			status:     models.StatusRegistered,
+			ttl:        172800 * time.Second,
+		},
+		{
+			name: "nxdomain",
+			response: dnsmessage.Message{
+				Header:      dnsmessage.Header{RCode: dnsmessage.RCodeNameError, Authoritative: true},
+				Authorities: []dnsmessage.Resource{soaRecord("test", 900, 300)},
+			},
+			delegation: DelegationNXDomain,
+			status:     models.StatusAvailable,
+			ttl:        300 * time.Second,
+		},
+		{
+			name: "not delegated",
+			response: dnsmessage.Message{
+				Header:      dnsmessage.Header{Authoritative: true},
+				Authorities: []dnsmessage.Resource{soaRecord("test", 900, 600)},
+			},
+			delegation: DelegationNotDelegated,
+			status:     models.StatusServerHold,
+			ttl:        600 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			standIn := newDNSStandIn(t, func(q dnsmessage.Question, network string) dnsmessage.Message {
+				return tt.response
+			})
+			checker := newTestAuthoritativeChecker(standIn)
+
+			result, err := checker.Check(context.Background(), "example.test")
+			if err != nil {
+				t.Fatalf("Check: %v", err)
+			}
+			if result.Delegation != tt.delegation {
+				t.Errorf("delegation = %q, want %q", result.Delegation, tt.delegation)
+			}
+			if result.Availability != tt.status {
+				t.Errorf("availability = %q, want %q", result.Availability, tt.status)
+			}
+			if result.TTL != tt.ttl {
+				t.Errorf("ttl = %s, want %s", result.TTL, tt.ttl)
+			}
+
+			queries := standIn.received()
+			if len(queries) != 1 || queries[0].question.Type != dnsmessage.TypeNS || queries[0].question.Name.String() != "example.test." {
+				t.Errorf("queries = %+v, want one NS query for example.test", queries)
+			}
+		})
+	}
+}
+
+func TestAuthoritativeCheckerServerFailure(t *testing.T) {
+	standIn := newDNSStandIn(t, func(q dnsmessage.Question, network string) dnsmessage.Message {
+		return dnsmessage.Message{Header: dnsmessage.Header{RCode: dnsmessage.RCodeServerFailure}}
+	})
+	checker := newTestAuthoritativeChecker(standIn)
+
+	_, err := checker.Check(context.Background(), "example.test")
+	var rcodeErr *RCodeError
+	if !errors.As(err, &rcodeErr) || !rcodeErr.Authoritative || rcodeErr.RCode != dnsmessage.RCodeServerFailure {
+		t.Errorf("err = %v, want an authoritative SERVFAIL error", err)
+	}
+}
+
+func TestAuthoritativeCheckerFollowsReferrals(t *testing.T) {
+	tests := []struct {
+		name string
+		glue bool
+	}{
+		{"with glue", true},
+		{"without glue", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The stand-in plays the "test" zone, then the "co.test" zone it
+			// refers to, and the recursive resolver used to find nameservers
+			var domainQueries atomic.Int32
+			standIn := newDNSStandIn(t, func(q dnsmessage.Question, network string) dnsmessage.Message {
+				switch q.Name.String() {
+				case "example.co.test.":
+					if domainQueries.Add(1) == 1 {
+						referral := dnsmessage.Message{
+							Authorities: []dnsmessage.Resource{nsRecord("co.test", "ns1.co.test", 3600)},
+						}
+						if tt.glue {
+							referral.Additionals = []dnsmessage.Resource{aRecord("ns1.co.test", loopback)}
+						}
+						return referral
+					}
+					return dnsmessage.Message{
+						Authorities: []dnsmessage.Resource{nsRecord("example.co.test", "ns1.example.net", 3600)},
+					}
+				case "ns1.co.test.":
+					return dnsmessage.Message{Answers: []dnsmessage.Resource{aRecord("ns1.co.test", loopback)}}
+				default:
+					// co.test is not known to the resolver as a zone cut
+					return dnsmessage.Message{}
+				}
+			})
+			checker := newTestAuthoritativeChecker(standIn)
+
+			delegation, err := checker.Delegation(context.Background(), "example.co.test")
+			if err != nil {
+				t.Fatalf("Delegation: %v", err)
+			}
+			if delegation != DelegationDelegated {
+				t.Errorf("delegation = %q, want %q", delegation, DelegationDelegated)
+			}
+			if got := domainQueries.Load(); got != 2 {
+				t.Errorf("example.co.test was queried %d times, want 2", got)
+			}
+
+			resolvedHost := false
+			for _, query := range standIn.received() {
+				if query.question.Name.String() == "ns1.co.test." && query.question.Type == dnsmessage.TypeA {
+					resolvedHost = true
+				}
+			}
+			if resolvedHost == tt.glue {
+				t.Errorf("nameserver address lookup = %v, want %v", resolvedHost, !tt.glue)
+			}
+		})
+	}
+}
+
+func TestAuthoritativeCheckerTruncatedAnswerUsesTCP(t *testing.T) {
+	standIn := newDNSStandIn(t, func(q dnsmessage.Question, network string) dnsmessage.Message {
+		if network == "udp" {
+			return dnsmessage.Message{Header: dnsmessage.Header{Truncated: true}}
+		}
+		return dnsmessage.Message{
+			Authorities: []dnsmessage.Resource{nsRecord("example.test", "ns1.example.net", 3600)},
+		}
+	})
+	checker := newTestAuthoritativeChecker(standIn)
+
+	delegation, err := checker.Delegation(context.Background(), "example.test")
+	if err != nil {
+		t.Fatalf("Delegation: %v", err)
+	}
+	if delegation != DelegationDelegated {
+		t.Errorf("delegation = %q, want %q", delegation, DelegationDelegated)
+	}
+
+	queries := standIn.received()
+	if len(queries) != 2 || queries[1].network != "tcp" {
+		t.Errorf("queries = %+v, want the truncated UDP answer retried over TCP", queries)
+	}
+}
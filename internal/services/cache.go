@@ -0,0 +1,202 @@
+package services
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"domaincheck/internal/config"
+	"domaincheck/internal/models"
+)
+
+const (
+	// defaultCachePositiveTTL is used when no positive TTL is configured
+	defaultCachePositiveTTL = 10 * time.Minute
+	// defaultCacheNegativeTTL is used when no negative TTL is configured
+	defaultCacheNegativeTTL = time.Minute
+	// defaultCacheMaxEntries is used when no cache size is configured
+	defaultCacheMaxEntries = 10000
+)
+
+// cacheBypassKey marks a context whose checks skip the result cache
+type cacheBypassKey struct{}
+
+// WithoutCache returns a context whose domain checks skip cached results.
+// Their fresh results still replace the cached ones.
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cacheBypassKey{}, true)
+}
+
+// cacheBypassed reports whether a context asks to skip cached results
+func cacheBypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(cacheBypassKey{}).(bool)
+	return bypass
+}
+
+// resultCache keeps recent check results so that repeated checks of the
+// same domain with the same strategy skip the network
+type resultCache struct {
+	enabled     bool
+	positiveTTL time.Duration
+	negativeTTL time.Duration
+	maxEntries  int
+	entries     map[string]*list.Element
+	order       *list.List // Most recently used first
+	hits        uint64
+	misses      uint64
+	bypasses    uint64
+	evictions   uint64
+	mutex       sync.Mutex
+}
+
+// cacheEntry is a cached result and when it expires
+type cacheEntry struct {
+	key     string
+	domain  models.Domain
+	expires time.Time
+}
+
+// newResultCache creates a result cache; a disabled cache stores nothing
+func newResultCache(cfg config.CacheConfig) *resultCache {
+	cache := &resultCache{
+		enabled:     cfg.Enabled,
+		positiveTTL: cfg.PositiveTTL,
+		negativeTTL: cfg.NegativeTTL,
+		maxEntries:  cfg.MaxEntries,
+		entries:     make(map[string]*list.Element),
+		order:       list.New(),
+	}
+	if cache.positiveTTL <= 0 {
+		cache.positiveTTL = defaultCachePositiveTTL
+	}
+	if cache.negativeTTL <= 0 {
+		cache.negativeTTL = defaultCacheNegativeTTL
+	}
+	if cache.maxEntries <= 0 {
+		cache.maxEntries = defaultCacheMaxEntries
+	}
+	return cache
+}
+
+// resultCacheKey identifies the result of checking a domain with a strategy chain
+func resultCacheKey(domain string, strategy []string) string {
+	return domain + "|" + strings.Join(strategy, ",")
+}
+
+// get returns a copy of a cached result marked as cached, if one is still fresh
+func (c *resultCache) get(key string) (*models.Domain, bool) {
+	if !c.enabled {
+		return nil, false
+	}
+
+	now := time.Now()
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, ok := c.entries[key]
+	if ok && now.After(element.Value.(*cacheEntry).expires) {
+		c.remove(element)
+		ok = false
+	}
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+
+	c.hits++
+	c.order.MoveToFront(element)
+
+	domain := element.Value.(*cacheEntry).domain
+	domain.Cached = true
+	domain.CacheAge = int64(now.Sub(domain.CheckedAt) / time.Second)
+	return &domain, true
+}
+
+// bypass counts a check that skipped the cache on request
+func (c *resultCache) bypass() {
+	if !c.enabled {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.bypasses++
+}
+
+// put caches a result. Results without a verdict are not cached, and a DNS
+// TTL shorter than the configured one takes precedence.
+func (c *resultCache) put(key string, domain *models.Domain, dnsTTL time.Duration) {
+	if !c.enabled || !domain.Availability.IsConclusive() {
+		return
+	}
+
+	ttl := c.positiveTTL
+	if domain.Availability.IsAvailable() {
+		ttl = c.negativeTTL
+	}
+	if dnsTTL > 0 && dnsTTL < ttl {
+		ttl = dnsTTL
+	}
+
+	entry := &cacheEntry{
+		key:     key,
+		domain:  *domain,
+		expires: domain.CheckedAt.Add(ttl),
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(entry)
+	for len(c.entries) > c.maxEntries {
+		c.remove(c.order.Back())
+		c.evictions++
+	}
+}
+
+// clear removes every cached result
+func (c *resultCache) clear() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.entries = make(map[string]*list.Element)
+	c.order.Init()
+}
+
+// stats returns the cache's size and hit counts
+func (c *resultCache) stats() models.CacheStats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	stats := models.CacheStats{
+		Enabled:     c.enabled,
+		Entries:     len(c.entries),
+		MaxEntries:  c.maxEntries,
+		Hits:        c.hits,
+		Misses:      c.misses,
+		Bypasses:    c.bypasses,
+		Evictions:   c.evictions,
+		PositiveTTL: int64(c.positiveTTL / time.Second),
+		NegativeTTL: int64(c.negativeTTL / time.Second),
+	}
+	if lookups := c.hits + c.misses; lookups > 0 {
+		stats.HitRate = float64(c.hits) / float64(lookups)
+	}
+	return stats
+}
+
+// remove drops an entry; the caller holds the lock
+func (c *resultCache) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*cacheEntry).key)
+}
@@ -0,0 +1,306 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"domaincheck/internal/config"
+	"domaincheck/internal/models"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// ErrCheckerNotApplicable is returned by a checker that cannot produce a verdict
+// for the given domain (e.g. no RDAP server known for its TLD)
+var ErrCheckerNotApplicable = errors.New("checker not applicable for domain")
+
+// Checker determines the availability of a single fully-qualified domain
+type Checker interface {
+	// Name returns the identifier used to reference the checker in the strategy chain
+	Name() string
+	// Check returns a verdict for the domain, or an error if no verdict could be reached
+	Check(ctx context.Context, domain string) (*CheckResult, error)
+}
+
+// CheckResult represents the verdict produced by a checker
+type CheckResult struct {
+	Availability models.AvailabilityStatus
+	Confidence   models.Confidence
+	Evidence     []string // Observations backing the verdict
+	DNSResolved  bool
+	IP           string
+	Delegation   string
+	Error        string
+	TTL          time.Duration // How long DNS allows the verdict to be cached; zero if unknown
+}
+
+// DNSChecker checks availability by resolving the domain's A/AAAA records
+type DNSChecker struct {
+	pool *ResolverPool
+}
+
+// NewDNSChecker creates a new DNS checker using the given resolver pool
+func NewDNSChecker(pool *ResolverPool) *DNSChecker {
+	return &DNSChecker{pool: pool}
+}
+
+// Name returns the checker name
+func (c *DNSChecker) Name() string {
+	return "dns"
+}
+
+// Check resolves the domain. Only NXDOMAIN counts as available; a name that
+// exists without A or AAAA records is registered with low confidence.
+func (c *DNSChecker) Check(ctx context.Context, domain string) (*CheckResult, error) {
+	var ttl time.Duration
+	for _, qtype := range []dnsmessage.Type{dnsmessage.TypeA, dnsmessage.TypeAAAA} {
+		response, resolver, err := c.pool.Query(ctx, domain, qtype)
+		if err != nil {
+			return nil, err
+		}
+		ttl = negativeTTL(response)
+
+		if response.RCode == dnsmessage.RCodeNameError {
+			return &CheckResult{
+				Availability: models.StatusAvailable,
+				Confidence:   models.ConfidenceMedium,
+				Evidence:     []string{fmt.Sprintf("resolver %s answered NXDOMAIN", resolver)},
+				Error:        fmt.Sprintf("lookup %s: no such host", domain),
+				TTL:          ttl,
+			}, nil
+		}
+
+		if ip := firstAddress(response); ip != "" {
+			return &CheckResult{
+				Availability: models.StatusRegistered,
+				Confidence:   models.ConfidenceHigh,
+				Evidence:     []string{fmt.Sprintf("resolved to %s via %s", ip, resolver)},
+				DNSResolved:  true,
+				IP:           ip,
+				TTL:          answerTTL(response),
+			}, nil
+		}
+	}
+
+	// NOERROR without addresses (NODATA): the name exists, it just has no web records
+	return &CheckResult{
+		Availability: models.StatusRegistered,
+		Confidence:   models.ConfidenceLow,
+		Evidence:     []string{"name exists but has no A or AAAA records"},
+		TTL:          ttl,
+	}, nil
+}
+
+// firstAddress returns the first A or AAAA record of a response
+func firstAddress(response *dnsmessage.Message) string {
+	for _, rr := range response.Answers {
+		switch body := rr.Body.(type) {
+		case *dnsmessage.AResource:
+			return net.IP(body.A[:]).String()
+		case *dnsmessage.AAAAResource:
+			return net.IP(body.AAAA[:]).String()
+		}
+	}
+	return ""
+}
+
+// answerTTL returns the lowest TTL of a response's answers, or zero if it has none
+func answerTTL(response *dnsmessage.Message) time.Duration {
+	var ttl time.Duration
+	for _, rr := range response.Answers {
+		if recordTTL := time.Duration(rr.Header.TTL) * time.Second; ttl == 0 || recordTTL < ttl {
+			ttl = recordTTL
+		}
+	}
+	return ttl
+}
+
+// negativeTTL returns how long a negative answer may be cached: the lower of
+// the SOA record's TTL and its minimum field (RFC 2308), or zero without an SOA record
+func negativeTTL(response *dnsmessage.Message) time.Duration {
+	for _, rr := range response.Authorities {
+		if soa, ok := rr.Body.(*dnsmessage.SOAResource); ok {
+			ttl := rr.Header.TTL
+			if soa.MinTTL < ttl {
+				ttl = soa.MinTTL
+			}
+			return time.Duration(ttl) * time.Second
+		}
+	}
+	return 0
+}
+
+// availabilityFromEPPStatus maps registry status codes to a typed status.
+// It accepts both EPP (RFC 5731, "pendingDelete") and RDAP (RFC 8056, "pending delete") spellings.
+func availabilityFromEPPStatus(statuses []string) models.AvailabilityStatus {
+	normalized := make(map[string]bool, len(statuses))
+	for _, status := range statuses {
+		normalized[strings.ToLower(strings.ReplaceAll(status, " ", ""))] = true
+	}
+
+	switch {
+	case normalized["redemptionperiod"]:
+		return models.StatusRedemption
+	case normalized["pendingdelete"]:
+		return models.StatusPendingDelete
+	case normalized["serverhold"]:
+		return models.StatusServerHold
+	default:
+		return models.StatusRegistered
+	}
+}
+
+// availabilityFromError classifies a failure to reach a verdict
+func availabilityFromError(err error) models.AvailabilityStatus {
+	if errors.Is(err, ErrRateLimited) {
+		return models.StatusRateLimited
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return models.StatusTimeout
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return models.StatusTimeout
+	}
+	return models.StatusUnknown
+}
+
+// RegisterChecker makes a checker available to the strategy chain under its name
+func (s *DomainService) RegisterChecker(checker Checker) {
+	s.checkersMutex.Lock()
+	defer s.checkersMutex.Unlock()
+
+	s.checkers[checker.Name()] = checker
+}
+
+// strategyNames returns the names of the checkers configured for an extension.
+// Multi-label extensions such as ".co.uk" fall back to the strategy of their TLD.
+func strategyNames(domainCfg *config.DomainConfig, extension string) []string {
+	extension = strings.ToLower(extension)
+	names := domainCfg.Strategy
+	if tldNames, ok := domainCfg.TLDStrategies[extension]; ok && len(tldNames) > 0 {
+		names = tldNames
+	} else if tldNames, ok := domainCfg.TLDStrategies[topLevelDomain(extension)]; ok && len(tldNames) > 0 {
+		names = tldNames
+	}
+	if len(names) == 0 {
+		names = []string{"dns"}
+	}
+	return names
+}
+
+// topLevelDomain returns the last label of an extension with a leading dot, e.g. ".uk" for ".co.uk"
+func topLevelDomain(extension string) string {
+	if lastDot := strings.LastIndex(extension, "."); lastDot > 0 {
+		return extension[lastDot:]
+	}
+	return extension
+}
+
+// checkerChain returns the ordered checkers configured for an extension
+func (s *DomainService) checkerChain(domainCfg *config.DomainConfig, extension string) ([]Checker, error) {
+	names := strategyNames(domainCfg, extension)
+
+	s.checkersMutex.RLock()
+	defer s.checkersMutex.RUnlock()
+
+	chain := make([]Checker, 0, len(names))
+	for _, name := range names {
+		checker, ok := s.checkers[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown checker in strategy: %s", name)
+		}
+		chain = append(chain, checker)
+	}
+	return chain, nil
+}
+
+// runCheckers runs the strategy chain until a checker produces a verdict.
+// It returns the verdict and the name of the checker that produced it, or
+// the last error encountered and the checker that raised it. The evidence
+// covers every checker that was tried, including those that failed, and the
+// attempts list every try including the retries of transient failures.
+func (s *DomainService) runCheckers(ctx context.Context, domainCfg *config.DomainConfig, domain, extension string) (*CheckResult, string, []models.Evidence, []models.CheckAttempt, error) {
+	chain, err := s.checkerChain(domainCfg, extension)
+	if err != nil {
+		return nil, "", nil, nil, err
+	}
+
+	// Registries throttle per TLD, e.g. ".co.uk" and ".org.uk" share the limit of ".uk"
+	if err := s.rateLimiter.wait(ctx, models.RateLimitScopeTLD, topLevelDomain(extension)); err != nil {
+		return nil, "", nil, nil, err
+	}
+
+	var evidence []models.Evidence
+	var attempts []models.CheckAttempt
+	var lastErr error
+	var lastChecker string
+	for _, checker := range chain {
+		result, err := s.runChecker(ctx, checker, domain, &attempts)
+		if err == nil {
+			for _, detail := range result.Evidence {
+				evidence = append(evidence, models.Evidence{Source: checker.Name(), Detail: detail})
+			}
+			return result, checker.Name(), evidence, attempts, nil
+		}
+		if !errors.Is(err, ErrCheckerNotApplicable) {
+			lastErr = err
+			lastChecker = checker.Name()
+			evidence = append(evidence, models.Evidence{Source: checker.Name(), Detail: err.Error()})
+		}
+
+		// If context is cancelled or timed out, don't try other checkers
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no checker could handle %s", domain)
+	}
+	return nil, lastChecker, evidence, attempts, lastErr
+}
+
+// runChecker tries a checker until it produces a verdict or fails in a way the
+// retry policy does not repeat, and appends every attempt to the log
+func (s *DomainService) runChecker(ctx context.Context, checker Checker, domain string, attempts *[]models.CheckAttempt) (*CheckResult, error) {
+	for attempt := 1; ; attempt++ {
+		start := time.Now()
+		result, err := checker.Check(ctx, domain)
+		if errors.Is(err, ErrCheckerNotApplicable) {
+			return nil, err
+		}
+
+		// Checks abandoned by the caller or held back by rate limits say nothing about the checker's health
+		if !errors.Is(err, ErrRateLimited) && !errors.Is(ctx.Err(), context.Canceled) {
+			s.checkerStats.record(checker.Name(), err != nil)
+		}
+
+		entry := models.CheckAttempt{
+			Checker:    checker.Name(),
+			Attempt:    attempt,
+			DurationMs: time.Since(start).Milliseconds(),
+		}
+		if err == nil {
+			*attempts = append(*attempts, entry)
+			return result, nil
+		}
+
+		entry.Error = err.Error()
+		entry.ErrorClass = classifyError(err)
+		wait, retry := s.retryPolicy.next(ctx, entry.ErrorClass, attempt)
+		if retry {
+			entry.Retried = true
+			entry.BackoffMs = wait.Milliseconds()
+		}
+		*attempts = append(*attempts, entry)
+
+		if !retry || !s.retryPolicy.sleep(ctx, wait) {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// checkerStatsBucket is the time span counted together in checker statistics
+const checkerStatsBucket = time.Minute
+
+// checkerStatsMaxAge is how far back checker statistics are kept
+const checkerStatsMaxAge = time.Hour
+
+// checkerCounts counts the checks a checker made in one bucket
+type checkerCounts struct {
+	start    time.Time
+	checks   int
+	failures int
+}
+
+// checkerStats counts recent successes and failures of every checker in
+// one-minute buckets, so that failing checkers can be detected
+type checkerStats struct {
+	buckets map[string][]checkerCounts // oldest first
+	mutex   sync.Mutex
+}
+
+// checkerRate is the failure rate of a checker over a window
+type checkerRate struct {
+	Checker  string
+	Checks   int
+	Failures int
+}
+
+// ErrorRate returns the share of failed checks
+func (r checkerRate) ErrorRate() float64 {
+	if r.Checks == 0 {
+		return 0
+	}
+	return float64(r.Failures) / float64(r.Checks)
+}
+
+// newCheckerStats creates empty checker statistics
+func newCheckerStats() *checkerStats {
+	return &checkerStats{buckets: make(map[string][]checkerCounts)}
+}
+
+// record counts one check made by a checker
+func (s *checkerStats) record(checker string, failed bool) {
+	now := time.Now()
+	start := now.Truncate(checkerStatsBucket)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	buckets := s.buckets[checker]
+	if n := len(buckets); n == 0 || buckets[n-1].start.Before(start) {
+		// Drop buckets that are too old to matter
+		cutoff := now.Add(-checkerStatsMaxAge)
+		i := 0
+		for i < len(buckets) && buckets[i].start.Before(cutoff) {
+			i++
+		}
+		buckets = append(buckets[i:], checkerCounts{start: start})
+	}
+
+	last := &buckets[len(buckets)-1]
+	last.checks++
+	if failed {
+		last.failures++
+	}
+	s.buckets[checker] = buckets
+}
+
+// rates returns the check counts of every checker within the window, by checker name
+func (s *checkerStats) rates(window time.Duration) []checkerRate {
+	cutoff := time.Now().Add(-window)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	result := make([]checkerRate, 0, len(s.buckets))
+	for checker, buckets := range s.buckets {
+		rate := checkerRate{Checker: checker}
+		for _, bucket := range buckets {
+			// A bucket counts if any part of it lies within the window
+			if bucket.start.Add(checkerStatsBucket).After(cutoff) {
+				rate.Checks += bucket.checks
+				rate.Failures += bucket.failures
+			}
+		}
+		result = append(result, rate)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Checker < result[j].Checker
+	})
+	return result
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"domaincheck/internal/models"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func TestDNSCheckerAnswers(t *testing.T) {
+	tests := []struct {
+		name       string
+		response   dnsmessage.Message
+		status     models.AvailabilityStatus
+		confidence models.Confidence
+		resolved   bool
+	}{
+		{
+			name: "nxdomain",
+			response: dnsmessage.Message{
+				Header:      dnsmessage.Header{RCode: dnsmessage.RCodeNameError},
+				Authorities: []dnsmessage.Resource{soaRecord("test", 900, 300)},
+			},
+			status:     models.StatusAvailable,
+			confidence: models.ConfidenceMedium,
+		},
+		{
+			name: "nodata",
+			response: dnsmessage.Message{
+				Authorities: []dnsmessage.Resource{soaRecord("test", 900, 300)},
+			},
+			status:     models.StatusRegistered,
+			confidence: models.ConfidenceLow,
+		},
+		{
+			name: "address",
+			response: dnsmessage.Message{
+				Answers: []dnsmessage.Resource{aRecord("example.test", [4]byte{192, 0, 2, 1})},
+			},
+			status:     models.StatusRegistered,
+			confidence: models.ConfidenceHigh,
+			resolved:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			standIn := newDNSStandIn(t, func(q dnsmessage.Question, network string) dnsmessage.Message {
+				return tt.response
+			})
+			checker := NewDNSChecker(newTestResolverPool(standIn.addr, time.Second))
+
+			result, err := checker.Check(context.Background(), "example.test")
+			if err != nil {
+				t.Fatalf("Check: %v", err)
+			}
+			if result.Availability != tt.status || result.Confidence != tt.confidence {
+				t.Errorf("got %s with %s confidence, want %s with %s confidence",
+					result.Availability, result.Confidence, tt.status, tt.confidence)
+			}
+			if result.DNSResolved != tt.resolved {
+				t.Errorf("DNSResolved = %v, want %v", result.DNSResolved, tt.resolved)
+			}
+			if tt.status != models.StatusAvailable && result.Error != "" {
+				t.Errorf("Error = %q, want none for an existing name", result.Error)
+			}
+		})
+	}
+}
@@ -0,0 +1,258 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"domaincheck/internal/models"
+	"domaincheck/internal/utils"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// typeCAA is the CAA record type (RFC 8659), which dnsmessage does not define
+const typeCAA = dnsmessage.Type(257)
+
+// dnsRecordTypes maps the record types available for inspection to their wire types
+var dnsRecordTypes = map[string]dnsmessage.Type{
+	"A":     dnsmessage.TypeA,
+	"AAAA":  dnsmessage.TypeAAAA,
+	"CNAME": dnsmessage.TypeCNAME,
+	"MX":    dnsmessage.TypeMX,
+	"NS":    dnsmessage.TypeNS,
+	"TXT":   dnsmessage.TypeTXT,
+	"SOA":   dnsmessage.TypeSOA,
+	"CAA":   typeCAA,
+	"SRV":   dnsmessage.TypeSRV,
+}
+
+// defaultDNSRecordTypes is the inspection order used when no types are requested
+var defaultDNSRecordTypes = []string{"A", "AAAA", "CNAME", "MX", "NS", "TXT", "SOA", "CAA", "SRV"}
+
+// commonSRVServices are the service labels probed for SRV records, since SRV
+// records live below the domain rather than at its apex
+var commonSRVServices = []string{
+	"_sip._tcp", "_sip._udp", "_sips._tcp",
+	"_xmpp-client._tcp", "_xmpp-server._tcp",
+	"_autodiscover._tcp", "_imaps._tcp", "_submission._tcp",
+	"_caldavs._tcp", "_carddavs._tcp",
+}
+
+// ParseDNSRecordTypes normalizes and validates requested record types
+func ParseDNSRecordTypes(types []string) ([]string, error) {
+	if len(types) == 0 {
+		return defaultDNSRecordTypes, nil
+	}
+
+	seen := make(map[string]bool)
+	var result []string
+	for _, t := range types {
+		for _, part := range strings.Split(t, ",") {
+			name := strings.ToUpper(strings.TrimSpace(part))
+			if name == "" || seen[name] {
+				continue
+			}
+			if _, ok := dnsRecordTypes[name]; !ok {
+				return nil, fmt.Errorf("unsupported DNS record type: %s", part)
+			}
+			seen[name] = true
+			result = append(result, name)
+		}
+	}
+
+	if len(result) == 0 {
+		return defaultDNSRecordTypes, nil
+	}
+	return result, nil
+}
+
+// InspectDNS returns the DNS records of the requested types for a domain
+func (s *DomainService) InspectDNS(ctx context.Context, domainName string, types []string) (*models.DNSInspection, error) {
+	startTime := time.Now()
+
+	domainName, err := utils.NormalizeDomain(domainName)
+	if err != nil {
+		return nil, err
+	}
+
+	recordTypes, err := ParseDNSRecordTypes(types)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create context with timeout
+	timeoutCtx, cancel := context.WithTimeout(ctx, s.cfg.Domain.Timeout)
+	defer cancel()
+
+	inspection := &models.DNSInspection{
+		Domain:     domainName,
+		RecordSets: make([]models.DNSRecordSet, len(recordTypes)),
+		CheckedAt:  time.Now(),
+	}
+
+	// Query every record type concurrently, keeping the requested order
+	var wg sync.WaitGroup
+	for i, recordType := range recordTypes {
+		wg.Add(1)
+		go func(i int, recordType string) {
+			defer wg.Done()
+			if recordType == "SRV" {
+				inspection.RecordSets[i] = s.lookupSRVRecords(timeoutCtx, domainName)
+				return
+			}
+			inspection.RecordSets[i] = s.lookupRecordSet(timeoutCtx, domainName, recordType)
+		}(i, recordType)
+	}
+	wg.Wait()
+
+	inspection.ResponseTime = time.Since(startTime).Milliseconds()
+	return inspection, nil
+}
+
+// InspectDNSMultiple inspects the DNS records of several domains concurrently
+func (s *DomainService) InspectDNSMultiple(ctx context.Context, domains []string, types []string) ([]*models.DNSInspection, error) {
+	if _, err := ParseDNSRecordTypes(types); err != nil {
+		return nil, err
+	}
+
+	results := make([]*models.DNSInspection, len(domains))
+	errs := make([]error, len(domains))
+
+	semaphore := make(chan struct{}, s.cfg.Domain.MaxConcurrentChecks)
+	var wg sync.WaitGroup
+	for i, domain := range domains {
+		wg.Add(1)
+		go func(i int, domain string) {
+			defer wg.Done()
+			semaphore <- struct{}{}        // Acquire semaphore
+			defer func() { <-semaphore }() // Release semaphore
+
+			results[i], errs[i] = s.InspectDNS(ctx, domain, types)
+		}(i, domain)
+	}
+	wg.Wait()
+
+	// Return first error if any
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("failed to inspect %s: %w", domains[i], err)
+		}
+	}
+	return results, nil
+}
+
+// lookupRecordSet queries the resolver pool for one record type
+func (s *DomainService) lookupRecordSet(ctx context.Context, name, recordType string) models.DNSRecordSet {
+	set := models.DNSRecordSet{Type: recordType, Records: []models.DNSRecord{}}
+
+	response, resolver, err := s.resolverPool.Query(ctx, name, dnsRecordTypes[recordType])
+	if err != nil {
+		set.Error = err.Error()
+		return set
+	}
+	set.Resolver = resolver
+
+	if response.RCode == dnsmessage.RCodeNameError {
+		set.Error = "NXDOMAIN"
+		return set
+	}
+
+	for _, rr := range response.Answers {
+		// Skip CNAME chain entries when other types were requested
+		if rr.Header.Type != dnsRecordTypes[recordType] {
+			continue
+		}
+		set.Records = append(set.Records, models.DNSRecord{
+			Name:  rr.Header.Name.String(),
+			Type:  recordType,
+			TTL:   rr.Header.TTL,
+			Value: formatDNSRecord(rr.Body),
+		})
+	}
+	return set
+}
+
+// lookupSRVRecords probes the common service labels below a domain
+func (s *DomainService) lookupSRVRecords(ctx context.Context, domain string) models.DNSRecordSet {
+	sets := make([]models.DNSRecordSet, len(commonSRVServices))
+
+	var wg sync.WaitGroup
+	for i, service := range commonSRVServices {
+		wg.Add(1)
+		go func(i int, service string) {
+			defer wg.Done()
+			sets[i] = s.lookupRecordSet(ctx, service+"."+domain, "SRV")
+		}(i, service)
+	}
+	wg.Wait()
+
+	// Missing services are expected, so only report failures if nothing answered
+	result := models.DNSRecordSet{Type: "SRV", Records: []models.DNSRecord{}}
+	var lastErr string
+	for _, set := range sets {
+		if set.Resolver != "" && result.Resolver == "" {
+			result.Resolver = set.Resolver
+		}
+		if set.Error != "" && set.Error != "NXDOMAIN" {
+			lastErr = set.Error
+		}
+		result.Records = append(result.Records, set.Records...)
+	}
+	if result.Resolver == "" {
+		result.Error = lastErr
+	}
+
+	sort.SliceStable(result.Records, func(i, j int) bool {
+		return result.Records[i].Name < result.Records[j].Name
+	})
+	return result
+}
+
+// formatDNSRecord renders a record body in zone file presentation format
+func formatDNSRecord(body dnsmessage.ResourceBody) string {
+	switch r := body.(type) {
+	case *dnsmessage.AResource:
+		return net.IP(r.A[:]).String()
+	case *dnsmessage.AAAAResource:
+		return net.IP(r.AAAA[:]).String()
+	case *dnsmessage.CNAMEResource:
+		return r.CNAME.String()
+	case *dnsmessage.MXResource:
+		return fmt.Sprintf("%d %s", r.Pref, r.MX.String())
+	case *dnsmessage.NSResource:
+		return r.NS.String()
+	case *dnsmessage.TXTResource:
+		// Long TXT records are split into 255-byte strings on the wire
+		return strings.Join(r.TXT, "")
+	case *dnsmessage.SOAResource:
+		return fmt.Sprintf("%s %s %d %d %d %d %d", r.NS.String(), r.MBox.String(),
+			r.Serial, r.Refresh, r.Retry, r.Expire, r.MinTTL)
+	case *dnsmessage.SRVResource:
+		return fmt.Sprintf("%d %d %d %s", r.Priority, r.Weight, r.Port, r.Target.String())
+	case *dnsmessage.UnknownResource:
+		if r.Type == typeCAA {
+			return formatCAARecord(r.Data)
+		}
+		return fmt.Sprintf("\\# %d %x", len(r.Data), r.Data)
+	default:
+		return ""
+	}
+}
+
+// formatCAARecord renders CAA record data as `flags tag "value"`
+func formatCAARecord(data []byte) string {
+	if len(data) < 2 || len(data) < 2+int(data[1]) {
+		return fmt.Sprintf("\\# %d %x", len(data), data)
+	}
+
+	flags := data[0]
+	tagLength := int(data[1])
+	tag := string(data[2 : 2+tagLength])
+	value := string(data[2+tagLength:])
+	return fmt.Sprintf("%d %s %q", flags, tag, value)
+}
@@ -0,0 +1,324 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// ednsPayloadSize is the UDP payload size advertised via EDNS0
+const ednsPayloadSize = 4096
+
+// DNS transport protocols supported for upstream servers
+const (
+	ProtocolUDP = "udp"
+	ProtocolTCP = "tcp"
+	ProtocolDoT = "dot"
+	ProtocolDoH = "doh"
+)
+
+// Upstream describes a DNS server and the transport used to reach it
+type Upstream struct {
+	Address    string // host:port, or the DoH endpoint URL
+	Protocol   string // ProtocolUDP, ProtocolTCP, ProtocolDoT or ProtocolDoH
+	ServerName string // TLS server name for DoT, defaults to the host of Address
+}
+
+// RCodeError is returned when a DNS server answers with an error code that
+// does not say anything about the name, such as SERVFAIL or REFUSED
+type RCodeError struct {
+	Server        string
+	Authoritative bool // The server is authoritative rather than a recursive resolver
+	RCode         dnsmessage.RCode
+}
+
+// Error describes the answer
+func (e *RCodeError) Error() string {
+	if e.Authoritative {
+		return fmt.Sprintf("authoritative server %s answered %s", e.Server, e.RCode)
+	}
+	return fmt.Sprintf("resolver %s answered %s", e.Server, e.RCode)
+}
+
+// DNSClient sends raw DNS messages over UDP, retrying over TCP when the answer is truncated
+type DNSClient struct {
+	timeout    time.Duration
+	httpClient *http.Client
+}
+
+// NewDNSClient creates a new raw DNS message client
+func NewDNSClient(timeout time.Duration) *DNSClient {
+	return &DNSClient{
+		timeout:    timeout,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// Query asks a server for the records of the given type. Recursion is only
+// requested when recursive is true, as needed when talking to resolvers.
+func (c *DNSClient) Query(ctx context.Context, server, name string, qtype dnsmessage.Type, recursive bool) (*dnsmessage.Message, error) {
+	query, err := newDNSQuery(name, qtype, recursive)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Exchange(ctx, server, query)
+}
+
+// QueryUpstream is like Query but reaches the server over the upstream's transport
+func (c *DNSClient) QueryUpstream(ctx context.Context, upstream Upstream, name string, qtype dnsmessage.Type, recursive bool) (*dnsmessage.Message, error) {
+	query, err := newDNSQuery(name, qtype, recursive)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.ExchangeUpstream(ctx, upstream, query)
+}
+
+// newDNSQuery builds a query message with an EDNS0 OPT record
+func newDNSQuery(name string, qtype dnsmessage.Type, recursive bool) (dnsmessage.Message, error) {
+	if !strings.HasSuffix(name, ".") {
+		name += "."
+	}
+	qname, err := dnsmessage.NewName(name)
+	if err != nil {
+		return dnsmessage.Message{}, fmt.Errorf("invalid DNS name %s: %w", name, err)
+	}
+
+	var opt dnsmessage.ResourceHeader
+	if err := opt.SetEDNS0(ednsPayloadSize, dnsmessage.RCodeSuccess, false); err != nil {
+		return dnsmessage.Message{}, err
+	}
+
+	return dnsmessage.Message{
+		Header: dnsmessage.Header{
+			ID:               uint16(rand.Intn(1 << 16)),
+			RecursionDesired: recursive,
+		},
+		Questions: []dnsmessage.Question{{
+			Name:  qname,
+			Type:  qtype,
+			Class: dnsmessage.ClassINET,
+		}},
+		Additionals: []dnsmessage.Resource{{
+			Header: opt,
+			Body:   &dnsmessage.OPTResource{},
+		}},
+	}, nil
+}
+
+// Exchange sends a query message to a server and returns the matching response
+func (c *DNSClient) Exchange(ctx context.Context, server string, query dnsmessage.Message) (*dnsmessage.Message, error) {
+	packed, err := query.Pack()
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack DNS query: %w", err)
+	}
+
+	response, err := c.exchange(ctx, "udp", server, query, packed)
+	if err != nil {
+		return nil, err
+	}
+	if response.Truncated {
+		return c.exchange(ctx, "tcp", server, query, packed)
+	}
+	return response, nil
+}
+
+// ExchangeUpstream sends a query message over the upstream's transport
+func (c *DNSClient) ExchangeUpstream(ctx context.Context, upstream Upstream, query dnsmessage.Message) (*dnsmessage.Message, error) {
+	switch upstream.Protocol {
+	case ProtocolUDP, "":
+		return c.Exchange(ctx, upstream.Address, query)
+	case ProtocolTCP, ProtocolDoT:
+		packed, err := query.Pack()
+		if err != nil {
+			return nil, fmt.Errorf("failed to pack DNS query: %w", err)
+		}
+		if upstream.Protocol == ProtocolTCP {
+			return c.exchange(ctx, "tcp", upstream.Address, query, packed)
+		}
+		return c.exchangeTLS(ctx, upstream, query, packed)
+	case ProtocolDoH:
+		return c.exchangeHTTPS(ctx, upstream.Address, query)
+	default:
+		return nil, fmt.Errorf("unsupported DNS protocol: %s", upstream.Protocol)
+	}
+}
+
+// exchange performs a single round trip over the given network
+func (c *DNSClient) exchange(ctx context.Context, network, server string, query dnsmessage.Message, packed []byte) (*dnsmessage.Message, error) {
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "53")
+	}
+
+	dialer := net.Dialer{Timeout: c.timeout}
+	conn, err := dialer.DialContext(ctx, network, server)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to DNS server %s: %w", server, err)
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(c.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	conn.SetDeadline(deadline)
+
+	var raw []byte
+	if network == "tcp" {
+		raw, err = exchangeStream(conn, packed)
+	} else {
+		raw, err = exchangeDatagram(conn, packed)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("DNS query to %s failed: %w", server, err)
+	}
+
+	return unpackDNSResponse(raw, server, query)
+}
+
+// exchangeTLS performs a single round trip over DNS-over-TLS (RFC 7858)
+func (c *DNSClient) exchangeTLS(ctx context.Context, upstream Upstream, query dnsmessage.Message, packed []byte) (*dnsmessage.Message, error) {
+	server := upstream.Address
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "853")
+	}
+
+	serverName := upstream.ServerName
+	if serverName == "" {
+		serverName, _, _ = net.SplitHostPort(server)
+	}
+
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: c.timeout},
+		Config:    &tls.Config{ServerName: serverName, MinVersion: tls.VersionTLS12},
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", server)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to DNS server %s: %w", server, err)
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(c.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	conn.SetDeadline(deadline)
+
+	raw, err := exchangeStream(conn, packed)
+	if err != nil {
+		return nil, fmt.Errorf("DNS query to %s failed: %w", server, err)
+	}
+
+	return unpackDNSResponse(raw, server, query)
+}
+
+// exchangeHTTPS performs a single round trip over DNS-over-HTTPS (RFC 8484)
+func (c *DNSClient) exchangeHTTPS(ctx context.Context, endpoint string, query dnsmessage.Message) (*dnsmessage.Message, error) {
+	// RFC 8484 recommends ID 0 for cache friendliness
+	query.ID = 0
+	packed, err := query.Pack()
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack DNS query: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(packed))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create DoH request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/dns-message")
+	req.Header.Set("Accept", "application/dns-message")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("DNS query to %s failed: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("DoH server %s returned status %d", endpoint, resp.StatusCode)
+	}
+
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DoH response from %s: %w", endpoint, err)
+	}
+
+	return unpackDNSResponse(raw, endpoint, query)
+}
+
+// unpackDNSResponse parses a response and makes sure it answers our query:
+// the ID and the question have to match, so a spoofed or stray answer to
+// another query is never taken for ours
+func unpackDNSResponse(raw []byte, server string, query dnsmessage.Message) (*dnsmessage.Message, error) {
+	var response dnsmessage.Message
+	if err := response.Unpack(raw); err != nil {
+		return nil, fmt.Errorf("failed to parse DNS response from %s: %w", server, err)
+	}
+	if !response.Response || response.ID != query.ID {
+		return nil, fmt.Errorf("mismatched DNS response from %s", server)
+	}
+	if !sameDNSQuestions(response.Questions, query.Questions) {
+		return nil, fmt.Errorf("DNS response from %s answers a different question", server)
+	}
+
+	return &response, nil
+}
+
+// sameDNSQuestions compares question sections; names are compared without
+// regard to case, as servers may echo them in a different one
+func sameDNSQuestions(a, b []dnsmessage.Question) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Type != b[i].Type || a[i].Class != b[i].Class || !strings.EqualFold(a[i].Name.String(), b[i].Name.String()) {
+			return false
+		}
+	}
+	return true
+}
+
+// exchangeDatagram writes a query and reads one response datagram
+func exchangeDatagram(conn net.Conn, packed []byte) ([]byte, error) {
+	if _, err := conn.Write(packed); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, ednsPayloadSize)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+// exchangeStream writes a length-prefixed query and reads the length-prefixed response
+func exchangeStream(rw io.ReadWriter, packed []byte) ([]byte, error) {
+	msg := make([]byte, 2+len(packed))
+	binary.BigEndian.PutUint16(msg, uint16(len(packed)))
+	copy(msg[2:], packed)
+	if _, err := rw.Write(msg); err != nil {
+		return nil, err
+	}
+
+	var length [2]byte
+	if _, err := io.ReadFull(rw, length[:]); err != nil {
+		return nil, err
+	}
+	raw := make([]byte, binary.BigEndian.Uint16(length[:]))
+	if _, err := io.ReadFull(rw, raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
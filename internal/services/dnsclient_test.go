@@ -0,0 +1,257 @@
+package services
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// dnsQueryLog records a query received by the DNS stand-in
+type dnsQueryLog struct {
+	network  string
+	question dnsmessage.Question
+}
+
+// dnsHandler builds the answer to a query; the stand-in fills in the ID, the
+// response flag and, unless the handler sets them, the questions
+type dnsHandler func(q dnsmessage.Question, network string) dnsmessage.Message
+
+// dnsStandIn is an in-process DNS server listening on UDP and TCP on the same port
+type dnsStandIn struct {
+	addr    string
+	port    string
+	handler dnsHandler
+	udp     net.PacketConn
+	tcp     net.Listener
+	queries []dnsQueryLog
+	mutex   sync.Mutex
+}
+
+func newDNSStandIn(t *testing.T, handler dnsHandler) *dnsStandIn {
+	t.Helper()
+
+	s := &dnsStandIn{handler: handler}
+	for attempt := 0; s.udp == nil; attempt++ {
+		if attempt == 10 {
+			t.Fatalf("could not bind UDP and TCP to the same port")
+		}
+
+		udp, err := net.ListenPacket("udp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("listen udp: %v", err)
+		}
+		tcp, err := net.Listen("tcp", udp.LocalAddr().String())
+		if err != nil {
+			udp.Close()
+			continue
+		}
+		s.udp, s.tcp = udp, tcp
+	}
+	s.addr = s.udp.LocalAddr().String()
+	_, s.port, _ = net.SplitHostPort(s.addr)
+
+	go s.serveUDP()
+	go s.serveTCP()
+	t.Cleanup(func() {
+		s.udp.Close()
+		s.tcp.Close()
+	})
+	return s
+}
+
+// answer parses a query and packs the handler's response to it
+func (s *dnsStandIn) answer(raw []byte, network string) ([]byte, bool) {
+	var query dnsmessage.Message
+	if err := query.Unpack(raw); err != nil || len(query.Questions) != 1 {
+		return nil, false
+	}
+
+	s.mutex.Lock()
+	s.queries = append(s.queries, dnsQueryLog{network: network, question: query.Questions[0]})
+	s.mutex.Unlock()
+
+	response := s.handler(query.Questions[0], network)
+	response.ID = query.ID
+	response.Response = true
+	if response.Questions == nil {
+		response.Questions = query.Questions
+	}
+
+	packed, err := response.Pack()
+	if err != nil {
+		return nil, false
+	}
+	return packed, true
+}
+
+func (s *dnsStandIn) serveUDP() {
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := s.udp.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		if packed, ok := s.answer(buf[:n], "udp"); ok {
+			s.udp.WriteTo(packed, addr)
+		}
+	}
+}
+
+func (s *dnsStandIn) serveTCP() {
+	for {
+		conn, err := s.tcp.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+			for {
+				var length [2]byte
+				if _, err := io.ReadFull(conn, length[:]); err != nil {
+					return
+				}
+				raw := make([]byte, binary.BigEndian.Uint16(length[:]))
+				if _, err := io.ReadFull(conn, raw); err != nil {
+					return
+				}
+				packed, ok := s.answer(raw, "tcp")
+				if !ok {
+					return
+				}
+				msg := make([]byte, 2+len(packed))
+				binary.BigEndian.PutUint16(msg, uint16(len(packed)))
+				copy(msg[2:], packed)
+				if _, err := conn.Write(msg); err != nil {
+					return
+				}
+			}
+		}()
+	}
+}
+
+// received returns the queries received so far
+func (s *dnsStandIn) received() []dnsQueryLog {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]dnsQueryLog(nil), s.queries...)
+}
+
+// dnsName converts a name to its wire form, adding the trailing dot
+func dnsName(name string) dnsmessage.Name {
+	if !strings.HasSuffix(name, ".") {
+		name += "."
+	}
+	return dnsmessage.MustNewName(name)
+}
+
+func nsRecord(owner, host string, ttl uint32) dnsmessage.Resource {
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: dnsName(owner), Type: dnsmessage.TypeNS, Class: dnsmessage.ClassINET, TTL: ttl},
+		Body:   &dnsmessage.NSResource{NS: dnsName(host)},
+	}
+}
+
+func aRecord(name string, ip [4]byte) dnsmessage.Resource {
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: dnsName(name), Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET, TTL: 300},
+		Body:   &dnsmessage.AResource{A: ip},
+	}
+}
+
+func soaRecord(zone string, ttl, minTTL uint32) dnsmessage.Resource {
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: dnsName(zone), Type: dnsmessage.TypeSOA, Class: dnsmessage.ClassINET, TTL: ttl},
+		Body: &dnsmessage.SOAResource{
+			NS:      dnsName("ns1." + zone),
+			MBox:    dnsName("hostmaster." + zone),
+			Serial:  1,
+			Refresh: 3600,
+			Retry:   600,
+			Expire:  86400,
+			MinTTL:  minTTL,
+		},
+	}
+}
+
+var loopback = [4]byte{127, 0, 0, 1}
+
+func TestDNSClientFallsBackToTCPWhenTruncated(t *testing.T) {
+	standIn := newDNSStandIn(t, func(q dnsmessage.Question, network string) dnsmessage.Message {
+		if network == "udp" {
+			return dnsmessage.Message{Header: dnsmessage.Header{Truncated: true}}
+		}
+		return dnsmessage.Message{Answers: []dnsmessage.Resource{aRecord(q.Name.String(), loopback)}}
+	})
+	client := NewDNSClient(time.Second)
+
+	response, err := client.Query(context.Background(), standIn.addr, "example.test", dnsmessage.TypeA, false)
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if response.Truncated || len(response.Answers) != 1 {
+		t.Fatalf("got truncated=%v answers=%d, want the full TCP answer", response.Truncated, len(response.Answers))
+	}
+
+	queries := standIn.received()
+	if len(queries) != 2 || queries[0].network != "udp" || queries[1].network != "tcp" {
+		t.Errorf("queries = %+v, want UDP then TCP", queries)
+	}
+}
+
+func TestDNSClientRejectsMismatchedQuestion(t *testing.T) {
+	tests := []struct {
+		name     string
+		question dnsmessage.Question
+		wantErr  bool
+	}{
+		{"same question", dnsmessage.Question{Name: dnsName("example.test"), Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET}, false},
+		{"name in other case", dnsmessage.Question{Name: dnsName("ExAmPlE.TeSt"), Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET}, false},
+		{"other name", dnsmessage.Question{Name: dnsName("other.test"), Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET}, true},
+		{"other type", dnsmessage.Question{Name: dnsName("example.test"), Type: dnsmessage.TypeAAAA, Class: dnsmessage.ClassINET}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			standIn := newDNSStandIn(t, func(q dnsmessage.Question, network string) dnsmessage.Message {
+				return dnsmessage.Message{
+					Questions: []dnsmessage.Question{tt.question},
+					Answers:   []dnsmessage.Resource{aRecord("example.test", loopback)},
+				}
+			})
+			client := NewDNSClient(time.Second)
+
+			_, err := client.Query(context.Background(), standIn.addr, "example.test", dnsmessage.TypeA, false)
+			if tt.wantErr && (err == nil || !strings.Contains(err.Error(), "different question")) {
+				t.Errorf("err = %v, want a mismatched question error", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Query: %v", err)
+			}
+		})
+	}
+}
+
+func TestDNSClientReportsTimeout(t *testing.T) {
+	// A UDP socket that never answers
+	silent, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen udp: %v", err)
+	}
+	defer silent.Close()
+
+	client := NewDNSClient(50 * time.Millisecond)
+	_, err = client.Query(context.Background(), silent.LocalAddr().String(), "example.test", dnsmessage.TypeA, false)
+
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Errorf("err = %v, want a timeout", err)
+	}
+}
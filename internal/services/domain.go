@@ -1,39 +1,69 @@
 package services
 
 import (
-	"bufio"
 	"context"
+	"errors"
 	"fmt"
-	"net"
-	"os"
+	"log"
 	"strings"
 	"sync"
 	"time"
 
 	"domaincheck/internal/config"
 	"domaincheck/internal/models"
+	"domaincheck/internal/storage"
 	"domaincheck/internal/utils"
 )
 
 // DomainService handles domain checking operations
 type DomainService struct {
 	cfg             *config.Config
-	validExtensions map[string]bool
-	checkedDomains  []models.Domain
-	mutex           sync.RWMutex
+	extensions      map[string]models.ExtensionInfo // Valid extensions by A-label
 	extensionsMutex sync.RWMutex
-	domainIDCounter int
-	history         []models.Domain
-	historyMutex    sync.RWMutex
+	historyStore    storage.HistoryStore
+	presets         map[string]models.ExtensionPreset // Presets defined in configuration by name
+	presetStore     storage.PresetStore
+	presetsMutex    sync.Mutex
+	checkers        map[string]Checker
+	checkersMutex   sync.RWMutex
+	checkerStats    *checkerStats
+	cache           *resultCache
+	inflight        *flightGroup
+	rateLimiter     *rateLimiter
+	retryPolicy     *retryPolicy
+	rdapClient      *RDAPClient
+	whoisClient     *WhoisClient
+	resolverPool    *ResolverPool
 }
 
 // NewDomainService creates a new domain service instance
 func NewDomainService(cfg *config.Config) (*DomainService, error) {
 	service := &DomainService{
-		cfg:             cfg,
-		validExtensions: make(map[string]bool),
-		checkedDomains:  make([]models.Domain, 0),
-		domainIDCounter: 1,
+		cfg:          cfg,
+		extensions:   make(map[string]models.ExtensionInfo),
+		checkers:     make(map[string]Checker),
+		checkerStats: newCheckerStats(),
+		cache:        newResultCache(cfg.Cache),
+		inflight:     newFlightGroup(),
+		rateLimiter:  newRateLimiter(cfg.RateLimit),
+		retryPolicy:  newRetryPolicy(cfg.Retry),
+		whoisClient:  NewWhoisClient(),
+		resolverPool: NewResolverPool(cfg.Resolvers),
+	}
+	service.whoisClient.limiter = service.rateLimiter
+	service.resolverPool.limiter = service.rateLimiter
+
+	// Load RDAP bootstrap registry; the extensions file may override its servers
+	rdapClient, err := NewRDAPClient(cfg.Domain.RDAPBootstrapFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize RDAP client: %w", err)
+	}
+	rdapClient.limiter = service.rateLimiter
+	service.rdapClient = rdapClient
+
+	// Load the public suffix list before the extensions that are checked against it
+	if err := service.loadPublicSuffixList(); err != nil {
+		return nil, err
 	}
 
 	// Load valid extensions from file
@@ -41,44 +71,118 @@ func NewDomainService(cfg *config.Config) (*DomainService, error) {
 		return nil, fmt.Errorf("failed to load domain extensions: %w", err)
 	}
 
+	// Open check history storage
+	historyStore, err := storage.NewHistoryStore(cfg.History)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize history store: %w", err)
+	}
+	service.historyStore = historyStore
+
+	// Load extension presets from configuration and open custom preset storage
+	if err := service.loadPresets(cfg.ExtensionPresets); err != nil {
+		historyStore.Close()
+		return nil, err
+	}
+
+	// Register built-in availability checkers; authoritative queries share the resolver timeout
+	dnsTimeout := cfg.Resolvers.Timeout
+	if dnsTimeout <= 0 {
+		dnsTimeout = defaultDNSTimeout
+	}
+	service.RegisterChecker(NewDNSChecker(service.resolverPool))
+	service.RegisterChecker(NewAuthoritativeChecker(NewDNSClient(dnsTimeout), service.resolverPool))
+	service.RegisterChecker(NewRDAPChecker(rdapClient))
+	service.RegisterChecker(NewWhoisChecker(service.whoisClient))
+
+	// Make sure every configured strategy refers to a known checker
+	if _, err := service.checkerChain(&cfg.Domain, ""); err != nil {
+		service.Close()
+		return nil, err
+	}
+	for extension := range cfg.Domain.TLDStrategies {
+		if _, err := service.checkerChain(&cfg.Domain, extension); err != nil {
+			service.Close()
+			return nil, err
+		}
+	}
+
 	return service, nil
 }
 
-// loadValidExtensions loads valid domain extensions from file
+// Close releases the resources held by the service
+func (s *DomainService) Close() error {
+	if err := s.presetStore.Close(); err != nil {
+		s.historyStore.Close()
+		return err
+	}
+	return s.historyStore.Close()
+}
+
+// loadValidExtensions loads valid domain extensions and their metadata from file
 func (s *DomainService) loadValidExtensions() error {
+	infos, err := readExtensionsFile(s.cfg.Domain.ExtensionsFile)
+	if err != nil {
+		return err
+	}
+
+	extensions := make(map[string]models.ExtensionInfo, len(infos))
+	whoisServers := make(map[string]string)
+	for _, info := range infos {
+		extensions[info.Extension] = info
+		if info.WhoisServer != "" {
+			whoisServers[info.Extension] = info.WhoisServer
+		}
+	}
+
 	s.extensionsMutex.Lock()
 	defer s.extensionsMutex.Unlock()
 
-	file, err := os.Open(s.cfg.Domain.ExtensionsFile)
-	if err != nil {
-		return fmt.Errorf("failed to open extensions file: %w", err)
-	}
-	defer file.Close()
+	s.extensions = extensions
+	s.whoisClient.SetServers(whoisServers)
 
-	// Clear existing extensions
-	s.validExtensions = make(map[string]bool)
+	// RDAP servers are looked up by TLD, so only TLD entries can override them
+	for extension, info := range extensions {
+		if info.RDAPBaseURL == "" {
+			continue
+		}
+		if strings.Count(extension, ".") > 1 {
+			log.Printf("Ignoring RDAP base URL of %s; RDAP servers are configured per TLD", extension)
+			continue
+		}
+		s.rdapClient.SetServer(extension, info.RDAPBaseURL)
+	}
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		extension := strings.TrimSpace(scanner.Text())
-		if extension != "" {
-			// Ensure extension starts with dot
-			if !strings.HasPrefix(extension, ".") {
-				extension = "." + extension
+	if list := utils.CurrentPublicSuffixList(); list != nil {
+		for extension := range extensions {
+			if !list.IsPublicSuffix(extension) {
+				log.Printf("Extension %s is not a public suffix; domains under it are checked as %s", extension, utils.SplitDomain("name"+extension).Suffix)
 			}
-			s.validExtensions[strings.ToLower(extension)] = true
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("error reading extensions file: %w", err)
+	return nil
+}
+
+// loadPublicSuffixList loads the configured public suffix list, if any
+func (s *DomainService) loadPublicSuffixList() error {
+	if s.cfg.Domain.PublicSuffixFile == "" {
+		return nil
 	}
 
+	list, err := utils.LoadPublicSuffixList(s.cfg.Domain.PublicSuffixFile)
+	if err != nil {
+		return fmt.Errorf("failed to load public suffix list: %w", err)
+	}
+	utils.SetPublicSuffixList(list)
+
 	return nil
 }
 
-// ReloadExtensions reloads domain extensions from file
+// ReloadExtensions reloads the public suffix list and domain extensions from file
 func (s *DomainService) ReloadExtensions() error {
+	if err := s.loadPublicSuffixList(); err != nil {
+		return err
+	}
 	return s.loadValidExtensions()
 }
 
@@ -87,135 +191,208 @@ func (s *DomainService) GetValidExtensions() []string {
 	s.extensionsMutex.RLock()
 	defer s.extensionsMutex.RUnlock()
 
-	extensions := make([]string, 0, len(s.validExtensions))
-	for ext := range s.validExtensions {
+	extensions := make([]string, 0, len(s.extensions))
+	for ext := range s.extensions {
 		extensions = append(extensions, ext)
 	}
 	return extensions
 }
 
+// ExtensionDomains returns the domain name combined with each selected extension
+func (s *DomainService) ExtensionDomains(domainName string, selection models.ExtensionSelection) ([]string, error) {
+	extensions, err := s.SelectExtensions(selection)
+	if err != nil {
+		return nil, err
+	}
+
+	domains := make([]string, len(extensions))
+	for i, extension := range extensions {
+		domains[i] = domainName + extension // Extensions include the leading dot
+	}
+	return domains, nil
+}
+
 // IsValidExtension checks if extension is valid
 func (s *DomainService) IsValidExtension(extension string) bool {
 	s.extensionsMutex.RLock()
 	defer s.extensionsMutex.RUnlock()
 
-	if !strings.HasPrefix(extension, ".") {
-		extension = "." + extension
+	_, ok := s.extensions[utils.NormalizeExtension(extension)]
+	return ok
+}
+
+// unicodeName returns the Unicode form of an internationalized domain, or an
+// empty string for a plain ASCII one
+func unicodeName(domain string) string {
+	if !utils.IsInternationalized(domain) {
+		return ""
 	}
-	return s.validExtensions[strings.ToLower(extension)]
+	return utils.UnicodeDomain(domain)
+}
+
+// GetResolverStatus returns the health of the upstream resolver pool
+func (s *DomainService) GetResolverStatus() []models.ResolverStatus {
+	return s.resolverPool.Status()
+}
+
+// CacheStats returns the size and hit rate of the result cache
+func (s *DomainService) CacheStats() models.CacheStats {
+	return s.cache.stats()
+}
+
+// InFlightStats returns how many lookups were shared by concurrent checks
+func (s *DomainService) InFlightStats() models.InFlightStats {
+	return s.inflight.stats()
+}
+
+// RateLimitStatus returns the token buckets and backoffs of every TLD, server and resolver queried so far
+func (s *DomainService) RateLimitStatus() []models.RateLimitStatus {
+	return s.rateLimiter.status()
+}
+
+// ClearCache discards every cached check result
+func (s *DomainService) ClearCache() {
+	s.cache.clear()
 }
 
 // CheckDomain performs domain availability check
 func (s *DomainService) CheckDomain(ctx context.Context, domainName string) (*models.DomainCheckResponse, error) {
-	startTime := time.Now()
+	return s.checkDomain(ctx, domainName, &s.cfg.Domain)
+}
 
-	// Sanitize domain
-	domainName = utils.SanitizeDomain(domainName)
+// checkDomain performs a domain availability check with the given timeout and strategies
+func (s *DomainService) checkDomain(ctx context.Context, domainName string, domainCfg *config.DomainConfig) (*models.DomainCheckResponse, error) {
+	startTime := time.Now()
 
-	// Validate domain format
-	if !utils.ValidateDomainFormat(domainName) {
-		return nil, fmt.Errorf("invalid domain format: %s", domainName)
+	// Sanitize the domain and convert internationalized names to A-labels
+	domainName, err := utils.NormalizeDomain(domainName)
+	if err != nil {
+		return nil, err
 	}
 
-	// Extract domain parts
-	_, extension := utils.ExtractDomainParts(domainName)
-	if extension == "" {
+	// Split off the public suffix; availability is a property of the registrable domain
+	parts := utils.SplitDomain(domainName)
+	if parts.Suffix == "" {
 		return nil, fmt.Errorf("domain must have an extension")
 	}
+	if parts.Name == "" {
+		return nil, fmt.Errorf("%s is a public suffix, not a registrable domain", domainName)
+	}
+	domainName = parts.RegistrableDomain()
+	extension := parts.Suffix
 
 	// Check if extension is supported
 	isValidTLD := s.IsValidExtension(extension)
 
-	// Create context with timeout
-	timeoutCtx, cancel := context.WithTimeout(ctx, s.cfg.Domain.Timeout)
-	defer cancel()
+	// Names the registry would refuse are not worth a lookup
+	if err := s.checkLabelLength(parts.Name, extension); err != nil {
+		return nil, err
+	}
 
-	// Perform DNS lookup
-	domain := &models.Domain{
-		ID:        s.getNextDomainID(),
-		Name:      domainName,
-		Extension: extension,
-		CheckedAt: time.Now(),
-	}
-
-	// DNS resolution with multiple DNS servers for reliability
-	dnsServers := []string{"8.8.8.8:53", "1.1.1.1:53", "208.67.222.222:53"} // Google, Cloudflare, OpenDNS
-
-	var ips []net.IPAddr
-	var err error
-	var lastError error
-
-	// Try different DNS servers
-	for _, dnsServer := range dnsServers {
-		resolver := &net.Resolver{
-			PreferGo: true,
-			Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
-				d := net.Dialer{
-					Timeout: time.Second * 2,
-				}
-				return d.DialContext(ctx, network, dnsServer)
-			},
-		}
+	// Answer repeated checks from the cache unless the caller asks for a fresh one
+	cacheKey := resultCacheKey(domainName, strategyNames(domainCfg, extension))
+	if cacheBypassed(ctx) {
+		s.cache.bypass()
+	} else if cached, ok := s.cache.get(cacheKey); ok {
+		// History lists every check in the order it happened, so the row of a
+		// cache hit is dated now; the age of the cached verdict is in CacheAge
+		entry := *cached
+		entry.CheckedAt = time.Now()
+		s.AddToHistory(&entry)
+		cached.ID = entry.ID
+		cached.Subdomain = parts.Subdomain
+		return &models.DomainCheckResponse{
+			Domain:       cached,
+			IsValidTLD:   isValidTLD,
+			SupportedTLD: isValidTLD,
+		}, nil
+	}
 
-		ips, err = resolver.LookupIPAddr(timeoutCtx, domainName)
-		if err == nil {
-			break // Success, exit loop
+	// Concurrent checks of the same domain share one lookup
+	domain, err := s.inflight.do(ctx, cacheKey, func(ctx context.Context) *models.Domain {
+		return s.lookupDomain(ctx, domainName, extension, cacheKey, domainCfg)
+	})
+	if err != nil {
+		// The caller gave up before the shared lookup finished
+		domain = &models.Domain{
+			Name:         domainName,
+			UnicodeName:  unicodeName(domainName),
+			Extension:    extension,
+			Availability: availabilityFromError(err),
+			Confidence:   models.ConfidenceNone,
+			Status:       models.LegacyStatusError,
+			CheckedAt:    startTime,
+			ResponseTime: time.Since(startTime).Milliseconds(),
+			Error:        err.Error(),
 		}
-		lastError = err
+	}
+	domain.Subdomain = parts.Subdomain
 
-		// If context is cancelled or timed out, don't try other servers
-		if timeoutCtx.Err() != nil {
-			break
-		}
+	response := &models.DomainCheckResponse{
+		Domain:       domain,
+		IsValidTLD:   isValidTLD,
+		SupportedTLD: isValidTLD,
 	}
 
-	if err != nil {
-		// Check if it's a real DNS error (domain doesn't exist) or network error
-		errorStr := err.Error()
-		if strings.Contains(errorStr, "no such host") ||
-			strings.Contains(errorStr, "NXDOMAIN") ||
-			strings.Contains(errorStr, "server misbehaving") {
-			domain.Available = true
-			domain.DNSResolved = false
-			domain.Status = "Available"
-		} else {
-			// Network or other error
-			domain.Available = false
-			domain.DNSResolved = false
-			domain.Status = "Error"
-		}
+	return response, nil
+}
 
-		// Use the last error for reporting
-		if lastError != nil {
-			domain.Error = lastError.Error()
-		} else {
-			domain.Error = err.Error()
-		}
+// lookupDomain runs the checker strategy chain for a domain, records the
+// result in history and caches it unless ctx was cancelled
+func (s *DomainService) lookupDomain(ctx context.Context, domainName, extension, cacheKey string, domainCfg *config.DomainConfig) *models.Domain {
+	startTime := time.Now()
+
+	// Create context with timeout
+	timeoutCtx, cancel := context.WithTimeout(ctx, domainCfg.Timeout)
+	defer cancel()
+
+	// Perform availability check
+	domain := &models.Domain{
+		Name:        domainName,
+		UnicodeName: unicodeName(domainName),
+		Extension:   extension,
+		CheckedAt:   startTime,
+	}
+
+	// Run the configured checker strategy chain
+	result, checker, evidence, attempts, err := s.runCheckers(timeoutCtx, domainCfg, domainName, extension)
+	domain.Checker = checker
+	domain.Evidence = evidence
+	if attemptsFailed(attempts) {
+		domain.Attempts = attempts
+	}
+	var dnsTTL time.Duration
+	if err != nil {
+		// No checker could reach a verdict
+		domain.Availability = availabilityFromError(err)
+		domain.Confidence = models.ConfidenceNone
+		domain.Error = err.Error()
 	} else {
-		domain.Available = false
-		domain.DNSResolved = true
-		domain.Status = "Registered"
-		if len(ips) > 0 {
-			domain.IP = ips[0].IP.String()
-		}
+		domain.Availability = result.Availability
+		domain.Confidence = result.Confidence
+		domain.DNSResolved = result.DNSResolved
+		domain.IP = result.IP
+		domain.Delegation = result.Delegation
+		domain.Error = result.Error
+		dnsTTL = result.TTL
 	}
+	domain.Available = domain.Availability.IsAvailable()
+	domain.Status = domain.Availability.LegacyStatus()
 
 	// Calculate response time
 	domain.ResponseTime = time.Since(startTime).Milliseconds()
 
-	// Store domain check result
-	s.storeDomainResult(*domain)
+	if ctx.Err() != nil {
+		// Every caller gave up, so the verdict was cut short by cancellation
+		// rather than reached: it is neither history nor worth caching
+		return domain
+	}
 
-	// Add to history
+	// Add to history, which assigns the domain its ID
 	s.AddToHistory(domain)
+	s.cache.put(cacheKey, domain, dnsTTL)
 
-	response := &models.DomainCheckResponse{
-		Domain:       domain,
-		IsValidTLD:   isValidTLD,
-		SupportedTLD: isValidTLD,
-	}
-
-	return response, nil
+	return domain
 }
 
 // CheckMultipleDomains checks multiple domains concurrently
@@ -300,181 +477,59 @@ func (s *DomainService) CheckMultipleDomains(ctx context.Context, domains []stri
 	return results, nil
 }
 
-// GetDomainHistory returns checked domain history
-func (s *DomainService) GetDomainHistory() []models.Domain {
-	s.historyMutex.RLock()
-	defer s.historyMutex.RUnlock()
-
-	// Return a copy to avoid race conditions
-	result := make([]models.Domain, len(s.history))
-	copy(result, s.history)
-	return result
+// GetDomainHistory returns checked domain history, newest first
+func (s *DomainService) GetDomainHistory() ([]models.Domain, error) {
+	history, err := s.historyStore.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	return history, nil
 }
 
 // ClearHistory clears domain check history
-func (s *DomainService) ClearHistory() {
-	s.historyMutex.Lock()
-	defer s.historyMutex.Unlock()
-	s.history = []models.Domain{}
-}
-
-// storeDomainResult stores domain check result
-func (s *DomainService) storeDomainResult(domain models.Domain) {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-
-	s.checkedDomains = append(s.checkedDomains, domain)
-}
-
-// getNextDomainID returns next domain ID
-func (s *DomainService) getNextDomainID() int {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-
-	id := s.domainIDCounter
-	s.domainIDCounter++
-	return id
-}
-
-// CheckAllExtensions checks a domain name with all available extensions
-func (s *DomainService) CheckAllExtensions(ctx context.Context, domainName string) (*models.AllExtensionsCheckResult, error) {
-	startTime := time.Now()
-
-	// Get all extensions
-	extensions := s.GetValidExtensions()
-	totalExtensions := len(extensions)
-
-	// Create result structure
-	result := &models.AllExtensionsCheckResult{
-		DomainName:         domainName,
-		TotalExtensions:    totalExtensions,
-		AvailableCount:     0,
-		UnavailableCount:   0,
-		ErrorCount:         0,
-		TotalTime:          0,
-		AllResults:         []models.DomainCheckResponse{},
-		AvailableDomains:   []models.DomainCheckResponse{},
-		UnavailableDomains: []models.DomainCheckResponse{},
-		Summary: models.ExtensionCheckSummary{
-			RecommendedDomains:     []string{},
-			AlternativeSuggestions: []string{},
-		},
-	}
-
-	// Create channels for concurrent processing
-	results := make(chan models.DomainCheckResponse, totalExtensions)
-	errors := make(chan error, totalExtensions)
-
-	// Start concurrent domain checks
-	semaphore := make(chan struct{}, s.cfg.Domain.MaxConcurrentChecks)
-	var wg sync.WaitGroup
-
-	for _, ext := range extensions {
-		wg.Add(1)
-		go func(extension string) {
-			defer wg.Done()
-			semaphore <- struct{}{}        // Acquire semaphore
-			defer func() { <-semaphore }() // Release semaphore
-
-			fullDomain := domainName + "." + extension
-			start := time.Now()
-
-			checkResult, err := s.CheckDomain(ctx, fullDomain)
-			responseTime := time.Since(start).Milliseconds()
-
-			if err != nil {
-				errors <- err
-				return
-			}
-
-			// Update response time
-			checkResult.Domain.ResponseTime = responseTime
-
-			// Add to history
-			s.AddToHistory(checkResult.Domain)
-
-			results <- *checkResult
-		}(ext)
+func (s *DomainService) ClearHistory() error {
+	if err := s.historyStore.Clear(); err != nil {
+		return fmt.Errorf("failed to clear history: %w", err)
 	}
+	return nil
+}
 
-	// Close channels when all goroutines complete
-	go func() {
-		wg.Wait()
-		close(results)
-		close(errors)
-	}()
-
-	// Process results
-	for resultItem := range results {
-		result.AllResults = append(result.AllResults, resultItem)
-
-		if resultItem.Domain.Status == "Available" {
-			result.AvailableCount++
-			result.AvailableDomains = append(result.AvailableDomains, resultItem)
-			result.Summary.RecommendedDomains = append(result.Summary.RecommendedDomains, resultItem.Domain.Name)
-		} else {
-			result.UnavailableCount++
-			result.UnavailableDomains = append(result.UnavailableDomains, resultItem)
-		}
+// GetWhoisInfo retrieves registration data for a domain via RDAP,
+// falling back to port-43 WHOIS for TLDs without an RDAP service
+func (s *DomainService) GetWhoisInfo(ctx context.Context, domain string) (*models.WhoisInfo, error) {
+	domain, err := utils.NormalizeDomain(domain)
+	if err != nil {
+		return nil, err
 	}
 
-	// Process errors
-	for range errors {
-		result.ErrorCount++
+	// Registration data belongs to the registrable domain
+	if registrable := utils.SplitDomain(domain).RegistrableDomain(); registrable != "" {
+		domain = registrable
 	}
 
-	// Calculate total time
-	result.TotalTime = time.Since(startTime).Milliseconds()
-
-	// Generate alternative suggestions
-	result.Summary.AlternativeSuggestions = s.generateAlternativeSuggestions(domainName)
-
-	return result, nil
-}
+	// Create context with timeout
+	timeoutCtx, cancel := context.WithTimeout(ctx, s.cfg.Domain.Timeout)
+	defer cancel()
 
-// GetWhoisInfo retrieves WHOIS information for a domain
-func (s *DomainService) GetWhoisInfo(ctx context.Context, domain string) (*models.WhoisInfo, error) {
-	// Simple WHOIS lookup using net package
-	// Note: This is a basic implementation. For production, consider using a dedicated WHOIS library
-	whoisInfo := &models.WhoisInfo{
-		Domain:    domain,
-		CheckedAt: time.Now(),
+	whoisInfo, err := s.rdapClient.LookupDomain(timeoutCtx, domain)
+	if errors.Is(err, ErrCheckerNotApplicable) {
+		whoisInfo, err = s.whoisClient.LookupDomain(timeoutCtx, domain)
 	}
-
-	// Try to get basic domain information
-	ips, err := net.LookupIP(domain)
-	if err == nil && len(ips) > 0 {
-		whoisInfo.Status = []string{"Active"}
+	if errors.Is(err, ErrCheckerNotApplicable) {
+		_, extension := utils.ExtractDomainParts(domain)
+		return nil, fmt.Errorf("no RDAP or WHOIS server known for %s", extension)
 	}
-
-	// Get nameservers
-	ns, err := net.LookupNS(domain)
-	if err == nil {
-		for _, n := range ns {
-			whoisInfo.NameServers = append(whoisInfo.NameServers, n.Host)
-		}
+	if err != nil {
+		return nil, err
 	}
 
-	// For a more comprehensive WHOIS lookup, you would need to:
-	// 1. Connect to the appropriate WHOIS server for the TLD
-	// 2. Send WHOIS query
-	// 3. Parse the response
-	// This is a simplified version for demonstration
-
 	return whoisInfo, nil
 }
 
-// AddToHistory adds a domain check result to history
+// AddToHistory adds a domain check result to history and assigns its ID
 func (s *DomainService) AddToHistory(domain *models.Domain) {
-	s.historyMutex.Lock()
-	defer s.historyMutex.Unlock()
-
-	// Add to beginning of slice
-	s.history = append([]models.Domain{*domain}, s.history...)
-
-	// Keep only last 1000 entries
-	if len(s.history) > 1000 {
-		s.history = s.history[:1000]
+	if err := s.historyStore.Add(domain); err != nil {
+		log.Printf("Failed to store history entry for %s: %v", domain.Name, err)
 	}
 }
 
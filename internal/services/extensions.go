@@ -0,0 +1,303 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"domaincheck/internal/models"
+	"domaincheck/internal/utils"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalidExtensionQuery is returned when extension list parameters cannot be used
+var ErrInvalidExtensionQuery = errors.New("invalid extension query")
+
+// ErrLabelLength is returned for a name shorter or longer than its extension accepts
+var ErrLabelLength = errors.New("name length not accepted by the extension")
+
+const (
+	// defaultExtensionMinLength is the shortest label of extensions that do not specify one
+	defaultExtensionMinLength = 1
+	// defaultExtensionMaxLength is the longest label of extensions that do not specify one
+	defaultExtensionMaxLength = 63
+)
+
+// extensionTypes maps lowercase extension types to their canonical spelling
+var extensionTypes = map[string]string{
+	strings.ToLower(models.ExtensionTypeGeneric):     models.ExtensionTypeGeneric,
+	strings.ToLower(models.ExtensionTypeCountryCode): models.ExtensionTypeCountryCode,
+	strings.ToLower(models.ExtensionTypeNewGeneric):  models.ExtensionTypeNewGeneric,
+}
+
+// extensionSortFields are the fields the extension list can be sorted by
+var extensionSortFields = map[string]bool{
+	"extension":  true,
+	"popularity": true,
+	"price":      true,
+}
+
+// extensionsFile is the structured extensions file
+type extensionsFile struct {
+	Extensions []models.ExtensionInfo `json:"extensions" yaml:"extensions"`
+}
+
+// readExtensionsFile reads the extensions file. Files ending in .yaml, .yml or
+// .json list extensions with their metadata under "extensions"; any other file
+// holds one extension per line, optionally followed by its WHOIS server.
+func readExtensionsFile(path string) ([]models.ExtensionInfo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open extensions file: %w", err)
+	}
+
+	var file extensionsFile
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &file)
+	case ".json":
+		err = json.Unmarshal(data, &file)
+	default:
+		file.Extensions, err = parseExtensionLines(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse extensions file: %w", err)
+	}
+
+	extensions := make([]models.ExtensionInfo, 0, len(file.Extensions))
+	for i, info := range file.Extensions {
+		if err := normalizeExtensionInfo(&info); err != nil {
+			return nil, fmt.Errorf("invalid entry %d in extensions file: %w", i+1, err)
+		}
+		extensions = append(extensions, info)
+	}
+	return extensions, nil
+}
+
+// parseExtensionLines parses the line format of the extensions file
+func parseExtensionLines(data []byte) ([]models.ExtensionInfo, error) {
+	var extensions []models.ExtensionInfo
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		if utils.NormalizeExtension(fields[0]) == "" {
+			log.Printf("Skipping invalid extension %q in extensions file", fields[0])
+			continue
+		}
+
+		info := models.ExtensionInfo{Extension: fields[0]}
+		if len(fields) > 1 {
+			info.WhoisServer = fields[1]
+		}
+		extensions = append(extensions, info)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading extensions file: %w", err)
+	}
+	return extensions, nil
+}
+
+// checkLabelLength rejects a name outside the label length bounds of its
+// extension; extensions missing from the extensions file are not limited
+func (s *DomainService) checkLabelLength(name, extension string) error {
+	s.extensionsMutex.RLock()
+	info, ok := s.extensions[extension]
+	s.extensionsMutex.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	if length := len(name); length < info.MinLength || length > info.MaxLength {
+		return fmt.Errorf("%w: %s accepts names of %d to %d characters, %s has %d",
+			ErrLabelLength, extension, info.MinLength, info.MaxLength, name, length)
+	}
+	return nil
+}
+
+// normalizeExtensionInfo validates an extension's metadata and fills in defaults
+func normalizeExtensionInfo(info *models.ExtensionInfo) error {
+	// Ensure extension starts with dot; IDN extensions are kept as A-labels
+	extension := utils.NormalizeExtension(info.Extension)
+	if extension == "" {
+		return fmt.Errorf("invalid extension %q", info.Extension)
+	}
+	info.Extension = extension
+	if utils.IsInternationalized(extension) {
+		info.UnicodeExtension = "." + utils.UnicodeDomain(extension[1:])
+	}
+
+	if info.Type != "" {
+		extensionType, ok := extensionTypes[strings.ToLower(strings.TrimSpace(info.Type))]
+		if !ok {
+			return fmt.Errorf("%s: unknown type %q, expected gTLD, ccTLD or new gTLD", extension, info.Type)
+		}
+		info.Type = extensionType
+	}
+	info.Category = strings.ToLower(strings.TrimSpace(info.Category))
+	info.WhoisServer = strings.ToLower(strings.TrimSpace(info.WhoisServer))
+
+	if info.Popularity < 0 {
+		return fmt.Errorf("%s: popularity must not be negative", extension)
+	}
+
+	if info.RDAPBaseURL != "" {
+		parsed, err := url.Parse(info.RDAPBaseURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("%s: invalid RDAP base URL %q", extension, info.RDAPBaseURL)
+		}
+		if !strings.HasSuffix(info.RDAPBaseURL, "/") {
+			info.RDAPBaseURL += "/"
+		}
+	}
+
+	if info.MinLength == 0 {
+		info.MinLength = defaultExtensionMinLength
+	}
+	if info.MaxLength == 0 {
+		info.MaxLength = defaultExtensionMaxLength
+	}
+	if info.MinLength < 1 || info.MaxLength > defaultExtensionMaxLength || info.MinLength > info.MaxLength {
+		return fmt.Errorf("%s: label length must be between 1 and 63 with min_length not above max_length", extension)
+	}
+
+	if price := info.TypicalPrice; price != nil {
+		price.Currency = strings.ToUpper(strings.TrimSpace(price.Currency))
+		if price.Amount < 0 {
+			return fmt.Errorf("%s: typical price must not be negative", extension)
+		}
+		if price.Currency == "" {
+			return fmt.Errorf("%s: typical price needs a currency", extension)
+		}
+	}
+
+	return nil
+}
+
+// ListExtensions returns the metadata of the valid extensions matching a query.
+// Extensions without an RDAP base URL of their own show the one of the bootstrap file.
+func (s *DomainService) ListExtensions(query models.ExtensionQuery) ([]models.ExtensionInfo, error) {
+	sortField := strings.ToLower(query.Sort)
+	if sortField == "" {
+		sortField = "extension"
+	}
+	if !extensionSortFields[sortField] {
+		return nil, fmt.Errorf("%w: unknown sort field %q", ErrInvalidExtensionQuery, query.Sort)
+	}
+
+	matcher, err := newExtensionMatcher(query.ExtensionFilter)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidExtensionQuery, err)
+	}
+
+	s.extensionsMutex.RLock()
+	extensions := make([]models.ExtensionInfo, 0, len(s.extensions))
+	for _, info := range s.extensions {
+		if matcher.matches(info) {
+			extensions = append(extensions, info)
+		}
+	}
+	s.extensionsMutex.RUnlock()
+
+	if s.rdapClient != nil {
+		for i := range extensions {
+			if extensions[i].RDAPBaseURL == "" {
+				extensions[i].RDAPBaseURL, _ = s.rdapClient.BaseURL(topLevelDomain(extensions[i].Extension))
+			}
+		}
+	}
+
+	sort.Slice(extensions, func(i, j int) bool {
+		a, b := extensions[i], extensions[j]
+		switch sortField {
+		case "popularity":
+			// Unranked extensions come last
+			if a.Popularity != b.Popularity && (a.Popularity == 0) != (b.Popularity == 0) {
+				return b.Popularity == 0
+			}
+			if a.Popularity != b.Popularity {
+				return a.Popularity < b.Popularity
+			}
+		case "price":
+			// Extensions without a price come last
+			if (a.TypicalPrice == nil) != (b.TypicalPrice == nil) {
+				return b.TypicalPrice == nil
+			}
+			if a.TypicalPrice != nil && a.TypicalPrice.Amount != b.TypicalPrice.Amount {
+				return a.TypicalPrice.Amount < b.TypicalPrice.Amount
+			}
+		}
+		return a.Extension < b.Extension
+	})
+
+	return extensions, nil
+}
+
+// extensionMatcher is an extension filter prepared for matching
+type extensionMatcher struct {
+	filter     models.ExtensionFilter
+	types      map[string]bool
+	categories map[string]bool
+	search     string
+}
+
+// newExtensionMatcher validates a filter and prepares it for matching
+func newExtensionMatcher(filter models.ExtensionFilter) (*extensionMatcher, error) {
+	matcher := &extensionMatcher{
+		filter:     filter,
+		types:      make(map[string]bool, len(filter.Type)),
+		categories: make(map[string]bool, len(filter.Category)),
+		search:     strings.ToLower(strings.TrimSpace(filter.Search)),
+	}
+
+	for _, value := range filter.Type {
+		extensionType, ok := extensionTypes[strings.ToLower(strings.TrimSpace(value))]
+		if !ok {
+			return nil, fmt.Errorf("unknown type %q", value)
+		}
+		matcher.types[extensionType] = true
+	}
+	for _, category := range filter.Category {
+		matcher.categories[strings.ToLower(strings.TrimSpace(category))] = true
+	}
+
+	return matcher, nil
+}
+
+// matches reports whether an extension meets every criterion of the filter
+func (m *extensionMatcher) matches(info models.ExtensionInfo) bool {
+	filter := m.filter
+	switch {
+	case len(m.types) > 0 && !m.types[info.Type]:
+		return false
+	case len(m.categories) > 0 && !m.categories[info.Category]:
+		return false
+	case m.search != "" && !strings.Contains(info.Extension, m.search) && !strings.Contains(info.UnicodeExtension, m.search):
+		return false
+	case filter.IDN != nil && info.IDN != *filter.IDN:
+		return false
+	case filter.Restricted != nil && (info.Restrictions != "") != *filter.Restricted:
+		return false
+	case filter.MaxPopularity > 0 && (info.Popularity == 0 || info.Popularity > filter.MaxPopularity):
+		return false
+	case filter.MaxPrice != nil && (info.TypicalPrice == nil || info.TypicalPrice.Amount > *filter.MaxPrice):
+		return false
+	case filter.LabelLength > 0 && (filter.LabelLength < info.MinLength || filter.LabelLength > info.MaxLength):
+		return false
+	}
+	return true
+}
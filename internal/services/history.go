@@ -0,0 +1,431 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"domaincheck/internal/models"
+)
+
+// ErrInvalidHistoryQuery is returned when history search parameters cannot be used
+var ErrInvalidHistoryQuery = errors.New("invalid history query")
+
+// historyExportBatchSize is how many entries are read from the store at a time while exporting
+const historyExportBatchSize = 500
+
+// historySortFields are the fields history can be sorted by
+var historySortFields = map[string]bool{
+	"id":            true,
+	"name":          true,
+	"extension":     true,
+	"status":        true,
+	"checked_at":    true,
+	"response_time": true,
+	"dns_resolved":  true,
+}
+
+// historySortKey is the comparable value of the sort field of an entry
+type historySortKey struct {
+	Num int64  `json:"n,omitempty"`
+	Str string `json:"s,omitempty"`
+}
+
+// historyCursor marks the last entry of a page; the next page starts after it
+type historyCursor struct {
+	Sort  string         `json:"sort"`
+	Order string         `json:"order"`
+	Key   historySortKey `json:"key"`
+	ID    int            `json:"id"`
+}
+
+// historyFilter is a validated history query
+type historyFilter struct {
+	statuses        map[string]bool
+	extensions      map[string]bool
+	name            string
+	nameRegex       *regexp.Regexp
+	from            time.Time
+	to              time.Time
+	minResponseTime *int64
+	maxResponseTime *int64
+	dnsResolved     *bool
+}
+
+// SearchHistory filters, sorts and paginates the check history.
+// A cursor takes precedence over the page number, so that paging stays
+// stable while new checks are added to the head of the history.
+func (s *DomainService) SearchHistory(query models.HistoryQuery) (*models.HistoryPage, error) {
+	filter, err := newHistoryFilter(query)
+	if err != nil {
+		return nil, err
+	}
+
+	sortField, order, err := parseHistorySort(query)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := s.GetDomainHistory()
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []models.Domain
+	for _, domain := range history {
+		if filter.matches(domain) {
+			matches = append(matches, domain)
+		}
+	}
+
+	sortHistory(matches, sortField, order)
+
+	perPage := query.PerPage
+	if perPage < 1 {
+		perPage = 20
+	}
+
+	// Find where the requested page starts
+	start := 0
+	if query.Cursor != "" {
+		cursor, err := decodeHistoryCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if cursor.Sort != sortField || cursor.Order != order {
+			return nil, fmt.Errorf("%w: cursor was issued for a different sort order", ErrInvalidHistoryQuery)
+		}
+
+		// The cursor entry may have been removed by retention, so compare
+		// by key rather than looking it up
+		start = sort.Search(len(matches), func(i int) bool {
+			c := compareHistoryKeys(historyEntryKey(matches[i], sortField), matches[i].ID, cursor.Key, cursor.ID)
+			if order == "desc" {
+				return c < 0
+			}
+			return c > 0
+		})
+	} else if query.Page > 1 {
+		start = (query.Page - 1) * perPage
+	}
+
+	if start > len(matches) {
+		start = len(matches)
+	}
+	end := start + perPage
+	if end > len(matches) {
+		end = len(matches)
+	}
+
+	page := &models.HistoryPage{
+		Items: append([]models.Domain{}, matches[start:end]...),
+		Total: len(matches),
+	}
+	if end < len(matches) && end > start {
+		last := matches[end-1]
+		page.NextCursor = encodeHistoryCursor(historyCursor{
+			Sort:  sortField,
+			Order: order,
+			Key:   historyEntryKey(last, sortField),
+			ID:    last.ID,
+		})
+	}
+
+	return page, nil
+}
+
+// HistoryExport iterates over the history entries matching a query
+type HistoryExport struct {
+	service   *DomainService
+	filter    *historyFilter
+	sortField string
+	order     string
+	sorted    bool // An explicit sort order was requested
+}
+
+// NewHistoryExport validates a history query for export. Paging parameters are ignored.
+func (s *DomainService) NewHistoryExport(query models.HistoryQuery) (*HistoryExport, error) {
+	filter, err := newHistoryFilter(query)
+	if err != nil {
+		return nil, err
+	}
+
+	sortField, order, err := parseHistorySort(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return &HistoryExport{
+		service:   s,
+		filter:    filter,
+		sortField: sortField,
+		order:     order,
+		sorted:    query.Sort != "" || query.Order != "",
+	}, nil
+}
+
+// Each passes every matching entry to fn. Without an explicit sort order the
+// entries are streamed from the store in batches, newest first; sorting
+// requires collecting the matches first.
+func (e *HistoryExport) Each(fn func(domain *models.Domain) error) error {
+	var matches []models.Domain
+
+	beforeID := 0
+	for {
+		batch, err := e.service.historyStore.Scan(beforeID, historyExportBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to read history: %w", err)
+		}
+
+		for i := range batch {
+			if !e.filter.matches(batch[i]) {
+				continue
+			}
+			if e.sorted {
+				matches = append(matches, batch[i])
+				continue
+			}
+			if err := fn(&batch[i]); err != nil {
+				return err
+			}
+		}
+
+		if len(batch) < historyExportBatchSize {
+			break
+		}
+		beforeID = batch[len(batch)-1].ID
+	}
+
+	if !e.sorted {
+		return nil
+	}
+
+	sortHistory(matches, e.sortField, e.order)
+	for i := range matches {
+		if err := fn(&matches[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseHistorySort validates the sort field and order of a history query
+func parseHistorySort(query models.HistoryQuery) (string, string, error) {
+	sortField := strings.ToLower(query.Sort)
+	if sortField == "" {
+		sortField = "checked_at"
+	}
+	if !historySortFields[sortField] {
+		return "", "", fmt.Errorf("%w: unsupported sort field %q", ErrInvalidHistoryQuery, query.Sort)
+	}
+
+	order := strings.ToLower(query.Order)
+	if order == "" {
+		order = "desc"
+	}
+	if order != "asc" && order != "desc" {
+		return "", "", fmt.Errorf("%w: order must be asc or desc", ErrInvalidHistoryQuery)
+	}
+
+	return sortField, order, nil
+}
+
+// sortHistory sorts entries by a field, breaking ties by ID
+func sortHistory(entries []models.Domain, field, order string) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		c := compareHistoryEntries(entries[i], entries[j], field)
+		if order == "desc" {
+			return c > 0
+		}
+		return c < 0
+	})
+}
+
+// newHistoryFilter validates the filter parameters of a history query
+func newHistoryFilter(query models.HistoryQuery) (*historyFilter, error) {
+	filter := &historyFilter{
+		statuses:        splitHistoryValues(query.Status),
+		extensions:      make(map[string]bool),
+		name:            strings.ToLower(query.Name),
+		minResponseTime: query.MinResponseTime,
+		maxResponseTime: query.MaxResponseTime,
+		dnsResolved:     query.DNSResolved,
+	}
+
+	for extension := range splitHistoryValues(query.Extension) {
+		if !strings.HasPrefix(extension, ".") {
+			extension = "." + extension
+		}
+		filter.extensions[extension] = true
+	}
+
+	if query.NameRegex != "" {
+		re, err := regexp.Compile(query.NameRegex)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid name_regex: %v", ErrInvalidHistoryQuery, err)
+		}
+		filter.nameRegex = re
+	}
+
+	var err error
+	if query.From != "" {
+		if filter.from, err = parseHistoryTime(query.From, false); err != nil {
+			return nil, err
+		}
+	}
+	if query.To != "" {
+		if filter.to, err = parseHistoryTime(query.To, true); err != nil {
+			return nil, err
+		}
+	}
+	if !filter.from.IsZero() && !filter.to.IsZero() && filter.from.After(filter.to) {
+		return nil, fmt.Errorf("%w: from must not be after to", ErrInvalidHistoryQuery)
+	}
+
+	if filter.minResponseTime != nil && filter.maxResponseTime != nil && *filter.minResponseTime > *filter.maxResponseTime {
+		return nil, fmt.Errorf("%w: min_response_time must not exceed max_response_time", ErrInvalidHistoryQuery)
+	}
+
+	return filter, nil
+}
+
+// matches reports whether a history entry passes every filter
+func (f *historyFilter) matches(domain models.Domain) bool {
+	if len(f.statuses) > 0 && !f.statuses[string(domain.Availability)] && !f.statuses[strings.ToLower(domain.Status)] {
+		return false
+	}
+	if len(f.extensions) > 0 && !f.extensions[strings.ToLower(domain.Extension)] {
+		return false
+	}
+	if f.name != "" && !strings.Contains(strings.ToLower(domain.Name), f.name) {
+		return false
+	}
+	if f.nameRegex != nil && !f.nameRegex.MatchString(domain.Name) {
+		return false
+	}
+	if !f.from.IsZero() && domain.CheckedAt.Before(f.from) {
+		return false
+	}
+	if !f.to.IsZero() && domain.CheckedAt.After(f.to) {
+		return false
+	}
+	if f.minResponseTime != nil && domain.ResponseTime < *f.minResponseTime {
+		return false
+	}
+	if f.maxResponseTime != nil && domain.ResponseTime > *f.maxResponseTime {
+		return false
+	}
+	if f.dnsResolved != nil && domain.DNSResolved != *f.dnsResolved {
+		return false
+	}
+	return true
+}
+
+// splitHistoryValues lowercases repeated and comma-separated query values into a set
+func splitHistoryValues(values []string) map[string]bool {
+	set := make(map[string]bool)
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.ToLower(strings.TrimSpace(part)); part != "" {
+				set[part] = true
+			}
+		}
+	}
+	return set
+}
+
+// parseHistoryTime parses an RFC 3339 timestamp or a date. A date used as
+// the end of a range covers the whole day.
+func parseHistoryTime(value string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: invalid date %q, expected RFC 3339 or YYYY-MM-DD", ErrInvalidHistoryQuery, value)
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return t, nil
+}
+
+// historyEntryKey returns the sort key of an entry for a field
+func historyEntryKey(domain models.Domain, field string) historySortKey {
+	switch field {
+	case "name":
+		return historySortKey{Str: domain.Name}
+	case "extension":
+		return historySortKey{Str: domain.Extension}
+	case "status":
+		if domain.Availability != "" {
+			return historySortKey{Str: string(domain.Availability)}
+		}
+		return historySortKey{Str: strings.ToLower(domain.Status)}
+	case "checked_at":
+		return historySortKey{Num: domain.CheckedAt.UnixNano()}
+	case "response_time":
+		return historySortKey{Num: domain.ResponseTime}
+	case "dns_resolved":
+		if domain.DNSResolved {
+			return historySortKey{Num: 1}
+		}
+		return historySortKey{}
+	default:
+		return historySortKey{Num: int64(domain.ID)}
+	}
+}
+
+// compareHistoryEntries orders two entries by a field, breaking ties by ID
+func compareHistoryEntries(a, b models.Domain, field string) int {
+	return compareHistoryKeys(historyEntryKey(a, field), a.ID, historyEntryKey(b, field), b.ID)
+}
+
+// compareHistoryKeys returns -1, 0 or 1 comparing two sort keys, breaking ties by ID
+func compareHistoryKeys(a historySortKey, aID int, b historySortKey, bID int) int {
+	switch {
+	case a.Num != b.Num:
+		if a.Num < b.Num {
+			return -1
+		}
+		return 1
+	case a.Str != b.Str:
+		if a.Str < b.Str {
+			return -1
+		}
+		return 1
+	case aID != bID:
+		if aID < bID {
+			return -1
+		}
+		return 1
+	default:
+		return 0
+	}
+}
+
+// encodeHistoryCursor serializes a cursor into an opaque URL-safe token
+func encodeHistoryCursor(cursor historyCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeHistoryCursor parses a cursor token
+func decodeHistoryCursor(token string) (*historyCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidHistoryQuery)
+	}
+
+	var cursor historyCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidHistoryQuery)
+	}
+	return &cursor, nil
+}
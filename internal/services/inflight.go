@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"sync"
+
+	"domaincheck/internal/models"
+)
+
+// flightGroup coalesces concurrent lookups of the same key: the first caller
+// starts the lookup and later callers wait for its result instead of
+// repeating it
+type flightGroup struct {
+	flights   map[string]*flight
+	lookups   uint64
+	coalesced uint64
+	mutex     sync.Mutex
+}
+
+// flight is a lookup in progress
+type flight struct {
+	done    chan struct{} // Closed when result is set
+	result  *models.Domain
+	waiters int                // Callers still waiting for the result
+	cancel  context.CancelFunc // Stops the lookup once every caller gave up
+}
+
+// newFlightGroup creates an empty flight group
+func newFlightGroup() *flightGroup {
+	return &flightGroup{flights: make(map[string]*flight)}
+}
+
+// do runs fn once for concurrent calls with the same key and hands every
+// caller its own copy of the result. fn runs detached from the callers'
+// contexts; its context ends only when all of them have given up, in which
+// case do returns the error of the caller's context.
+func (g *flightGroup) do(ctx context.Context, key string, fn func(context.Context) *models.Domain) (*models.Domain, error) {
+	g.mutex.Lock()
+	f, ok := g.flights[key]
+	if ok {
+		f.waiters++
+		g.coalesced++
+	} else {
+		flightCtx, cancel := context.WithCancel(context.Background())
+		f = &flight{done: make(chan struct{}), waiters: 1, cancel: cancel}
+		g.flights[key] = f
+		g.lookups++
+
+		go func() {
+			defer cancel()
+
+			result := fn(flightCtx)
+
+			g.mutex.Lock()
+			if g.flights[key] == f {
+				delete(g.flights, key)
+			}
+			g.mutex.Unlock()
+
+			f.result = result
+			close(f.done)
+		}()
+	}
+	g.mutex.Unlock()
+
+	select {
+	case <-f.done:
+		domain := *f.result
+		return &domain, nil
+	case <-ctx.Done():
+		g.leave(key, f)
+		return nil, ctx.Err()
+	}
+}
+
+// leave gives up waiting for a flight and cancels it if nobody else waits
+func (g *flightGroup) leave(key string, f *flight) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	f.waiters--
+	if f.waiters > 0 {
+		return
+	}
+
+	// Later callers must not join a cancelled lookup
+	if g.flights[key] == f {
+		delete(g.flights, key)
+	}
+	f.cancel()
+}
+
+// stats returns the number of lookups and of calls that shared one
+func (g *flightGroup) stats() models.InFlightStats {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	stats := models.InFlightStats{
+		InFlight:  len(g.flights),
+		Lookups:   g.lookups,
+		Coalesced: g.coalesced,
+	}
+	if calls := g.lookups + g.coalesced; calls > 0 {
+		stats.SavedRatio = float64(g.coalesced) / float64(calls)
+	}
+	return stats
+}
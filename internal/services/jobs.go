@@ -0,0 +1,689 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"domaincheck/internal/config"
+	"domaincheck/internal/models"
+	"domaincheck/internal/storage"
+	"domaincheck/internal/utils"
+)
+
+// ErrJobNotFound is returned for unknown or expired job IDs
+var ErrJobNotFound = errors.New("job not found")
+
+// ErrJobFinished is returned when cancelling a job that already finished
+var ErrJobFinished = errors.New("job already finished")
+
+const (
+	// defaultJobRetention is used when no retention is configured
+	defaultJobRetention = time.Hour
+	// jobCleanupInterval is how often expired jobs are discarded
+	jobCleanupInterval = time.Minute
+)
+
+// JobManager runs bulk domain checks in the background and keeps their
+// progress and results available for polling
+type JobManager struct {
+	service   *DomainService
+	store     storage.JobStore
+	retention time.Duration
+	jobs      map[string]*job
+	closing   bool // Set on shutdown, so interrupted jobs stay resumable
+	listeners []func(models.Job)
+	resumed   map[string][]string // Domains left of interrupted jobs, run by Start
+	mutex     sync.RWMutex
+	stop      chan struct{}
+	wg        sync.WaitGroup
+}
+
+// job holds the state of one bulk check
+type job struct {
+	info     models.Job // Results are kept in the results slice instead
+	domains  []string
+	config   models.JobConfig
+	results  []models.JobResult
+	ctx      context.Context // Cancelled to stop the job
+	cancel   context.CancelFunc
+	watchers map[chan struct{}]bool
+	mutex    sync.RWMutex
+}
+
+// NewJobManager creates a new job manager, loads the jobs interrupted by
+// the last shutdown and starts discarding expired jobs. The interrupted jobs
+// run once Start is called.
+func NewJobManager(service *DomainService, cfg config.JobsConfig) (*JobManager, error) {
+	retention := cfg.Retention
+	if retention <= 0 {
+		retention = defaultJobRetention
+	}
+
+	store, err := storage.NewJobStore(cfg.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize job store: %w", err)
+	}
+
+	manager := &JobManager{
+		service:   service,
+		store:     store,
+		retention: retention,
+		jobs:      make(map[string]*job),
+		resumed:   make(map[string][]string),
+		stop:      make(chan struct{}),
+	}
+
+	if err := manager.resume(); err != nil {
+		store.Close()
+		return nil, err
+	}
+
+	manager.wg.Add(1)
+	go manager.cleanupLoop()
+
+	return manager, nil
+}
+
+// OnFinish registers a function that is called with every job, including
+// its results, once it completes or is cancelled. Register listeners before
+// Start so they also see the resumed jobs finish.
+func (m *JobManager) OnFinish(fn func(models.Job)) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.listeners = append(m.listeners, fn)
+}
+
+// Start runs the jobs interrupted by the last shutdown
+func (m *JobManager) Start() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.closing {
+		return
+	}
+	for id, domains := range m.resumed {
+		j := m.jobs[id]
+		log.Printf("Resuming job %s: %d of %d domains left", id, len(domains), j.info.Total)
+		m.wg.Add(1)
+		go m.run(j, domains)
+	}
+	m.resumed = nil
+}
+
+// SubmitCheckAllExtensions starts a job checking a name with the selected
+// extensions, every valid extension if the selection is empty
+func (m *JobManager) SubmitCheckAllExtensions(domainName string, selection models.ExtensionSelection) (*models.Job, error) {
+	domainName = utils.SanitizeDomain(domainName)
+	if domainName == "" {
+		return nil, fmt.Errorf("domain name is required")
+	}
+
+	domains, err := m.service.ExtensionDomains(domainName, selection)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.submit(models.Job{
+		Type:       models.JobTypeCheckAllExtensions,
+		DomainName: domainName,
+		Preset:     strings.ToLower(strings.TrimSpace(selection.Preset)),
+	}, domains)
+}
+
+// CheckAllExtensions runs a SubmitCheckAllExtensions job and waits for it,
+// returning its results in the shape of the synchronous bulk check. The job
+// is cancelled if ctx ends first.
+func (m *JobManager) CheckAllExtensions(ctx context.Context, domainName string, selection models.ExtensionSelection) (*models.AllExtensionsCheckResult, error) {
+	submitted, err := m.SubmitCheckAllExtensions(domainName, selection)
+	if err != nil {
+		return nil, err
+	}
+
+	updates, unwatch, err := m.Watch(submitted.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer unwatch()
+
+	for {
+		select {
+		case <-updates:
+			job, err := m.Get(submitted.ID, false)
+			if err != nil {
+				return nil, err
+			}
+			if !job.Status.IsFinished() {
+				continue
+			}
+
+			job, err = m.Get(submitted.ID, true)
+			if err != nil {
+				return nil, err
+			}
+			return m.service.jobCheckResult(job), nil
+		case <-ctx.Done():
+			if _, err := m.Cancel(submitted.ID); err != nil && !errors.Is(err, ErrJobFinished) {
+				log.Printf("Failed to cancel job %s: %v", submitted.ID, err)
+			}
+			return nil, ctx.Err()
+		case <-m.stop:
+			// Interrupted jobs stay unfinished until the next start
+			return nil, fmt.Errorf("job manager is shutting down")
+		}
+	}
+}
+
+// SubmitDomains starts a job checking a list of fully-qualified domains
+func (m *JobManager) SubmitDomains(domains []string) (*models.Job, error) {
+	if len(domains) == 0 {
+		return nil, fmt.Errorf("at least one domain is required")
+	}
+
+	return m.submit(models.Job{Type: models.JobTypeCheckDomains}, domains)
+}
+
+// submit registers a job described by info and starts running it
+func (m *JobManager) submit(info models.Job, domains []string) (*models.Job, error) {
+	id, err := newJobID()
+	if err != nil {
+		return nil, err
+	}
+
+	info.ID = id
+	info.Status = models.JobStatusQueued
+	info.Total = len(domains)
+	info.CreatedAt = time.Now()
+
+	domainCfg := m.service.cfg.Domain
+	j := newJob(info, domains, models.JobConfig{
+		TimeoutMs:           domainCfg.Timeout.Milliseconds(),
+		MaxConcurrentChecks: domainCfg.MaxConcurrentChecks,
+		Strategy:            domainCfg.Strategy,
+		TLDStrategies:       domainCfg.TLDStrategies,
+	})
+
+	m.mutex.Lock()
+	if m.closing {
+		m.mutex.Unlock()
+		return nil, fmt.Errorf("job manager is shutting down")
+	}
+	m.jobs[id] = j
+	m.wg.Add(1)
+	m.mutex.Unlock()
+
+	m.save(j)
+	go m.run(j, domains)
+
+	snapshot := j.snapshot(false)
+	return &snapshot, nil
+}
+
+// newJob creates the in-memory state of a job
+func newJob(info models.Job, domains []string, jobConfig models.JobConfig) *job {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &job{
+		info:     info,
+		domains:  domains,
+		config:   jobConfig,
+		results:  make([]models.JobResult, 0, len(domains)),
+		ctx:      ctx,
+		cancel:   cancel,
+		watchers: make(map[chan struct{}]bool),
+	}
+}
+
+// resume loads stored jobs, queueing unfinished ones for Start with the
+// domains that were not checked before the shutdown
+func (m *JobManager) resume() error {
+	states, err := m.store.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load jobs: %w", err)
+	}
+
+	now := time.Now()
+	for _, state := range states {
+		results := state.Job.Results
+		state.Job.Results = nil
+		state.Job.CheckedCount = 0
+		state.Job.AvailableCount = 0
+		state.Job.UnavailableCount = 0
+		state.Job.RateLimitedCount = 0
+		state.Job.ErrorCount = 0
+
+		if state.Job.Status.IsFinished() && state.Job.ExpiresAt != nil && now.After(*state.Job.ExpiresAt) {
+			if err := m.store.Delete(state.Job.ID); err != nil {
+				log.Printf("Failed to delete expired job %s: %v", state.Job.ID, err)
+			}
+			continue
+		}
+
+		j := newJob(state.Job, state.Domains, state.Config)
+		checked := make(map[string]bool, len(results))
+		for _, result := range results {
+			if checked[result.Domain] {
+				continue
+			}
+			checked[result.Domain] = true
+			j.results = append(j.results, result)
+			j.count(result)
+		}
+
+		m.jobs[j.info.ID] = j
+		if j.info.Status.IsFinished() {
+			j.cancel()
+			continue
+		}
+
+		var remaining []string
+		for _, domain := range state.Domains {
+			if !checked[domain] {
+				remaining = append(remaining, domain)
+			}
+		}
+
+		j.info.Status = models.JobStatusQueued
+		m.resumed[j.info.ID] = remaining
+	}
+
+	return nil
+}
+
+// run checks the given domains of a job with the concurrency it was submitted with
+func (m *JobManager) run(j *job, domains []string) {
+	defer m.wg.Done()
+	defer j.cancel()
+
+	ctx := j.ctx
+
+	j.mutex.Lock()
+	startedAt := time.Now()
+	j.info.Status = models.JobStatusRunning
+	if j.info.StartedAt == nil {
+		j.info.StartedAt = &startedAt
+	}
+	j.mutex.Unlock()
+	m.save(j)
+	j.notify()
+
+	domainCfg := &config.DomainConfig{
+		Timeout:             time.Duration(j.config.TimeoutMs) * time.Millisecond,
+		MaxConcurrentChecks: j.config.MaxConcurrentChecks,
+		Strategy:            j.config.Strategy,
+		TLDStrategies:       j.config.TLDStrategies,
+	}
+	if domainCfg.Timeout <= 0 {
+		domainCfg.Timeout = m.service.cfg.Domain.Timeout
+	}
+	if domainCfg.MaxConcurrentChecks <= 0 {
+		domainCfg.MaxConcurrentChecks = m.service.cfg.Domain.MaxConcurrentChecks
+	}
+
+	domainChan := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < domainCfg.MaxConcurrentChecks; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for domain := range domainChan {
+				result, err := m.service.checkDomain(ctx, domain, domainCfg)
+				if ctx.Err() != nil {
+					// Verdicts cut short by cancellation are not results
+					continue
+				}
+				m.record(j, domain, result, err)
+			}
+		}()
+	}
+
+feed:
+	for _, domain := range domains {
+		select {
+		case domainChan <- domain:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(domainChan)
+	wg.Wait()
+
+	if ctx.Err() != nil && m.isClosing() {
+		// Interrupted by shutdown: keep the stored job unfinished so it resumes
+		return
+	}
+
+	j.mutex.Lock()
+	finishedAt := time.Now()
+	expiresAt := finishedAt.Add(m.retention)
+	j.info.FinishedAt = &finishedAt
+	j.info.ExpiresAt = &expiresAt
+	if ctx.Err() != nil {
+		j.info.Status = models.JobStatusCancelled
+	} else {
+		j.info.Status = models.JobStatusCompleted
+	}
+	j.mutex.Unlock()
+	m.save(j)
+	j.notify()
+
+	m.mutex.RLock()
+	listeners := m.listeners
+	m.mutex.RUnlock()
+
+	if len(listeners) > 0 {
+		finished := j.snapshot(true)
+		for _, listener := range listeners {
+			listener(finished)
+		}
+	}
+}
+
+// record stores the outcome of one domain and checkpoints it
+func (m *JobManager) record(j *job, domain string, response *models.DomainCheckResponse, err error) {
+	result := models.JobResult{Domain: domain}
+	if err != nil {
+		result.Error = err.Error()
+	} else {
+		result.Result = response.Domain
+	}
+
+	if err := m.store.AppendResult(j.info.ID, result); err != nil {
+		log.Printf("Failed to checkpoint job %s: %v", j.info.ID, err)
+	}
+
+	j.mutex.Lock()
+	j.results = append(j.results, result)
+	j.count(result)
+	j.mutex.Unlock()
+
+	j.notify()
+}
+
+// save persists the job state
+func (m *JobManager) save(j *job) {
+	j.mutex.RLock()
+	state := &models.JobState{
+		Job:     j.info,
+		Domains: j.domains,
+		Config:  j.config,
+	}
+	j.mutex.RUnlock()
+
+	if err := m.store.Save(state); err != nil {
+		log.Printf("Failed to save job %s: %v", state.Job.ID, err)
+	}
+}
+
+// isClosing reports whether the manager is shutting down
+func (m *JobManager) isClosing() bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.closing
+}
+
+// Get returns a job, with its results if requested
+func (m *JobManager) Get(id string, withResults bool) (*models.Job, error) {
+	j, err := m.job(id)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := j.snapshot(withResults)
+	return &snapshot, nil
+}
+
+// List returns all retained jobs without their results, newest first
+func (m *JobManager) List() []models.Job {
+	m.mutex.RLock()
+	jobs := make([]models.Job, 0, len(m.jobs))
+	for _, j := range m.jobs {
+		jobs = append(jobs, j.snapshot(false))
+	}
+	m.mutex.RUnlock()
+
+	sortJobs(jobs)
+	return jobs
+}
+
+// Cancel stops a running job; results gathered so far are kept
+func (m *JobManager) Cancel(id string) (*models.Job, error) {
+	j, err := m.job(id)
+	if err != nil {
+		return nil, err
+	}
+
+	j.mutex.RLock()
+	finished := j.info.Status.IsFinished()
+	j.mutex.RUnlock()
+	if finished {
+		return nil, ErrJobFinished
+	}
+
+	j.cancel()
+	snapshot := j.snapshot(false)
+	return &snapshot, nil
+}
+
+// Watch returns a channel that receives a signal whenever the job makes progress,
+// and a function to stop watching. Signals are coalesced, so a watcher should
+// read everything new with ResultsSince after each signal.
+func (m *JobManager) Watch(id string) (<-chan struct{}, func(), error) {
+	j, err := m.job(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	updates := make(chan struct{}, 1)
+	updates <- struct{}{} // Report the current state right away
+
+	j.mutex.Lock()
+	j.watchers[updates] = true
+	j.mutex.Unlock()
+
+	unwatch := func() {
+		j.mutex.Lock()
+		delete(j.watchers, updates)
+		j.mutex.Unlock()
+	}
+	return updates, unwatch, nil
+}
+
+// ResultsSince returns the job state and the results recorded after the first offset ones
+func (m *JobManager) ResultsSince(id string, offset int) (*models.Job, []models.JobResult, error) {
+	j, err := m.job(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	j.mutex.RLock()
+	defer j.mutex.RUnlock()
+
+	snapshot := j.info
+	if offset > len(j.results) {
+		offset = len(j.results)
+	}
+	results := append([]models.JobResult{}, j.results[offset:]...)
+	return &snapshot, results, nil
+}
+
+// Close stops running jobs and waits for them. Stored jobs stay unfinished
+// and resume when the manager is created again.
+func (m *JobManager) Close() error {
+	close(m.stop)
+
+	m.mutex.Lock()
+	m.closing = true
+	for _, j := range m.jobs {
+		j.cancel()
+	}
+	m.mutex.Unlock()
+
+	m.wg.Wait()
+	return m.store.Close()
+}
+
+// job looks up a job by ID
+func (m *JobManager) job(id string) (*job, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	j, ok := m.jobs[id]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	return j, nil
+}
+
+// cleanupLoop periodically discards finished jobs past their retention
+func (m *JobManager) cleanupLoop() {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(jobCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stop:
+			return
+		case now := <-ticker.C:
+			var expired []string
+			m.mutex.Lock()
+			for id, j := range m.jobs {
+				j.mutex.RLock()
+				if j.info.ExpiresAt != nil && now.After(*j.info.ExpiresAt) {
+					expired = append(expired, id)
+					delete(m.jobs, id)
+				}
+				j.mutex.RUnlock()
+			}
+			m.mutex.Unlock()
+
+			// Stored files are removed without holding up other job calls
+			for _, id := range expired {
+				if err := m.store.Delete(id); err != nil {
+					log.Printf("Failed to delete expired job %s: %v", id, err)
+				}
+			}
+		}
+	}
+}
+
+// count updates the counters for one result; the caller holds the lock
+func (j *job) count(result models.JobResult) {
+	j.info.CheckedCount++
+	switch {
+	case result.Result == nil:
+		j.info.ErrorCount++
+	case result.Result.Status == models.LegacyStatusAvailable:
+		j.info.AvailableCount++
+	case result.Result.Availability == models.StatusRateLimited:
+		j.info.RateLimitedCount++
+	default:
+		j.info.UnavailableCount++
+	}
+}
+
+// notify signals every watcher without blocking
+func (j *job) notify() {
+	j.mutex.RLock()
+	defer j.mutex.RUnlock()
+
+	for watcher := range j.watchers {
+		select {
+		case watcher <- struct{}{}:
+		default:
+			// A signal is already pending
+		}
+	}
+}
+
+// snapshot returns a copy of the job state
+func (j *job) snapshot(withResults bool) models.Job {
+	j.mutex.RLock()
+	defer j.mutex.RUnlock()
+
+	snapshot := j.info
+	if withResults {
+		snapshot.Results = append([]models.JobResult{}, j.results...)
+	}
+	return snapshot
+}
+
+// jobCheckResult summarizes the results of a job in the shape of a synchronous bulk check
+func (s *DomainService) jobCheckResult(job *models.Job) *models.AllExtensionsCheckResult {
+	result := &models.AllExtensionsCheckResult{
+		DomainName:         job.DomainName,
+		Preset:             job.Preset,
+		TotalExtensions:    job.Total,
+		AllResults:         []models.DomainCheckResponse{},
+		AvailableDomains:   []models.DomainCheckResponse{},
+		UnavailableDomains: []models.DomainCheckResponse{},
+		Summary: models.ExtensionCheckSummary{
+			RecommendedDomains:     []string{},
+			PopularAvailable:       []string{},
+			AlternativeSuggestions: []string{},
+		},
+	}
+
+	for _, jobResult := range job.Results {
+		if jobResult.Result == nil {
+			result.ErrorCount++
+			continue
+		}
+
+		_, extension := utils.ExtractDomainParts(jobResult.Result.Name)
+		isValidTLD := s.IsValidExtension(extension)
+		item := models.DomainCheckResponse{
+			Domain:       jobResult.Result,
+			IsValidTLD:   isValidTLD,
+			SupportedTLD: isValidTLD,
+		}
+
+		result.AllResults = append(result.AllResults, item)
+		if item.Domain.Status == models.LegacyStatusAvailable {
+			result.AvailableCount++
+			result.AvailableDomains = append(result.AvailableDomains, item)
+			result.Summary.RecommendedDomains = append(result.Summary.RecommendedDomains, item.Domain.Name)
+		} else if item.Domain.Availability == models.StatusRateLimited {
+			result.RateLimitedCount++
+		} else {
+			result.UnavailableCount++
+			result.UnavailableDomains = append(result.UnavailableDomains, item)
+		}
+	}
+
+	result.Summary.PopularAvailable = s.popularAvailable(result.AvailableDomains)
+
+	if job.StartedAt != nil && job.FinishedAt != nil {
+		result.TotalTime = job.FinishedAt.Sub(*job.StartedAt).Milliseconds()
+	}
+	if job.DomainName != "" {
+		result.Summary.AlternativeSuggestions = s.generateAlternativeSuggestions(job.DomainName)
+	}
+
+	return result
+}
+
+// sortJobs orders jobs newest first
+func sortJobs(jobs []models.Job) {
+	sort.Slice(jobs, func(a, b int) bool {
+		return jobs[a].CreatedAt.After(jobs[b].CreatedAt)
+	})
+}
+
+// newJobID returns a random job identifier
+func newJobID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate job ID: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,486 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"domaincheck/internal/config"
+	"domaincheck/internal/models"
+	"domaincheck/internal/storage"
+	"domaincheck/internal/utils"
+)
+
+// ErrPortfolioDomainNotFound is returned for unknown portfolio domain IDs
+var ErrPortfolioDomainNotFound = storage.ErrPortfolioDomainNotFound
+
+// ErrPortfolioDomainExists is returned when adding a domain that is already in the portfolio
+var ErrPortfolioDomainExists = errors.New("domain is already in the portfolio")
+
+// ErrInvalidPortfolioDomain is returned for a portfolio request with an invalid domain
+var ErrInvalidPortfolioDomain = errors.New("invalid portfolio domain")
+
+const (
+	// defaultPortfolioRefreshInterval is used when no refresh interval is configured
+	defaultPortfolioRefreshInterval = 24 * time.Hour
+	// defaultPortfolioConcurrency is used when no concurrency limit is configured
+	defaultPortfolioConcurrency = 3
+	// portfolioMaxWait is the longest the scheduler sleeps before looking for due domains again
+	portfolioMaxWait = time.Minute
+	// portfolioRetryInterval is how soon a domain is looked up again after a failed lookup
+	portfolioRetryInterval = time.Hour
+)
+
+// defaultReminderDays are used when no reminder thresholds are configured
+var defaultReminderDays = []int{60, 30, 7, 1}
+
+// PortfolioManager tracks the expiration dates of owned domains and sends
+// renewal reminders as they approach
+type PortfolioManager struct {
+	service         *DomainService
+	store           storage.PortfolioStore
+	refreshInterval time.Duration
+	reminderDays    []int         // Descending
+	slots           chan struct{} // Limits concurrent lookups
+	refreshing      map[int]bool  // Domains with a scheduled lookup in flight
+	listeners       []func(models.PortfolioReminder)
+	mutex           sync.Mutex // Serializes domain updates
+	listenersMutex  sync.RWMutex
+	wake            chan struct{}
+	ctx             context.Context // Cancelled on shutdown
+	cancel          context.CancelFunc
+	wg              sync.WaitGroup
+}
+
+// NewPortfolioManager creates a new portfolio manager and starts its scheduler
+func NewPortfolioManager(service *DomainService, cfg config.PortfolioConfig) (*PortfolioManager, error) {
+	refreshInterval := cfg.RefreshInterval
+	if refreshInterval <= 0 {
+		refreshInterval = defaultPortfolioRefreshInterval
+	}
+	concurrency := cfg.MaxConcurrentChecks
+	if concurrency <= 0 {
+		concurrency = defaultPortfolioConcurrency
+	}
+
+	reminderDays := cfg.ReminderDays
+	if len(reminderDays) == 0 {
+		reminderDays = defaultReminderDays
+	}
+	reminderDays = append([]int(nil), reminderDays...)
+	sort.Sort(sort.Reverse(sort.IntSlice(reminderDays)))
+
+	store, err := storage.NewPortfolioStore(cfg.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize portfolio store: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	manager := &PortfolioManager{
+		service:         service,
+		store:           store,
+		refreshInterval: refreshInterval,
+		reminderDays:    reminderDays,
+		slots:           make(chan struct{}, concurrency),
+		refreshing:      make(map[int]bool),
+		wake:            make(chan struct{}, 1),
+		ctx:             ctx,
+		cancel:          cancel,
+	}
+
+	manager.wg.Add(1)
+	go manager.schedule()
+
+	return manager, nil
+}
+
+// OnReminder registers a function that is called for every renewal reminder
+func (m *PortfolioManager) OnReminder(fn func(models.PortfolioReminder)) {
+	m.listenersMutex.Lock()
+	defer m.listenersMutex.Unlock()
+
+	m.listeners = append(m.listeners, fn)
+}
+
+// Add marks a domain as owned; its expiration date is looked up right away
+func (m *PortfolioManager) Add(request models.PortfolioRequest) (*models.PortfolioDomain, error) {
+	name, err := utils.NormalizeDomain(request.Domain)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPortfolioDomain, err)
+	}
+	if !strings.Contains(name, ".") {
+		return nil, fmt.Errorf("%w: invalid domain format: %s", ErrInvalidPortfolioDomain, request.Domain)
+	}
+
+	// Track the registrable domain, e.g. "example.co.uk" for "shop.example.co.uk"
+	name = utils.SplitDomain(name).RegistrableDomain()
+	if name == "" {
+		return nil, fmt.Errorf("%w: %s is a public suffix", ErrInvalidPortfolioDomain, request.Domain)
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	domains, err := m.store.ListDomains()
+	if err != nil {
+		return nil, err
+	}
+	for _, domain := range domains {
+		if domain.Domain == name {
+			return nil, fmt.Errorf("%w: %s", ErrPortfolioDomainExists, name)
+		}
+	}
+
+	now := time.Now()
+	domain := &models.PortfolioDomain{
+		Domain:        name,
+		Note:          request.Note,
+		NextRefreshAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := m.store.SaveDomain(domain); err != nil {
+		return nil, err
+	}
+
+	m.signal()
+	return domain, nil
+}
+
+// List returns all owned domains
+func (m *PortfolioManager) List() ([]models.PortfolioDomain, error) {
+	domains, err := m.store.ListDomains()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for i := range domains {
+		setDaysLeft(&domains[i], now)
+	}
+	return domains, nil
+}
+
+// Expiring returns the owned domains that expire within the given period,
+// including those already expired, soonest first
+func (m *PortfolioManager) Expiring(within time.Duration) ([]models.PortfolioDomain, error) {
+	domains, err := m.store.ListDomains()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	cutoff := now.Add(within)
+	result := []models.PortfolioDomain{}
+	for _, domain := range domains {
+		if domain.ExpirationDate != nil && !domain.ExpirationDate.After(cutoff) {
+			setDaysLeft(&domain, now)
+			result = append(result, domain)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ExpirationDate.Before(*result[j].ExpirationDate)
+	})
+	return result, nil
+}
+
+// Get returns one owned domain
+func (m *PortfolioManager) Get(id int) (*models.PortfolioDomain, error) {
+	domain, err := m.store.GetDomain(id)
+	if err != nil {
+		return nil, err
+	}
+
+	setDaysLeft(domain, time.Now())
+	return domain, nil
+}
+
+// Update changes the note of an owned domain
+func (m *PortfolioManager) Update(id int, request models.PortfolioUpdateRequest) (*models.PortfolioDomain, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	domain, err := m.store.GetDomain(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Note != nil {
+		domain.Note = *request.Note
+	}
+	domain.UpdatedAt = time.Now()
+
+	if err := m.store.SaveDomain(domain); err != nil {
+		return nil, err
+	}
+
+	setDaysLeft(domain, domain.UpdatedAt)
+	return domain, nil
+}
+
+// Remove stops tracking an owned domain
+func (m *PortfolioManager) Remove(id int) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.store.DeleteDomain(id)
+}
+
+// RefreshNow looks up the expiration date of an owned domain immediately
+func (m *PortfolioManager) RefreshNow(ctx context.Context, id int) (*models.PortfolioDomain, error) {
+	domain, err := m.store.GetDomain(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.refresh(ctx, domain.ID, domain.Domain)
+}
+
+// Close stops the scheduler, waits for running lookups and closes the store
+func (m *PortfolioManager) Close() error {
+	m.cancel()
+	m.wg.Wait()
+	return m.store.Close()
+}
+
+// signal wakes the scheduler without blocking
+func (m *PortfolioManager) signal() {
+	select {
+	case m.wake <- struct{}{}:
+	default:
+		// A wake-up is already pending
+	}
+}
+
+// schedule starts the lookups of due domains and sleeps until the next one is due
+func (m *PortfolioManager) schedule() {
+	defer m.wg.Done()
+
+	for {
+		timer := time.NewTimer(m.dispatchDue())
+		select {
+		case <-m.ctx.Done():
+			timer.Stop()
+			return
+		case <-m.wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// dispatchDue starts a lookup for every due domain and returns how long
+// to wait until the next domain is due
+func (m *PortfolioManager) dispatchDue() time.Duration {
+	domains, err := m.store.ListDomains()
+	if err != nil {
+		log.Printf("Failed to load portfolio: %v", err)
+		return portfolioMaxWait
+	}
+
+	now := time.Now()
+	wait := portfolioMaxWait
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, domain := range domains {
+		if m.refreshing[domain.ID] {
+			continue
+		}
+
+		if until := domain.NextRefreshAt.Sub(now); until > 0 {
+			if until < wait {
+				wait = until
+			}
+			continue
+		}
+
+		m.refreshing[domain.ID] = true
+		m.wg.Add(1)
+		go func(id int, name string) {
+			defer m.wg.Done()
+
+			select {
+			case m.slots <- struct{}{}:
+			case <-m.ctx.Done():
+				return
+			}
+
+			if _, err := m.refresh(m.ctx, id, name); err != nil && !errors.Is(err, ErrPortfolioDomainNotFound) && m.ctx.Err() == nil {
+				log.Printf("Failed to refresh portfolio domain %s: %v", name, err)
+			}
+
+			<-m.slots
+			m.mutex.Lock()
+			delete(m.refreshing, id)
+			m.mutex.Unlock()
+			m.signal()
+		}(domain.ID, domain.Domain)
+	}
+
+	return wait
+}
+
+// refresh looks up the registration data of an owned domain and records it
+func (m *PortfolioManager) refresh(ctx context.Context, id int, name string) (*models.PortfolioDomain, error) {
+	whoisInfo, lookupErr := m.service.GetWhoisInfo(ctx, name)
+	if ctx.Err() != nil {
+		// Cut short by shutdown or a departed client, not a real outcome
+		return nil, ctx.Err()
+	}
+
+	domain, reminder, err := m.record(id, whoisInfo, lookupErr)
+	if err != nil {
+		return nil, err
+	}
+
+	if reminder != nil {
+		m.emit(*reminder)
+	}
+	return domain, nil
+}
+
+// record applies the outcome of a lookup to a domain and returns the
+// reminder it triggers, if any
+func (m *PortfolioManager) record(id int, whoisInfo *models.WhoisInfo, lookupErr error) (*models.PortfolioDomain, *models.PortfolioReminder, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	// Re-read the domain, it may have been changed or removed during the lookup
+	domain, err := m.store.GetDomain(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	domain.LastRefreshedAt = &now
+	domain.NextRefreshAt = now.Add(m.refreshInterval)
+
+	switch {
+	case lookupErr != nil:
+		// Keep the last known expiration date and try again sooner
+		domain.LastError = lookupErr.Error()
+		if retry := now.Add(portfolioRetryInterval); retry.Before(domain.NextRefreshAt) {
+			domain.NextRefreshAt = retry
+		}
+	case whoisInfo.ExpirationDate == nil:
+		domain.LastError = "registry did not publish an expiration date"
+		domain.Registrar = whoisInfo.Registrar
+	default:
+		domain.LastError = ""
+		domain.Registrar = whoisInfo.Registrar
+		domain.ExpirationDate = whoisInfo.ExpirationDate
+	}
+
+	reminder := m.remind(domain, now)
+
+	// Wake up when the next threshold is crossed; the lookup then also notices a renewal
+	if next := m.nextReminder(domain, now); !next.IsZero() && next.Before(domain.NextRefreshAt) {
+		domain.NextRefreshAt = next
+	}
+
+	if err := m.store.SaveDomain(domain); err != nil {
+		return nil, nil, err
+	}
+
+	setDaysLeft(domain, now)
+	if reminder != nil {
+		reminder.Domain = *domain
+	}
+	return domain, reminder, nil
+}
+
+// remind returns a reminder for the smallest threshold a domain has crossed
+// that it was not reminded of yet. Thresholds crossed together send one
+// reminder, and a renewal moves the expiration date and re-arms them all.
+func (m *PortfolioManager) remind(domain *models.PortfolioDomain, now time.Time) *models.PortfolioReminder {
+	if domain.ExpirationDate == nil {
+		return nil
+	}
+
+	expiresAt := *domain.ExpirationDate
+	if domain.RemindedFor == nil || !domain.RemindedFor.Equal(expiresAt) {
+		domain.RemindedFor = &expiresAt
+		domain.RemindedDays = 0
+	}
+	if !expiresAt.After(now) {
+		return nil
+	}
+
+	threshold := 0
+	for _, days := range m.reminderDays {
+		if expiresAt.Sub(now) <= reminderPeriod(days) && (domain.RemindedDays == 0 || days < domain.RemindedDays) {
+			threshold = days
+		}
+	}
+	if threshold == 0 {
+		return nil
+	}
+
+	domain.RemindedDays = threshold
+	return &models.PortfolioReminder{
+		ThresholdDays:  threshold,
+		DaysLeft:       daysUntil(expiresAt, now),
+		ExpirationDate: expiresAt,
+	}
+}
+
+// nextReminder returns when a domain crosses its next reminder threshold,
+// or the zero time if no reminder is left
+func (m *PortfolioManager) nextReminder(domain *models.PortfolioDomain, now time.Time) time.Time {
+	var next time.Time
+	if domain.ExpirationDate == nil {
+		return next
+	}
+
+	for _, days := range m.reminderDays {
+		if domain.RemindedDays != 0 && days >= domain.RemindedDays {
+			continue
+		}
+		at := domain.ExpirationDate.Add(-reminderPeriod(days))
+		if at.After(now) && (next.IsZero() || at.Before(next)) {
+			next = at
+		}
+	}
+	return next
+}
+
+// emit passes a reminder to every listener
+func (m *PortfolioManager) emit(reminder models.PortfolioReminder) {
+	log.Printf("Portfolio: %s expires in %d days", reminder.Domain.Domain, reminder.DaysLeft)
+
+	m.listenersMutex.RLock()
+	defer m.listenersMutex.RUnlock()
+
+	for _, listener := range m.listeners {
+		listener(reminder)
+	}
+}
+
+// setDaysLeft fills in the days until a domain's expiration
+func setDaysLeft(domain *models.PortfolioDomain, now time.Time) {
+	if domain.ExpirationDate == nil {
+		return
+	}
+
+	days := daysUntil(*domain.ExpirationDate, now)
+	domain.DaysLeft = &days
+}
+
+// daysUntil returns the whole days from now until t, negative once t has passed
+func daysUntil(t, now time.Time) int {
+	return int(math.Floor(t.Sub(now).Hours() / 24))
+}
+
+// reminderPeriod converts a reminder threshold in days to a duration
+func reminderPeriod(days int) time.Duration {
+	return time.Duration(days) * 24 * time.Hour
+}
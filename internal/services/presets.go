@@ -0,0 +1,365 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"domaincheck/internal/config"
+	"domaincheck/internal/models"
+	"domaincheck/internal/storage"
+	"domaincheck/internal/utils"
+)
+
+// ErrPresetNotFound is returned for an unknown extension preset name
+var ErrPresetNotFound = storage.ErrPresetNotFound
+
+// ErrPresetExists is returned when creating a preset whose name is taken
+var ErrPresetExists = errors.New("extension preset already exists")
+
+// ErrPresetReadOnly is returned when changing or deleting a preset defined in configuration
+var ErrPresetReadOnly = errors.New("extension preset is defined in configuration")
+
+// ErrInvalidPreset is returned for presets that cannot be saved
+var ErrInvalidPreset = errors.New("invalid extension preset")
+
+// ErrInvalidExtensionSelection is returned when the extensions of a bulk check cannot be selected
+var ErrInvalidExtensionSelection = errors.New("invalid extension selection")
+
+// popularExtensionRank is the popularity rank up to which available domains count as popular
+const popularExtensionRank = 20
+
+// presetNamePattern restricts preset names to identifiers that fit in a URL
+var presetNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// loadPresets converts the presets of the configuration and opens the store of custom presets
+func (s *DomainService) loadPresets(cfg config.ExtensionPresetsConfig) error {
+	presets := make(map[string]models.ExtensionPreset, len(cfg.Presets))
+	for name, presetCfg := range cfg.Presets {
+		filter := models.ExtensionFilter{
+			Type:          presetCfg.Types,
+			Category:      presetCfg.Categories,
+			IDN:           presetCfg.IDN,
+			Restricted:    presetCfg.Restricted,
+			MaxPopularity: presetCfg.MaxPopularity,
+		}
+		if presetCfg.MaxPrice > 0 {
+			maxPrice := presetCfg.MaxPrice
+			filter.MaxPrice = &maxPrice
+		}
+		if _, err := newExtensionMatcher(filter); err != nil {
+			return fmt.Errorf("extension preset %s: %w", name, err)
+		}
+
+		preset := models.ExtensionPreset{
+			Name:        strings.ToLower(name),
+			Description: presetCfg.Description,
+			Extensions:  presetCfg.Extensions,
+			BuiltIn:     true,
+		}
+		if !filter.IsEmpty() {
+			preset.Filter = &filter
+		}
+		presets[preset.Name] = preset
+	}
+	s.presets = presets
+
+	store, err := storage.NewPresetStore(cfg.Path)
+	if err != nil {
+		return fmt.Errorf("failed to initialize preset store: %w", err)
+	}
+	s.presetStore = store
+
+	return nil
+}
+
+// ListPresets returns the built-in and custom extension presets ordered by name
+func (s *DomainService) ListPresets() ([]models.ExtensionPreset, error) {
+	custom, err := s.presetStore.ListPresets()
+	if err != nil {
+		return nil, err
+	}
+
+	presets := make([]models.ExtensionPreset, 0, len(s.presets)+len(custom))
+	for _, preset := range s.presets {
+		presets = append(presets, preset)
+	}
+	presets = append(presets, custom...)
+	sort.Slice(presets, func(i, j int) bool {
+		return presets[i].Name < presets[j].Name
+	})
+
+	for i := range presets {
+		presets[i].ExtensionCount = len(s.resolvePreset(&presets[i]))
+	}
+	return presets, nil
+}
+
+// GetPreset returns an extension preset with the extensions it currently selects
+func (s *DomainService) GetPreset(name string) (*models.ExtensionPreset, error) {
+	preset, err := s.findPreset(name)
+	if err != nil {
+		return nil, err
+	}
+
+	preset.ResolvedExtensions = s.resolvePreset(preset)
+	preset.ExtensionCount = len(preset.ResolvedExtensions)
+	return preset, nil
+}
+
+// CreatePreset stores a custom extension preset
+func (s *DomainService) CreatePreset(request models.ExtensionPresetRequest) (*models.ExtensionPreset, error) {
+	name := strings.ToLower(strings.TrimSpace(request.Name))
+	if !presetNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("%w: name must be up to 64 lowercase letters, digits, '-' and '_'", ErrInvalidPreset)
+	}
+
+	now := time.Now()
+	preset := &models.ExtensionPreset{
+		Name:        name,
+		Description: strings.TrimSpace(request.Description),
+		Extensions:  request.Extensions,
+		Filter:      request.Filter,
+		CreatedAt:   &now,
+		UpdatedAt:   &now,
+	}
+	if err := s.validatePreset(preset); err != nil {
+		return nil, err
+	}
+
+	s.presetsMutex.Lock()
+	defer s.presetsMutex.Unlock()
+
+	if _, err := s.findPreset(name); err == nil {
+		return nil, fmt.Errorf("%w: %s", ErrPresetExists, name)
+	} else if !errors.Is(err, ErrPresetNotFound) {
+		return nil, err
+	}
+
+	if err := s.presetStore.SavePreset(preset); err != nil {
+		return nil, err
+	}
+	return s.GetPreset(name)
+}
+
+// UpdatePreset changes a custom extension preset
+func (s *DomainService) UpdatePreset(name string, request models.ExtensionPresetUpdateRequest) (*models.ExtensionPreset, error) {
+	s.presetsMutex.Lock()
+	defer s.presetsMutex.Unlock()
+
+	preset, err := s.customPreset(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Description != nil {
+		preset.Description = strings.TrimSpace(*request.Description)
+	}
+	if request.Extensions != nil {
+		preset.Extensions = request.Extensions
+	}
+	if request.Filter != nil {
+		preset.Filter = request.Filter
+	}
+	if err := s.validatePreset(preset); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	preset.UpdatedAt = &now
+	if err := s.presetStore.SavePreset(preset); err != nil {
+		return nil, err
+	}
+	return s.GetPreset(preset.Name)
+}
+
+// DeletePreset removes a custom extension preset
+func (s *DomainService) DeletePreset(name string) error {
+	s.presetsMutex.Lock()
+	defer s.presetsMutex.Unlock()
+
+	preset, err := s.customPreset(name)
+	if err != nil {
+		return err
+	}
+	return s.presetStore.DeletePreset(preset.Name)
+}
+
+// findPreset returns a built-in or custom preset by name
+func (s *DomainService) findPreset(name string) (*models.ExtensionPreset, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if preset, ok := s.presets[name]; ok {
+		return &preset, nil
+	}
+	return s.presetStore.GetPreset(name)
+}
+
+// customPreset returns a preset that may be changed via the API
+func (s *DomainService) customPreset(name string) (*models.ExtensionPreset, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if _, ok := s.presets[name]; ok {
+		return nil, fmt.Errorf("%w: %s", ErrPresetReadOnly, name)
+	}
+	return s.presetStore.GetPreset(name)
+}
+
+// validatePreset normalizes the extensions and filter of a custom preset
+func (s *DomainService) validatePreset(preset *models.ExtensionPreset) error {
+	extensions, err := s.normalizeExtensionList(preset.Extensions)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPreset, err)
+	}
+	preset.Extensions = extensions
+
+	if preset.Filter != nil && preset.Filter.IsEmpty() {
+		preset.Filter = nil
+	}
+	if preset.Filter != nil {
+		if _, err := newExtensionMatcher(*preset.Filter); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidPreset, err)
+		}
+	}
+
+	if len(preset.Extensions) == 0 && preset.Filter == nil {
+		return fmt.Errorf("%w: a preset needs extensions, a filter or both", ErrInvalidPreset)
+	}
+	return nil
+}
+
+// normalizeExtensionList converts extensions to the form they are loaded in and
+// drops duplicates; extensions that are not loaded are rejected
+func (s *DomainService) normalizeExtensionList(extensions []string) ([]string, error) {
+	seen := make(map[string]bool, len(extensions))
+	result := make([]string, 0, len(extensions))
+	for _, extension := range extensions {
+		normalized := utils.NormalizeExtension(extension)
+		if normalized == "" || !s.IsValidExtension(normalized) {
+			return nil, fmt.Errorf("unsupported extension %q", extension)
+		}
+		if !seen[normalized] {
+			seen[normalized] = true
+			result = append(result, normalized)
+		}
+	}
+	return result, nil
+}
+
+// resolvePreset returns the loaded extensions a preset selects, sorted.
+// Listed extensions that are no longer loaded are left out.
+func (s *DomainService) resolvePreset(preset *models.ExtensionPreset) []string {
+	var matcher *extensionMatcher
+	if preset.Filter != nil {
+		matcher, _ = newExtensionMatcher(*preset.Filter)
+	}
+
+	s.extensionsMutex.RLock()
+	selected := make(map[string]bool)
+	for _, extension := range preset.Extensions {
+		extension = utils.NormalizeExtension(extension)
+		if _, ok := s.extensions[extension]; ok {
+			selected[extension] = true
+		}
+	}
+	if matcher != nil {
+		for extension, info := range s.extensions {
+			if matcher.matches(info) {
+				selected[extension] = true
+			}
+		}
+	}
+	s.extensionsMutex.RUnlock()
+
+	return sortedExtensions(selected)
+}
+
+// SelectExtensions returns the sorted extensions a bulk check runs with
+func (s *DomainService) SelectExtensions(selection models.ExtensionSelection) ([]string, error) {
+	if selection.Preset != "" && len(selection.Extensions) > 0 {
+		return nil, fmt.Errorf("%w: preset and extensions cannot be combined", ErrInvalidExtensionSelection)
+	}
+
+	selected := make(map[string]bool)
+	switch {
+	case selection.Preset != "":
+		preset, err := s.findPreset(selection.Preset)
+		if errors.Is(err, ErrPresetNotFound) {
+			return nil, fmt.Errorf("%w: unknown preset %q", ErrInvalidExtensionSelection, selection.Preset)
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, extension := range s.resolvePreset(preset) {
+			selected[extension] = true
+		}
+	case len(selection.Extensions) > 0:
+		extensions, err := s.normalizeExtensionList(selection.Extensions)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidExtensionSelection, err)
+		}
+		for _, extension := range extensions {
+			selected[extension] = true
+		}
+	default:
+		for _, extension := range s.GetValidExtensions() {
+			selected[extension] = true
+		}
+	}
+
+	include, err := s.normalizeExtensionList(selection.Include)
+	if err != nil {
+		return nil, fmt.Errorf("%w: include: %v", ErrInvalidExtensionSelection, err)
+	}
+	for _, extension := range include {
+		selected[extension] = true
+	}
+	for _, extension := range selection.Exclude {
+		delete(selected, utils.NormalizeExtension(extension))
+	}
+
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("%w: no extensions selected", ErrInvalidExtensionSelection)
+	}
+	return sortedExtensions(selected), nil
+}
+
+// popularAvailable returns the available domains whose extension ranks among
+// the most popular ones, most popular first
+func (s *DomainService) popularAvailable(available []models.DomainCheckResponse) []string {
+	type rankedDomain struct {
+		name string
+		rank int
+	}
+
+	s.extensionsMutex.RLock()
+	ranked := make([]rankedDomain, 0, len(available))
+	for _, item := range available {
+		info, ok := s.extensions[item.Domain.Extension]
+		if ok && info.Popularity > 0 && info.Popularity <= popularExtensionRank {
+			ranked = append(ranked, rankedDomain{name: item.Domain.Name, rank: info.Popularity})
+		}
+	}
+	s.extensionsMutex.RUnlock()
+
+	sort.Slice(ranked, func(i, j int) bool {
+		return ranked[i].rank < ranked[j].rank
+	})
+
+	names := make([]string, len(ranked))
+	for i, domain := range ranked {
+		names[i] = domain.name
+	}
+	return names
+}
+
+// sortedExtensions returns the keys of an extension set in order
+func sortedExtensions(set map[string]bool) []string {
+	extensions := make([]string, 0, len(set))
+	for extension := range set {
+		extensions = append(extensions, extension)
+	}
+	sort.Strings(extensions)
+	return extensions
+}
@@ -0,0 +1,346 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"domaincheck/internal/config"
+	"domaincheck/internal/models"
+)
+
+// ErrRateLimited is returned when a query is held back by a rate limit or
+// refused by a server that signals rate limiting
+var ErrRateLimited = errors.New("rate limited")
+
+const (
+	// defaultRateLimitMaxWait is used when no maximum wait is configured
+	defaultRateLimitMaxWait = 10 * time.Second
+	// defaultRateLimitInitialBackoff is used when no initial backoff is configured
+	defaultRateLimitInitialBackoff = 30 * time.Second
+	// defaultRateLimitMaxBackoff is used when no maximum backoff is configured
+	defaultRateLimitMaxBackoff = 10 * time.Minute
+)
+
+// rateLimiter keeps a token bucket per TLD, per WHOIS/RDAP server and per
+// resolver, and backs off servers that signal rate limiting. A nil limiter
+// allows every query.
+type rateLimiter struct {
+	enabled        bool
+	scopes         map[string]config.RateLimitScope
+	maxWait        time.Duration
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	buckets        map[string]*tokenBucket // scope + "|" + key
+	mutex          sync.Mutex
+}
+
+// tokenBucket limits the queries sent to one TLD, server or resolver
+type tokenBucket struct {
+	scope         string
+	key           string
+	rate          float64 // Tokens added per second; zero means unlimited
+	burst         float64
+	tokens        float64 // Negative while queries wait for tokens
+	updated       time.Time
+	backoff       time.Duration // Current backoff; zero while the server cooperates
+	backoffUntil  time.Time
+	requests      uint64
+	delayed       uint64
+	rejected      uint64
+	throttled     uint64
+	lastThrottled time.Time
+}
+
+// newRateLimiter creates a rate limiter from configuration
+func newRateLimiter(cfg config.RateLimitConfig) *rateLimiter {
+	limiter := &rateLimiter{
+		enabled: cfg.Enabled,
+		scopes: map[string]config.RateLimitScope{
+			models.RateLimitScopeTLD:      normalizeRateLimitScope(models.RateLimitScopeTLD, cfg.TLDs),
+			models.RateLimitScopeServer:   normalizeRateLimitScope(models.RateLimitScopeServer, cfg.Servers),
+			models.RateLimitScopeResolver: normalizeRateLimitScope(models.RateLimitScopeResolver, cfg.Resolvers),
+		},
+		maxWait:        cfg.MaxWait,
+		initialBackoff: cfg.InitialBackoff,
+		maxBackoff:     cfg.MaxBackoff,
+		buckets:        make(map[string]*tokenBucket),
+	}
+	if limiter.maxWait <= 0 {
+		limiter.maxWait = defaultRateLimitMaxWait
+	}
+	if limiter.initialBackoff <= 0 {
+		limiter.initialBackoff = defaultRateLimitInitialBackoff
+	}
+	if limiter.maxBackoff <= 0 {
+		limiter.maxBackoff = defaultRateLimitMaxBackoff
+	}
+	if limiter.maxBackoff < limiter.initialBackoff {
+		limiter.maxBackoff = limiter.initialBackoff
+	}
+	return limiter
+}
+
+// normalizeRateLimitScope normalizes the override keys of a scope
+func normalizeRateLimitScope(scope string, cfg config.RateLimitScope) config.RateLimitScope {
+	overrides := make(map[string]config.RateLimit, len(cfg.Overrides))
+	for key, limit := range cfg.Overrides {
+		overrides[rateLimitKey(scope, key)] = limit
+	}
+	return config.RateLimitScope{Default: cfg.Default, Overrides: overrides}
+}
+
+// rateLimitKey normalizes a TLD ("com" and ".com" are the same), host or resolver address
+func rateLimitKey(scope, key string) string {
+	key = strings.ToLower(key)
+	if scope == models.RateLimitScopeTLD {
+		key = strings.TrimPrefix(key, ".")
+	}
+	return key
+}
+
+// bucket returns the token bucket of a key, creating it full; the caller holds the lock
+func (l *rateLimiter) bucket(scope, key string, now time.Time) *tokenBucket {
+	key = rateLimitKey(scope, key)
+	if b, ok := l.buckets[scope+"|"+key]; ok {
+		return b
+	}
+
+	limit, ok := l.scopes[scope].Overrides[key]
+	if !ok {
+		limit = l.scopes[scope].Default
+	}
+
+	b := &tokenBucket{scope: scope, key: key, updated: now}
+	if l.enabled && limit.Rate > 0 {
+		b.rate = limit.Rate
+		b.burst = float64(limit.Burst)
+		if b.burst < 1 {
+			b.burst = math.Max(1, math.Ceil(limit.Rate))
+		}
+		b.tokens = b.burst
+	}
+	l.buckets[scope+"|"+key] = b
+	return b
+}
+
+// refill adds the tokens earned since the last update
+func (b *tokenBucket) refill(now time.Time) {
+	if b.rate > 0 && now.After(b.updated) {
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.updated).Seconds()*b.rate)
+	}
+	b.updated = now
+}
+
+// delay returns how long a query has to wait for its turn
+func (b *tokenBucket) delay(now time.Time) time.Duration {
+	var delay time.Duration
+	if now.Before(b.backoffUntil) {
+		delay = b.backoffUntil.Sub(now)
+	}
+	if b.rate > 0 && b.tokens < 1 {
+		if tokenDelay := time.Duration((1 - b.tokens) / b.rate * float64(time.Second)); tokenDelay > delay {
+			delay = tokenDelay
+		}
+	}
+	return delay
+}
+
+// take consumes a token
+func (b *tokenBucket) take() {
+	b.requests++
+	if b.rate > 0 {
+		b.tokens--
+	}
+}
+
+// wait blocks until a query to the key may be sent. It returns an error
+// wrapping ErrRateLimited without waiting if that would take longer than the
+// maximum wait or outlast the context's deadline.
+func (l *rateLimiter) wait(ctx context.Context, scope, key string) error {
+	if l == nil {
+		return nil
+	}
+
+	now := time.Now()
+
+	l.mutex.Lock()
+	b := l.bucket(scope, key, now)
+	b.refill(now)
+	delay := b.delay(now)
+
+	deadline, hasDeadline := ctx.Deadline()
+	if delay > l.maxWait || (hasDeadline && now.Add(delay).After(deadline)) {
+		b.rejected++
+		l.mutex.Unlock()
+		return fmt.Errorf("%w: %s %s allows the next query in %s", ErrRateLimited, scope, b.key, delay.Round(time.Millisecond))
+	}
+
+	b.take()
+	if delay == 0 {
+		l.mutex.Unlock()
+		return nil
+	}
+	b.delayed++
+	l.mutex.Unlock()
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// Hand the token back to the queries still waiting
+		l.mutex.Lock()
+		if b.rate > 0 {
+			b.tokens++
+		}
+		l.mutex.Unlock()
+		return ctx.Err()
+	}
+}
+
+// allow takes a token if a query to the key may be sent right away
+func (l *rateLimiter) allow(scope, key string) bool {
+	if l == nil {
+		return true
+	}
+
+	now := time.Now()
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	b := l.bucket(scope, key, now)
+	b.refill(now)
+	if b.delay(now) > 0 {
+		return false
+	}
+	b.take()
+	return true
+}
+
+// backoff leaves a server alone after it signalled rate limiting. The backoff
+// doubles every time the server does so again after the previous one ended,
+// and is at least as long as the server asked for.
+func (l *rateLimiter) backoff(scope, key string, retryAfter time.Duration) {
+	if l == nil {
+		return
+	}
+
+	now := time.Now()
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	b := l.bucket(scope, key, now)
+	b.throttled++
+	b.lastThrottled = now
+
+	// Queries sent before the backoff started do not make it longer
+	if now.Before(b.backoffUntil) && retryAfter <= b.backoffUntil.Sub(now) {
+		return
+	}
+
+	switch {
+	case b.backoff == 0:
+		b.backoff = l.initialBackoff
+	case now.After(b.backoffUntil):
+		b.backoff *= 2
+	}
+	if b.backoff > l.maxBackoff {
+		b.backoff = l.maxBackoff
+	}
+
+	wait := b.backoff
+	if retryAfter > wait {
+		wait = retryAfter
+	}
+	b.backoffUntil = now.Add(wait)
+
+	log.Printf("Backing off %s %s for %s after it signalled rate limiting", scope, b.key, wait)
+}
+
+// succeed resets the backoff of a server that answered normally
+func (l *rateLimiter) succeed(scope, key string) {
+	if l == nil {
+		return
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if b, ok := l.buckets[scope+"|"+rateLimitKey(scope, key)]; ok && !time.Now().Before(b.backoffUntil) {
+		b.backoff = 0
+	}
+}
+
+// status returns a snapshot of every token bucket
+func (l *rateLimiter) status() []models.RateLimitStatus {
+	if l == nil {
+		return []models.RateLimitStatus{}
+	}
+
+	now := time.Now()
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	statuses := make([]models.RateLimitStatus, 0, len(l.buckets))
+	for _, b := range l.buckets {
+		b.refill(now)
+		status := models.RateLimitStatus{
+			Scope:     b.scope,
+			Key:       b.key,
+			Rate:      b.rate,
+			Burst:     int(b.burst),
+			Tokens:    b.tokens,
+			Requests:  b.requests,
+			Delayed:   b.delayed,
+			Rejected:  b.rejected,
+			Throttled: b.throttled,
+		}
+		if now.Before(b.backoffUntil) {
+			backoffUntil := b.backoffUntil
+			status.BackoffUntil = &backoffUntil
+		}
+		if !b.lastThrottled.IsZero() {
+			lastThrottled := b.lastThrottled
+			status.LastThrottledAt = &lastThrottled
+		}
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Scope != statuses[j].Scope {
+			return statuses[i].Scope < statuses[j].Scope
+		}
+		return statuses[i].Key < statuses[j].Key
+	})
+	return statuses
+}
+
+// parseRetryAfter returns the wait a Retry-After header asks for, given in
+// seconds or as an HTTP date, or zero if it is missing or malformed
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
@@ -0,0 +1,332 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"domaincheck/internal/models"
+)
+
+// ErrRDAPNotFound is returned when the RDAP server has no record of the domain
+var ErrRDAPNotFound = errors.New("domain not found in RDAP")
+
+// RDAPStatusError is returned when an RDAP server answers with an unexpected HTTP status
+type RDAPStatusError struct {
+	StatusCode int
+}
+
+// Error describes the answer
+func (e *RDAPStatusError) Error() string {
+	return fmt.Sprintf("RDAP server returned status %d", e.StatusCode)
+}
+
+// maxRDAPResponseSize limits how much of an RDAP response is read
+const maxRDAPResponseSize = 1 << 20
+
+// RDAPClient queries registry RDAP servers resolved from the IANA bootstrap file
+type RDAPClient struct {
+	bootstrapFile string
+	httpClient    *http.Client
+	servers       map[string]string // TLD without dot -> RDAP base URL
+	mutex         sync.RWMutex
+	limiter       *rateLimiter
+}
+
+// rdapBootstrap represents the IANA RDAP bootstrap file (RFC 9224)
+type rdapBootstrap struct {
+	Version     string       `json:"version"`
+	Publication string       `json:"publication"`
+	Services    [][][]string `json:"services"`
+}
+
+// rdapDomain represents the subset of an RDAP domain object we use (RFC 9083)
+type rdapDomain struct {
+	ObjectClassName string           `json:"objectClassName"`
+	LDHName         string           `json:"ldhName"`
+	Status          []string         `json:"status"`
+	Events          []rdapEvent      `json:"events"`
+	Entities        []rdapEntity     `json:"entities"`
+	Nameservers     []rdapNameserver `json:"nameservers"`
+}
+
+// rdapEvent represents an RDAP event such as registration or expiration
+type rdapEvent struct {
+	EventAction string `json:"eventAction"`
+	EventDate   string `json:"eventDate"`
+}
+
+// rdapEntity represents an RDAP entity (registrar, registrant, contacts)
+type rdapEntity struct {
+	Roles      []string      `json:"roles"`
+	VCardArray []interface{} `json:"vcardArray"`
+	Entities   []rdapEntity  `json:"entities"`
+}
+
+// rdapNameserver represents an RDAP nameserver object
+type rdapNameserver struct {
+	LDHName string `json:"ldhName"`
+}
+
+// NewRDAPClient creates a new RDAP client and loads the bootstrap file
+func NewRDAPClient(bootstrapFile string) (*RDAPClient, error) {
+	client := &RDAPClient{
+		bootstrapFile: bootstrapFile,
+		httpClient:    &http.Client{},
+		servers:       make(map[string]string),
+	}
+
+	if bootstrapFile != "" {
+		if err := client.LoadBootstrap(); err != nil {
+			return nil, err
+		}
+	}
+
+	return client, nil
+}
+
+// LoadBootstrap (re)loads the TLD to RDAP server mapping from the bootstrap file
+func (c *RDAPClient) LoadBootstrap() error {
+	data, err := os.ReadFile(c.bootstrapFile)
+	if err != nil {
+		return fmt.Errorf("failed to read RDAP bootstrap file: %w", err)
+	}
+
+	var bootstrap rdapBootstrap
+	if err := json.Unmarshal(data, &bootstrap); err != nil {
+		return fmt.Errorf("failed to parse RDAP bootstrap file: %w", err)
+	}
+
+	servers := make(map[string]string)
+	for _, service := range bootstrap.Services {
+		if len(service) < 2 || len(service[1]) == 0 {
+			continue
+		}
+
+		// Prefer HTTPS endpoints when several are listed
+		baseURL := service[1][0]
+		for _, url := range service[1] {
+			if strings.HasPrefix(url, "https://") {
+				baseURL = url
+				break
+			}
+		}
+		if !strings.HasSuffix(baseURL, "/") {
+			baseURL += "/"
+		}
+
+		for _, tld := range service[0] {
+			servers[strings.ToLower(tld)] = baseURL
+		}
+	}
+
+	c.mutex.Lock()
+	c.servers = servers
+	c.mutex.Unlock()
+
+	return nil
+}
+
+// SetServer overrides the RDAP base URL used for a TLD
+func (c *RDAPClient) SetServer(tld, baseURL string) {
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.servers[strings.ToLower(strings.TrimPrefix(tld, "."))] = baseURL
+}
+
+// BaseURL returns the RDAP base URL responsible for a TLD
+func (c *RDAPClient) BaseURL(tld string) (string, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	baseURL, ok := c.servers[strings.ToLower(strings.TrimPrefix(tld, "."))]
+	return baseURL, ok
+}
+
+// LookupDomain fetches the RDAP domain object and maps it into WHOIS information.
+// It returns ErrRDAPNotFound if the registry reports that the domain does not exist
+// and ErrCheckerNotApplicable if no RDAP server is known for the domain's TLD.
+// Errors wrapping ErrRateLimited mean the server asked us to slow down.
+func (c *RDAPClient) LookupDomain(ctx context.Context, domain string) (*models.WhoisInfo, error) {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+
+	lastDot := strings.LastIndex(domain, ".")
+	if lastDot == -1 {
+		return nil, fmt.Errorf("domain must have an extension")
+	}
+
+	baseURL, ok := c.BaseURL(domain[lastDot+1:])
+	if !ok {
+		return nil, ErrCheckerNotApplicable
+	}
+
+	host := baseURL
+	if parsed, err := url.Parse(baseURL); err == nil && parsed.Host != "" {
+		host = parsed.Host
+	}
+	if err := c.limiter.wait(ctx, models.RateLimitScopeServer, host); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"domain/"+domain, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create RDAP request: %w", err)
+	}
+	req.Header.Set("Accept", "application/rdap+json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("RDAP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		c.limiter.backoff(models.RateLimitScopeServer, host, parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()))
+		return nil, fmt.Errorf("%w: RDAP server %s returned status 429", ErrRateLimited, host)
+	}
+	// Only a proper answer ends a backoff; failing servers keep it growing
+	if resp.StatusCode/100 == 2 || resp.StatusCode == http.StatusNotFound {
+		c.limiter.succeed(models.RateLimitScopeServer, host)
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrRDAPNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &RDAPStatusError{StatusCode: resp.StatusCode}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRDAPResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read RDAP response: %w", err)
+	}
+
+	var object rdapDomain
+	if err := json.Unmarshal(body, &object); err != nil {
+		return nil, fmt.Errorf("failed to parse RDAP response: %w", err)
+	}
+
+	whoisInfo := &models.WhoisInfo{
+		Domain:    domain,
+		Status:    object.Status,
+		RawData:   string(body),
+		CheckedAt: time.Now(),
+	}
+
+	for _, event := range object.Events {
+		switch event.EventAction {
+		case "registration":
+			whoisInfo.CreationDate = parseRegistryDate(event.EventDate)
+		case "expiration":
+			whoisInfo.ExpirationDate = parseRegistryDate(event.EventDate)
+		case "last changed":
+			whoisInfo.UpdatedDate = parseRegistryDate(event.EventDate)
+		}
+	}
+
+	whoisInfo.Registrar = findRDAPEntityName(object.Entities, "registrar")
+	whoisInfo.AdminContact = findRDAPEntityName(object.Entities, "administrative")
+	whoisInfo.TechContact = findRDAPEntityName(object.Entities, "technical")
+
+	for _, ns := range object.Nameservers {
+		if ns.LDHName != "" {
+			whoisInfo.NameServers = append(whoisInfo.NameServers, strings.ToLower(ns.LDHName))
+		}
+	}
+
+	return whoisInfo, nil
+}
+
+// findRDAPEntityName returns the formatted name of the first entity with the given role
+func findRDAPEntityName(entities []rdapEntity, role string) string {
+	for _, entity := range entities {
+		for _, r := range entity.Roles {
+			if r == role {
+				if name := vcardFormattedName(entity.VCardArray); name != "" {
+					return name
+				}
+			}
+		}
+		if name := findRDAPEntityName(entity.Entities, role); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// vcardFormattedName extracts the "fn" property from a jCard (RFC 7095)
+func vcardFormattedName(vcard []interface{}) string {
+	if len(vcard) < 2 {
+		return ""
+	}
+
+	properties, ok := vcard[1].([]interface{})
+	if !ok {
+		return ""
+	}
+
+	for _, p := range properties {
+		property, ok := p.([]interface{})
+		if !ok || len(property) < 4 {
+			continue
+		}
+		if name, _ := property[0].(string); name == "fn" {
+			value, _ := property[3].(string)
+			return value
+		}
+	}
+	return ""
+}
+
+// RDAPChecker checks availability by querying the registry's RDAP server
+type RDAPChecker struct {
+	client *RDAPClient
+}
+
+// NewRDAPChecker creates a new RDAP checker
+func NewRDAPChecker(client *RDAPClient) *RDAPChecker {
+	return &RDAPChecker{client: client}
+}
+
+// Name returns the checker name
+func (c *RDAPChecker) Name() string {
+	return "rdap"
+}
+
+// Check treats a registry 404 as an authoritative "Available" verdict
+// and maps the registry status codes of existing domains
+func (c *RDAPChecker) Check(ctx context.Context, domain string) (*CheckResult, error) {
+	whoisInfo, err := c.client.LookupDomain(ctx, domain)
+	if errors.Is(err, ErrRDAPNotFound) {
+		return &CheckResult{
+			Availability: models.StatusAvailable,
+			Confidence:   models.ConfidenceHigh,
+			Evidence:     []string{"registry RDAP server returned 404"},
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CheckResult{
+		Availability: availabilityFromEPPStatus(whoisInfo.Status),
+		Confidence:   models.ConfidenceHigh,
+		Evidence:     []string{"registry RDAP server returned the domain object"},
+	}
+	if len(whoisInfo.Status) > 0 {
+		result.Evidence = append(result.Evidence, "registry status: "+strings.Join(whoisInfo.Status, ", "))
+	}
+	return result, nil
+}
@@ -0,0 +1,305 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"domaincheck/internal/config"
+	"domaincheck/internal/models"
+)
+
+// rdapStandIn is a local RDAP server answering with a fixed status and body
+type rdapStandIn struct {
+	server     *httptest.Server
+	status     atomic.Int32
+	body       atomic.Value // string
+	retryAfter atomic.Value // string
+	requests   atomic.Int32
+	lastPath   atomic.Value // string
+}
+
+func newRDAPStandIn(t *testing.T) *rdapStandIn {
+	t.Helper()
+
+	s := &rdapStandIn{}
+	s.status.Store(http.StatusOK)
+	s.body.Store("")
+	s.retryAfter.Store("")
+	s.lastPath.Store("")
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests.Add(1)
+		s.lastPath.Store(r.URL.Path)
+		if retryAfter := s.retryAfter.Load().(string); retryAfter != "" {
+			w.Header().Set("Retry-After", retryAfter)
+		}
+		w.Header().Set("Content-Type", "application/rdap+json")
+		w.WriteHeader(int(s.status.Load()))
+		w.Write([]byte(s.body.Load().(string)))
+	}))
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+// respond sets the next answers of the stand-in
+func (s *rdapStandIn) respond(status int, body string) {
+	s.status.Store(int32(status))
+	s.body.Store(body)
+}
+
+// host is the rate limit key of the stand-in
+func (s *rdapStandIn) host() string {
+	parsed, _ := url.Parse(s.server.URL)
+	return parsed.Host
+}
+
+// newTestRDAPClient returns a client sending ".test" lookups to the stand-in
+func newTestRDAPClient(t *testing.T, standIn *rdapStandIn, limits config.RateLimitConfig) *RDAPClient {
+	t.Helper()
+
+	client, err := NewRDAPClient("")
+	if err != nil {
+		t.Fatalf("NewRDAPClient: %v", err)
+	}
+	client.SetServer(".test", standIn.server.URL)
+	client.limiter = newRateLimiter(limits)
+	return client
+}
+
+const rdapDomainObject = `{
+  "objectClassName": "domain",
+  "ldhName": "EXAMPLE.TEST",
+  "status": ["client transfer prohibited", "server hold"],
+  "events": [
+    {"eventAction": "registration", "eventDate": "2001-02-03T04:05:06Z"},
+    {"eventAction": "expiration", "eventDate": "2031-02-03T04:05:06Z"},
+    {"eventAction": "last changed", "eventDate": "2024-05-06T07:08:09Z"}
+  ],
+  "entities": [
+    {
+      "roles": ["registrar"],
+      "vcardArray": ["vcard", [["version", {}, "text", "4.0"], ["fn", {}, "text", "Example Registrar, Inc."]]],
+      "entities": [
+        {"roles": ["technical"], "vcardArray": ["vcard", [["fn", {}, "text", "Registrar Tech"]]]}
+      ]
+    },
+    {"roles": ["administrative"], "vcardArray": ["vcard", [["fn", {}, "text", "Domain Admin"]]]}
+  ],
+  "nameservers": [
+    {"objectClassName": "nameserver", "ldhName": "NS1.EXAMPLE.TEST"},
+    {"objectClassName": "nameserver", "ldhName": "ns2.example.test"}
+  ]
+}`
+
+func TestRDAPCheckerNotFoundIsAvailable(t *testing.T) {
+	standIn := newRDAPStandIn(t)
+	standIn.respond(http.StatusNotFound, `{"errorCode": 404}`)
+	checker := NewRDAPChecker(newTestRDAPClient(t, standIn, config.RateLimitConfig{}))
+
+	result, err := checker.Check(context.Background(), "Example.TEST")
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if result.Availability != models.StatusAvailable {
+		t.Errorf("availability = %q, want %q", result.Availability, models.StatusAvailable)
+	}
+	if result.Confidence != models.ConfidenceHigh {
+		t.Errorf("confidence = %q, want %q", result.Confidence, models.ConfidenceHigh)
+	}
+	if path := standIn.lastPath.Load().(string); path != "/domain/example.test" {
+		t.Errorf("requested %q, want /domain/example.test", path)
+	}
+}
+
+func TestRDAPCheckerMapsEPPStatus(t *testing.T) {
+	tests := []struct {
+		status string
+		want   models.AvailabilityStatus
+	}{
+		{`["active"]`, models.StatusRegistered},
+		{`["client transfer prohibited"]`, models.StatusRegistered},
+		{`["redemption period"]`, models.StatusRedemption},
+		{`["pending delete", "redemption period"]`, models.StatusRedemption},
+		{`["pendingDelete"]`, models.StatusPendingDelete},
+		{`["server hold"]`, models.StatusServerHold},
+		{`[]`, models.StatusRegistered},
+	}
+
+	standIn := newRDAPStandIn(t)
+	checker := NewRDAPChecker(newTestRDAPClient(t, standIn, config.RateLimitConfig{}))
+
+	for _, tt := range tests {
+		standIn.respond(http.StatusOK, `{"objectClassName": "domain", "ldhName": "example.test", "status": `+tt.status+`}`)
+
+		result, err := checker.Check(context.Background(), "example.test")
+		if err != nil {
+			t.Fatalf("status %s: Check: %v", tt.status, err)
+		}
+		if result.Availability != tt.want {
+			t.Errorf("status %s: availability = %q, want %q", tt.status, result.Availability, tt.want)
+		}
+	}
+}
+
+func TestRDAPLookupDomainParsesObject(t *testing.T) {
+	standIn := newRDAPStandIn(t)
+	standIn.respond(http.StatusOK, rdapDomainObject)
+	client := newTestRDAPClient(t, standIn, config.RateLimitConfig{})
+
+	info, err := client.LookupDomain(context.Background(), "example.test")
+	if err != nil {
+		t.Fatalf("LookupDomain: %v", err)
+	}
+
+	dates := []struct {
+		name string
+		got  *time.Time
+		want string
+	}{
+		{"creation", info.CreationDate, "2001-02-03T04:05:06Z"},
+		{"expiration", info.ExpirationDate, "2031-02-03T04:05:06Z"},
+		{"updated", info.UpdatedDate, "2024-05-06T07:08:09Z"},
+	}
+	for _, date := range dates {
+		want, _ := time.Parse(time.RFC3339, date.want)
+		if date.got == nil || !date.got.Equal(want) {
+			t.Errorf("%s date = %v, want %s", date.name, date.got, date.want)
+		}
+	}
+
+	if info.Registrar != "Example Registrar, Inc." {
+		t.Errorf("registrar = %q", info.Registrar)
+	}
+	if info.AdminContact != "Domain Admin" {
+		t.Errorf("admin contact = %q", info.AdminContact)
+	}
+	if info.TechContact != "Registrar Tech" {
+		t.Errorf("tech contact = %q, want the nested entity", info.TechContact)
+	}
+	if len(info.NameServers) != 2 || info.NameServers[0] != "ns1.example.test" || info.NameServers[1] != "ns2.example.test" {
+		t.Errorf("name servers = %v", info.NameServers)
+	}
+	if len(info.Status) != 2 || info.Status[1] != "server hold" {
+		t.Errorf("status = %v", info.Status)
+	}
+}
+
+func TestRDAPLookupDomainUnknownTLD(t *testing.T) {
+	standIn := newRDAPStandIn(t)
+	client := newTestRDAPClient(t, standIn, config.RateLimitConfig{})
+
+	if _, err := client.LookupDomain(context.Background(), "example.nowhere"); !errors.Is(err, ErrCheckerNotApplicable) {
+		t.Errorf("err = %v, want ErrCheckerNotApplicable", err)
+	}
+	if standIn.requests.Load() != 0 {
+		t.Errorf("stand-in was queried for a TLD it does not serve")
+	}
+}
+
+func TestRDAPLookupDomainServerError(t *testing.T) {
+	standIn := newRDAPStandIn(t)
+	standIn.respond(http.StatusInternalServerError, "")
+	client := newTestRDAPClient(t, standIn, config.RateLimitConfig{})
+
+	_, err := client.LookupDomain(context.Background(), "example.test")
+	var statusErr *RDAPStatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusInternalServerError {
+		t.Errorf("err = %v, want RDAPStatusError with status 500", err)
+	}
+}
+
+func TestRDAPLookupDomainRetryAfterBacksOff(t *testing.T) {
+	standIn := newRDAPStandIn(t)
+	standIn.respond(http.StatusTooManyRequests, "")
+	standIn.retryAfter.Store("120")
+	client := newTestRDAPClient(t, standIn, config.RateLimitConfig{
+		MaxWait:        10 * time.Millisecond,
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Minute,
+	})
+
+	_, err := client.LookupDomain(context.Background(), "example.test")
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("err = %v, want ErrRateLimited", err)
+	}
+
+	// Retry-After asks for longer than the backoff would, so it wins
+	status := findRateLimitStatus(client.limiter, models.RateLimitScopeServer, standIn.host())
+	if status == nil || status.BackoffUntil == nil {
+		t.Fatalf("server is not backed off: %+v", status)
+	}
+	if remaining := time.Until(*status.BackoffUntil); remaining < 110*time.Second {
+		t.Errorf("backoff ends in %s, want about 120s", remaining)
+	}
+
+	// The next lookup is refused locally instead of hitting the server again
+	standIn.respond(http.StatusOK, rdapDomainObject)
+	if _, err := client.LookupDomain(context.Background(), "example.test"); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("err = %v, want ErrRateLimited while backed off", err)
+	}
+	if requests := standIn.requests.Load(); requests != 1 {
+		t.Errorf("server received %d requests, want 1", requests)
+	}
+}
+
+func TestRDAPLookupDomainServerErrorKeepsBackoff(t *testing.T) {
+	const initialBackoff = 20 * time.Millisecond
+
+	standIn := newRDAPStandIn(t)
+	client := newTestRDAPClient(t, standIn, config.RateLimitConfig{
+		MaxWait:        time.Second,
+		InitialBackoff: initialBackoff,
+		MaxBackoff:     time.Minute,
+	})
+	host := standIn.host()
+	bucketBackoff := func() time.Duration {
+		client.limiter.mutex.Lock()
+		defer client.limiter.mutex.Unlock()
+		return client.limiter.buckets[models.RateLimitScopeServer+"|"+host].backoff
+	}
+
+	standIn.respond(http.StatusTooManyRequests, "")
+	client.LookupDomain(context.Background(), "example.test")
+	if got := bucketBackoff(); got != initialBackoff {
+		t.Fatalf("backoff after 429 = %s, want %s", got, initialBackoff)
+	}
+
+	// A failing server must not reset the backoff once it has ended
+	time.Sleep(2 * initialBackoff)
+	standIn.respond(http.StatusServiceUnavailable, "")
+	client.LookupDomain(context.Background(), "example.test")
+	if got := bucketBackoff(); got != initialBackoff {
+		t.Fatalf("backoff after 503 = %s, want %s", got, initialBackoff)
+	}
+
+	// Another 429 doubles it
+	time.Sleep(2 * initialBackoff)
+	standIn.respond(http.StatusTooManyRequests, "")
+	client.LookupDomain(context.Background(), "example.test")
+	if got := bucketBackoff(); got != 2*initialBackoff {
+		t.Fatalf("backoff after second 429 = %s, want %s", got, 2*initialBackoff)
+	}
+
+	// A proper answer resets it
+	time.Sleep(3 * initialBackoff)
+	standIn.respond(http.StatusNotFound, "")
+	client.LookupDomain(context.Background(), "example.test")
+	if got := bucketBackoff(); got != 0 {
+		t.Errorf("backoff after 404 = %s, want 0", got)
+	}
+}
+
+// findRateLimitStatus returns the status of one token bucket
+func findRateLimitStatus(limiter *rateLimiter, scope, key string) *models.RateLimitStatus {
+	for _, status := range limiter.status() {
+		if status.Scope == scope && status.Key == key {
+			return &status
+		}
+	}
+	return nil
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"domaincheck/internal/config"
+	"domaincheck/internal/models"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const (
+	// healthSmoothing is the weight of the newest sample in the moving averages
+	healthSmoothing = 0.2
+	// defaultMaxFailureRate ejects resolvers failing more than half of their queries
+	defaultMaxFailureRate = 0.5
+	// defaultMinSamples is the number of queries observed before a resolver can be ejected
+	defaultMinSamples = 5
+	// defaultEjectionDuration is how long an unhealthy resolver is kept out of rotation
+	defaultEjectionDuration = 30 * time.Second
+	// defaultDNSTimeout limits a DNS query when no resolver timeout is configured
+	defaultDNSTimeout = 2 * time.Second
+)
+
+// defaultUpstreams is used when no resolvers are configured
+var defaultUpstreams = []config.UpstreamConfig{
+	{Address: "8.8.8.8:53", Protocol: ProtocolUDP},        // Google
+	{Address: "1.1.1.1:53", Protocol: ProtocolUDP},        // Cloudflare
+	{Address: "208.67.222.222:53", Protocol: ProtocolUDP}, // OpenDNS
+}
+
+// poolResolver tracks the health of a single upstream
+type poolResolver struct {
+	upstream     Upstream
+	queries      int64
+	failures     int64
+	samples      int
+	failureRate  float64 // exponential moving average
+	latency      float64 // exponential moving average in milliseconds
+	ejectedUntil time.Time
+	lastError    string
+	lastUsed     time.Time
+}
+
+// ResolverPool spreads recursive queries across upstream resolvers, preferring
+// fast healthy ones and temporarily ejecting resolvers that keep failing
+type ResolverPool struct {
+	client           *DNSClient
+	resolvers        []*poolResolver
+	maxFailureRate   float64
+	minSamples       int
+	ejectionDuration time.Duration
+	limiter          *rateLimiter
+	mutex            sync.Mutex
+}
+
+// NewResolverPool creates a resolver pool from configuration
+func NewResolverPool(cfg config.ResolverConfig) *ResolverPool {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultDNSTimeout
+	}
+
+	pool := &ResolverPool{
+		client:           NewDNSClient(timeout),
+		maxFailureRate:   cfg.MaxFailureRate,
+		minSamples:       cfg.MinSamples,
+		ejectionDuration: cfg.EjectionDuration,
+	}
+	if pool.maxFailureRate <= 0 {
+		pool.maxFailureRate = defaultMaxFailureRate
+	}
+	if pool.minSamples <= 0 {
+		pool.minSamples = defaultMinSamples
+	}
+	if pool.ejectionDuration <= 0 {
+		pool.ejectionDuration = defaultEjectionDuration
+	}
+
+	upstreams := cfg.Upstreams
+	if len(upstreams) == 0 {
+		upstreams = defaultUpstreams
+	}
+	for _, u := range upstreams {
+		pool.resolvers = append(pool.resolvers, &poolResolver{
+			upstream: Upstream{
+				Address:    u.Address,
+				Protocol:   u.Protocol,
+				ServerName: u.ServerName,
+			},
+		})
+	}
+
+	return pool
+}
+
+// Query sends a recursive query, failing over between resolvers until one answers.
+// NXDOMAIN is a valid answer; SERVFAIL and REFUSED count as resolver failures.
+// It returns the response and the address of the resolver that produced it.
+// Resolvers at their rate limit are skipped; if no other resolver answers,
+// the query waits for the first of them.
+func (p *ResolverPool) Query(ctx context.Context, name string, qtype dnsmessage.Type) (*dnsmessage.Message, string, error) {
+	var lastErr error
+	var throttled []*poolResolver
+	for _, resolver := range p.candidates() {
+		if !p.limiter.allow(models.RateLimitScopeResolver, resolver.upstream.Address) {
+			throttled = append(throttled, resolver)
+			continue
+		}
+
+		response, err := p.query(ctx, resolver, name, qtype)
+		if err == nil {
+			return response, resolver.upstream.Address, nil
+		}
+		lastErr = err
+
+		// If context is cancelled or timed out, don't try other resolvers
+		if ctx.Err() != nil {
+			return nil, "", lastErr
+		}
+	}
+
+	if len(throttled) > 0 {
+		resolver := throttled[0]
+		if err := p.limiter.wait(ctx, models.RateLimitScopeResolver, resolver.upstream.Address); err != nil {
+			if lastErr == nil {
+				lastErr = err
+			}
+			return nil, "", lastErr
+		}
+
+		response, err := p.query(ctx, resolver, name, qtype)
+		if err == nil {
+			return response, resolver.upstream.Address, nil
+		}
+		lastErr = err
+	}
+
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no resolvers configured")
+	}
+	return nil, "", lastErr
+}
+
+// query sends a recursive query to one resolver and records the outcome,
+// unless the caller's context ended first
+func (p *ResolverPool) query(ctx context.Context, resolver *poolResolver, name string, qtype dnsmessage.Type) (*dnsmessage.Message, error) {
+	start := time.Now()
+	response, err := p.client.QueryUpstream(ctx, resolver.upstream, name, qtype, true)
+	if err == nil && response.RCode != dnsmessage.RCodeSuccess && response.RCode != dnsmessage.RCodeNameError {
+		err = &RCodeError{Server: resolver.upstream.Address, RCode: response.RCode}
+	}
+	// Queries cut short by the caller say nothing about the resolver's health
+	if ctx.Err() == nil {
+		p.record(resolver, time.Since(start), err)
+	}
+	return response, err
+}
+
+// candidates returns resolvers in the order they should be tried: healthy ones
+// by latency, then ejected ones by how soon their ejection ends
+func (p *ResolverPool) candidates() []*poolResolver {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	now := time.Now()
+	var healthy, ejected []*poolResolver
+	for _, r := range p.resolvers {
+		if now.Before(r.ejectedUntil) {
+			ejected = append(ejected, r)
+			continue
+		}
+		if !r.ejectedUntil.IsZero() {
+			// Ejection expired: give the resolver a fresh start
+			r.ejectedUntil = time.Time{}
+			r.samples = 0
+			r.failureRate = 0
+		}
+		healthy = append(healthy, r)
+	}
+
+	sort.SliceStable(healthy, func(i, j int) bool {
+		return healthy[i].latency < healthy[j].latency
+	})
+	sort.SliceStable(ejected, func(i, j int) bool {
+		return ejected[i].ejectedUntil.Before(ejected[j].ejectedUntil)
+	})
+
+	return append(healthy, ejected...)
+}
+
+// record updates a resolver's health after a query
+func (p *ResolverPool) record(r *poolResolver, latency time.Duration, err error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	r.queries++
+	r.samples++
+	r.lastUsed = time.Now()
+
+	failure := 0.0
+	if err != nil {
+		failure = 1
+		r.failures++
+		r.lastError = err.Error()
+	} else {
+		ms := float64(latency) / float64(time.Millisecond)
+		if r.latency == 0 {
+			r.latency = ms
+		} else {
+			r.latency = (1-healthSmoothing)*r.latency + healthSmoothing*ms
+		}
+	}
+
+	if r.samples == 1 {
+		r.failureRate = failure
+	} else {
+		r.failureRate = (1-healthSmoothing)*r.failureRate + healthSmoothing*failure
+	}
+
+	if r.samples >= p.minSamples && r.failureRate > p.maxFailureRate {
+		r.ejectedUntil = time.Now().Add(p.ejectionDuration)
+	}
+}
+
+// Status returns a snapshot of every resolver's health
+func (p *ResolverPool) Status() []models.ResolverStatus {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	now := time.Now()
+	statuses := make([]models.ResolverStatus, 0, len(p.resolvers))
+	for _, r := range p.resolvers {
+		status := models.ResolverStatus{
+			Address:     r.upstream.Address,
+			Protocol:    r.upstream.Protocol,
+			Healthy:     !now.Before(r.ejectedUntil),
+			Queries:     r.queries,
+			Failures:    r.failures,
+			FailureRate: r.failureRate,
+			LatencyMs:   r.latency,
+			LastError:   r.lastError,
+		}
+		if status.Protocol == "" {
+			status.Protocol = ProtocolUDP
+		}
+		if !status.Healthy {
+			ejectedUntil := r.ejectedUntil
+			status.EjectedUntil = &ejectedUntil
+		}
+		if !r.lastUsed.IsZero() {
+			lastUsed := r.lastUsed
+			status.LastUsed = &lastUsed
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
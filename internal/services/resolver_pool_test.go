@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"domaincheck/internal/config"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// newTestResolverPool returns a pool with a single upstream
+func newTestResolverPool(address string, timeout time.Duration) *ResolverPool {
+	pool := NewResolverPool(config.ResolverConfig{
+		Timeout:   timeout,
+		Upstreams: []config.UpstreamConfig{{Address: address, Protocol: ProtocolUDP}},
+	})
+	pool.limiter = newRateLimiter(config.RateLimitConfig{})
+	return pool
+}
+
+func TestResolverPoolRecordsFailures(t *testing.T) {
+	standIn := newDNSStandIn(t, func(q dnsmessage.Question, network string) dnsmessage.Message {
+		return dnsmessage.Message{Header: dnsmessage.Header{RCode: dnsmessage.RCodeServerFailure}}
+	})
+	pool := newTestResolverPool(standIn.addr, time.Second)
+
+	if _, _, err := pool.Query(context.Background(), "example.test", dnsmessage.TypeA); err == nil {
+		t.Fatal("Query succeeded, want a SERVFAIL error")
+	}
+
+	status := pool.Status()[0]
+	if status.Queries != 1 || status.Failures != 1 {
+		t.Errorf("queries = %d, failures = %d; want 1 and 1", status.Queries, status.Failures)
+	}
+}
+
+func TestResolverPoolIgnoresCancelledQueries(t *testing.T) {
+	// A UDP socket that never answers
+	silent, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen udp: %v", err)
+	}
+	defer silent.Close()
+
+	pool := newTestResolverPool(silent.LocalAddr().String(), 300*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	if _, _, err := pool.Query(ctx, "example.test", dnsmessage.TypeA); err == nil {
+		t.Fatal("Query succeeded, want a cancellation error")
+	}
+
+	status := pool.Status()[0]
+	if status.Queries != 0 || status.Failures != 0 || !status.Healthy {
+		t.Errorf("cancelled query was recorded: %+v", status)
+	}
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	"domaincheck/internal/config"
+	"domaincheck/internal/models"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const (
+	// defaultRetryMaxAttempts is used when no number of attempts is configured
+	defaultRetryMaxAttempts = 3
+	// defaultRetryInitialBackoff is used when no initial backoff is configured
+	defaultRetryInitialBackoff = 100 * time.Millisecond
+	// defaultRetryMaxBackoff is used when no maximum backoff is configured
+	defaultRetryMaxBackoff = time.Second
+)
+
+// transientErrorClasses are retried unless configured otherwise; other
+// failures are unlikely to go away by asking again
+var transientErrorClasses = map[string]bool{
+	models.ErrorClassTimeout:           true,
+	models.ErrorClassServFail:          true,
+	models.ErrorClassConnectionRefused: true,
+	models.ErrorClassNetwork:           true,
+	models.ErrorClassServerError:       true,
+}
+
+// retryPolicy decides whether and when a failed checker attempt is repeated
+type retryPolicy struct {
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	jitter         float64
+	classes        map[string]int // Error class -> attempts
+}
+
+// newRetryPolicy creates a retry policy from configuration
+func newRetryPolicy(cfg config.RetryConfig) *retryPolicy {
+	policy := &retryPolicy{
+		maxAttempts:    cfg.MaxAttempts,
+		initialBackoff: cfg.InitialBackoff,
+		maxBackoff:     cfg.MaxBackoff,
+		jitter:         cfg.Jitter,
+		classes:        make(map[string]int, len(cfg.Classes)),
+	}
+	if policy.maxAttempts <= 0 {
+		policy.maxAttempts = defaultRetryMaxAttempts
+	}
+	if policy.initialBackoff <= 0 {
+		policy.initialBackoff = defaultRetryInitialBackoff
+	}
+	if policy.maxBackoff <= 0 {
+		policy.maxBackoff = defaultRetryMaxBackoff
+	}
+	for class, attempts := range cfg.Classes {
+		policy.classes[strings.ToLower(class)] = attempts
+	}
+	return policy
+}
+
+// attempts returns how often a checker is tried for failures of an error class
+func (p *retryPolicy) attempts(class string) int {
+	if attempts, ok := p.classes[class]; ok {
+		return attempts
+	}
+	if transientErrorClasses[class] {
+		return p.maxAttempts
+	}
+	return 1
+}
+
+// next returns how long to wait before repeating an attempt that failed with
+// an error of the given class, or false if it is not repeated. Attempts are not
+// repeated once the wait would outlast the context's deadline.
+func (p *retryPolicy) next(ctx context.Context, class string, attempt int) (time.Duration, bool) {
+	if attempt >= p.attempts(class) || ctx.Err() != nil {
+		return 0, false
+	}
+
+	wait := p.initialBackoff
+	for i := 1; i < attempt && wait < p.maxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > p.maxBackoff {
+		wait = p.maxBackoff
+	}
+	wait -= time.Duration(rand.Float64() * p.jitter * float64(wait))
+
+	if deadline, ok := ctx.Deadline(); ok && time.Now().Add(wait).After(deadline) {
+		return 0, false
+	}
+	return wait, true
+}
+
+// sleep waits for the given duration and reports false if the context ended first
+func (p *retryPolicy) sleep(ctx context.Context, wait time.Duration) bool {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// classifyError sorts a lookup failure into an error class
+func classifyError(err error) string {
+	var rcodeErr *RCodeError
+	var statusErr *RDAPStatusError
+	var netErr net.Error
+
+	switch {
+	case errors.Is(err, ErrRateLimited):
+		return models.ErrorClassRateLimited
+	case errors.As(err, &rcodeErr):
+		switch rcodeErr.RCode {
+		case dnsmessage.RCodeServerFailure:
+			return models.ErrorClassServFail
+		case dnsmessage.RCodeRefused:
+			return models.ErrorClassRefused
+		default:
+			return models.ErrorClassOther
+		}
+	case errors.As(err, &statusErr):
+		if statusErr.StatusCode >= 500 {
+			return models.ErrorClassServerError
+		}
+		return models.ErrorClassOther
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return models.ErrorClassConnectionRefused
+	case errors.Is(err, context.DeadlineExceeded):
+		return models.ErrorClassTimeout
+	case errors.As(err, &netErr):
+		if netErr.Timeout() {
+			return models.ErrorClassTimeout
+		}
+		return models.ErrorClassNetwork
+	default:
+		return models.ErrorClassOther
+	}
+}
+
+// attemptsFailed reports whether any attempt in a log failed
+func attemptsFailed(attempts []models.CheckAttempt) bool {
+	for _, attempt := range attempts {
+		if attempt.Error != "" {
+			return true
+		}
+	}
+	return false
+}
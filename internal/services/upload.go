@@ -0,0 +1,175 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"domaincheck/internal/models"
+	"domaincheck/internal/utils"
+)
+
+// ErrTooManyDomains is returned when an uploaded list exceeds the configured limit
+var ErrTooManyDomains = errors.New("too many domains in list")
+
+// defaultMaxUploadDomains is used when no upload limit is configured
+const defaultMaxUploadDomains = 500000
+
+// DomainList is a parsed, deduplicated list of domains
+type DomainList struct {
+	Domains        []string
+	Lines          map[string]int // First line each domain appeared on
+	Invalid        []models.BulkCheckItem
+	TotalLines     int
+	DuplicateCount int
+}
+
+// ParseDomainList reads one domain per line, or the first column of each CSV
+// record. Blank lines and lines starting with "#" are skipped, as is a CSV
+// header row. Domains are sanitized, validated and deduplicated.
+func (s *DomainService) ParseDomainList(r io.Reader, isCSV bool) (*DomainList, error) {
+	limit := s.cfg.Domain.MaxUploadDomains
+	if limit <= 0 {
+		limit = defaultMaxUploadDomains
+	}
+
+	list := &DomainList{Lines: make(map[string]int)}
+
+	add := func(line int, value string) error {
+		value = strings.TrimSpace(value)
+		if value == "" || strings.HasPrefix(value, "#") {
+			return nil
+		}
+
+		domain, err := utils.NormalizeDomain(value)
+		if err == nil && !strings.Contains(domain, ".") {
+			err = fmt.Errorf("invalid domain format")
+		}
+		if err != nil {
+			list.Invalid = append(list.Invalid, models.BulkCheckItem{
+				Type:   models.BulkLineInvalid,
+				Line:   line,
+				Domain: value,
+				Error:  err.Error(),
+			})
+			return nil
+		}
+
+		if _, seen := list.Lines[domain]; seen {
+			list.DuplicateCount++
+			return nil
+		}
+		if len(list.Domains) >= limit {
+			return fmt.Errorf("%w: the limit is %d", ErrTooManyDomains, limit)
+		}
+		list.Lines[domain] = line
+		list.Domains = append(list.Domains, domain)
+		return nil
+	}
+
+	if isCSV {
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		reader.ReuseRecord = true
+		for {
+			record, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to read CSV: %w", err)
+			}
+
+			list.TotalLines++
+			line, _ := reader.FieldPos(0)
+			if len(record) == 0 || (line == 1 && isDomainHeader(record[0])) {
+				continue
+			}
+			if err := add(line, record[0]); err != nil {
+				return nil, err
+			}
+		}
+		return list, nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		list.TotalLines++
+		if err := add(list.TotalLines, scanner.Text()); err != nil {
+			return nil, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read domain list: %w", err)
+	}
+
+	return list, nil
+}
+
+// isDomainHeader reports whether a CSV cell is a column title rather than a domain
+func isDomainHeader(cell string) bool {
+	switch strings.ToLower(strings.TrimSpace(strings.TrimPrefix(cell, "\ufeff"))) {
+	case "domain", "domains", "name", "domain_name", "fqdn":
+		return true
+	default:
+		return false
+	}
+}
+
+// CheckDomainsStream checks domains with the worker pool and passes each
+// outcome to fn as soon as it completes. fn is never called concurrently.
+// Checking stops when ctx is cancelled; the domains checked so far are reported.
+func (s *DomainService) CheckDomainsStream(ctx context.Context, domains []string, fn func(domain string, result *models.DomainCheckResponse, err error)) {
+	type outcome struct {
+		domain string
+		result *models.DomainCheckResponse
+		err    error
+	}
+
+	domainChan := make(chan string)
+	outcomes := make(chan outcome)
+
+	// Start workers
+	var wg sync.WaitGroup
+	for i := 0; i < s.cfg.Domain.MaxConcurrentChecks; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for domain := range domainChan {
+				result, err := s.CheckDomain(ctx, domain)
+				if ctx.Err() != nil {
+					// Verdicts cut short by cancellation are not results
+					continue
+				}
+				outcomes <- outcome{domain: domain, result: result, err: err}
+			}
+		}()
+	}
+
+	// Send domains to workers
+	go func() {
+		defer close(domainChan)
+		for _, domain := range domains {
+			select {
+			case domainChan <- domain:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// Close outcome channel when all workers are done
+	go func() {
+		wg.Wait()
+		close(outcomes)
+	}()
+
+	for o := range outcomes {
+		fn(o.domain, o.result, o.err)
+	}
+}
@@ -0,0 +1,500 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"domaincheck/internal/config"
+	"domaincheck/internal/models"
+	"domaincheck/internal/storage"
+	"domaincheck/internal/utils"
+)
+
+// ErrWatchEntryNotFound is returned for unknown watch entry IDs
+var ErrWatchEntryNotFound = storage.ErrWatchEntryNotFound
+
+// ErrWatchEntryExists is returned when watching a domain that is already watched
+var ErrWatchEntryExists = errors.New("domain is already watched")
+
+// ErrInvalidWatchEntry is returned for a watch request with an invalid domain or interval
+var ErrInvalidWatchEntry = errors.New("invalid watch entry")
+
+const (
+	// defaultWatchInterval is used when no default interval is configured
+	defaultWatchInterval = 6 * time.Hour
+	// defaultWatchConcurrency is used when no concurrency limit is configured
+	defaultWatchConcurrency = 5
+	// watchMaxWait is the longest the scheduler sleeps before looking for due entries again
+	watchMaxWait = time.Minute
+	// watchRetryInterval is how soon an entry is re-checked after a check reached no verdict
+	watchRetryInterval = 5 * time.Minute
+	// defaultExpiryWarning is used when no expiry warning period is configured
+	defaultExpiryWarning = 30 * 24 * time.Hour
+	// defaultTransitionLimit is how many transitions are listed unless asked otherwise
+	defaultTransitionLimit = 100
+)
+
+// WatchlistManager re-checks watched domains on their own schedules,
+// records their status transitions and emits events for domains that drop
+type WatchlistManager struct {
+	service         *DomainService
+	store           storage.WatchlistStore
+	defaultInterval time.Duration
+	minInterval     time.Duration
+	expiryWarning   time.Duration
+	slots           chan struct{} // Limits concurrent checks
+	checking        map[int]bool  // Entries with a scheduled check in flight
+	listeners       []func(models.WatchEvent)
+	mutex           sync.Mutex // Serializes entry updates
+	listenersMutex  sync.RWMutex
+	wake            chan struct{}
+	ctx             context.Context // Cancelled on shutdown
+	cancel          context.CancelFunc
+	wg              sync.WaitGroup
+}
+
+// NewWatchlistManager creates a new watchlist manager and starts its scheduler
+func NewWatchlistManager(service *DomainService, cfg config.WatchlistConfig) (*WatchlistManager, error) {
+	defaultInterval := cfg.DefaultInterval
+	if defaultInterval <= 0 {
+		defaultInterval = defaultWatchInterval
+	}
+	expiryWarning := cfg.ExpiryWarning
+	if expiryWarning <= 0 {
+		expiryWarning = defaultExpiryWarning
+	}
+	concurrency := cfg.MaxConcurrentChecks
+	if concurrency <= 0 {
+		concurrency = defaultWatchConcurrency
+	}
+
+	store, err := storage.NewWatchlistStore(cfg.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize watchlist store: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	manager := &WatchlistManager{
+		service:         service,
+		store:           store,
+		defaultInterval: defaultInterval,
+		minInterval:     cfg.MinInterval,
+		expiryWarning:   expiryWarning,
+		slots:           make(chan struct{}, concurrency),
+		checking:        make(map[int]bool),
+		wake:            make(chan struct{}, 1),
+		ctx:             ctx,
+		cancel:          cancel,
+	}
+
+	manager.wg.Add(1)
+	go manager.schedule()
+
+	return manager, nil
+}
+
+// OnEvent registers a function that is called for every watchlist event
+func (m *WatchlistManager) OnEvent(fn func(models.WatchEvent)) {
+	m.listenersMutex.Lock()
+	defer m.listenersMutex.Unlock()
+
+	m.listeners = append(m.listeners, fn)
+}
+
+// Add starts watching a domain; its first check runs right away
+func (m *WatchlistManager) Add(request models.WatchRequest) (*models.WatchEntry, error) {
+	domain, err := utils.NormalizeDomain(request.Domain)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWatchEntry, err)
+	}
+	if !strings.Contains(domain, ".") {
+		return nil, fmt.Errorf("%w: invalid domain format: %s", ErrInvalidWatchEntry, request.Domain)
+	}
+
+	// Watch the registrable domain, e.g. "example.co.uk" for "shop.example.co.uk"
+	domain = utils.SplitDomain(domain).RegistrableDomain()
+	if domain == "" {
+		return nil, fmt.Errorf("%w: %s is a public suffix", ErrInvalidWatchEntry, request.Domain)
+	}
+
+	interval, err := m.interval(request.IntervalSeconds)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	entries, err := m.store.ListEntries()
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.Domain == domain {
+			return nil, fmt.Errorf("%w: %s", ErrWatchEntryExists, domain)
+		}
+	}
+
+	now := time.Now()
+	entry := &models.WatchEntry{
+		Domain:          domain,
+		Note:            request.Note,
+		IntervalSeconds: int64(interval / time.Second),
+		NextCheckAt:     now,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if err := m.store.SaveEntry(entry); err != nil {
+		return nil, err
+	}
+
+	m.signal()
+	return entry, nil
+}
+
+// List returns all watched domains
+func (m *WatchlistManager) List() ([]models.WatchEntry, error) {
+	return m.store.ListEntries()
+}
+
+// Get returns one watched domain
+func (m *WatchlistManager) Get(id int) (*models.WatchEntry, error) {
+	return m.store.GetEntry(id)
+}
+
+// Update changes the note or interval of a watched domain
+func (m *WatchlistManager) Update(id int, request models.WatchUpdateRequest) (*models.WatchEntry, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	entry, err := m.store.GetEntry(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Note != nil {
+		entry.Note = *request.Note
+	}
+	if request.IntervalSeconds != nil {
+		interval, err := m.interval(*request.IntervalSeconds)
+		if err != nil {
+			return nil, err
+		}
+		entry.IntervalSeconds = int64(interval / time.Second)
+
+		// Reschedule from the last check with the new interval
+		if entry.LastCheckedAt != nil {
+			entry.NextCheckAt = entry.LastCheckedAt.Add(interval)
+		}
+	}
+	entry.UpdatedAt = time.Now()
+
+	if err := m.store.SaveEntry(entry); err != nil {
+		return nil, err
+	}
+
+	m.signal()
+	return entry, nil
+}
+
+// Remove stops watching a domain and discards its transitions
+func (m *WatchlistManager) Remove(id int) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.store.DeleteEntry(id)
+}
+
+// Transitions returns the status transitions of a watched domain, newest first
+func (m *WatchlistManager) Transitions(id int, limit int) ([]models.WatchTransition, error) {
+	if _, err := m.store.GetEntry(id); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultTransitionLimit
+	}
+
+	return m.store.ListTransitions(id, limit)
+}
+
+// CheckNow re-checks a watched domain immediately and returns the updated entry
+func (m *WatchlistManager) CheckNow(ctx context.Context, id int) (*models.WatchEntry, error) {
+	entry, err := m.store.GetEntry(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.check(ctx, entry.ID, entry.Domain)
+}
+
+// Close stops the scheduler, waits for running checks and closes the store
+func (m *WatchlistManager) Close() error {
+	m.cancel()
+	m.wg.Wait()
+	return m.store.Close()
+}
+
+// interval validates a requested interval, where zero selects the default
+func (m *WatchlistManager) interval(seconds int64) (time.Duration, error) {
+	if seconds < 0 {
+		return 0, fmt.Errorf("%w: interval must not be negative", ErrInvalidWatchEntry)
+	}
+	if seconds == 0 {
+		return m.defaultInterval, nil
+	}
+
+	interval := time.Duration(seconds) * time.Second
+	if interval < m.minInterval {
+		return 0, fmt.Errorf("%w: interval must be at least %s", ErrInvalidWatchEntry, m.minInterval)
+	}
+	return interval, nil
+}
+
+// signal wakes the scheduler without blocking
+func (m *WatchlistManager) signal() {
+	select {
+	case m.wake <- struct{}{}:
+	default:
+		// A wake-up is already pending
+	}
+}
+
+// schedule starts the checks of due entries and sleeps until the next one is due
+func (m *WatchlistManager) schedule() {
+	defer m.wg.Done()
+
+	for {
+		timer := time.NewTimer(m.dispatchDue())
+		select {
+		case <-m.ctx.Done():
+			timer.Stop()
+			return
+		case <-m.wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// dispatchDue starts a check for every due entry and returns how long
+// to wait until the next entry is due
+func (m *WatchlistManager) dispatchDue() time.Duration {
+	entries, err := m.store.ListEntries()
+	if err != nil {
+		log.Printf("Failed to load watchlist: %v", err)
+		return watchMaxWait
+	}
+
+	now := time.Now()
+	wait := watchMaxWait
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, entry := range entries {
+		if m.checking[entry.ID] {
+			continue
+		}
+
+		if until := entry.NextCheckAt.Sub(now); until > 0 {
+			if until < wait {
+				wait = until
+			}
+			continue
+		}
+
+		m.checking[entry.ID] = true
+		m.wg.Add(1)
+		go func(id int, domain string) {
+			defer m.wg.Done()
+
+			select {
+			case m.slots <- struct{}{}:
+			case <-m.ctx.Done():
+				return
+			}
+
+			if _, err := m.check(m.ctx, id, domain); err != nil && !errors.Is(err, ErrWatchEntryNotFound) && m.ctx.Err() == nil {
+				log.Printf("Failed to check watched domain %s: %v", domain, err)
+			}
+
+			<-m.slots
+			m.mutex.Lock()
+			delete(m.checking, id)
+			m.mutex.Unlock()
+			m.signal()
+		}(entry.ID, entry.Domain)
+	}
+
+	return wait
+}
+
+// check runs an availability check, plus an RDAP/WHOIS lookup for registered
+// domains, and records the outcome on the entry
+func (m *WatchlistManager) check(ctx context.Context, id int, domain string) (*models.WatchEntry, error) {
+	availability := models.StatusUnknown
+	var result *models.Domain
+	var checkErr string
+	var whoisInfo *models.WhoisInfo
+
+	// Scheduled checks look for changes, so a cached result would only delay them
+	response, err := m.service.CheckDomain(WithoutCache(ctx), domain)
+	if err != nil {
+		checkErr = err.Error()
+	} else {
+		result = response.Domain
+		availability = result.Availability
+		checkErr = result.Error
+	}
+
+	// The registry status reveals pending delete and redemption, which the
+	// DNS-based checkers cannot see
+	if availability.IsConclusive() && !availability.IsAvailable() {
+		if info, err := m.service.GetWhoisInfo(ctx, domain); err == nil {
+			whoisInfo = info
+			if availability == models.StatusRegistered {
+				availability = availabilityFromEPPStatus(info.Status)
+			}
+		}
+	}
+
+	if ctx.Err() != nil {
+		// Cut short by shutdown or a departed client, not a real outcome
+		return nil, ctx.Err()
+	}
+
+	entry, events, err := m.record(id, availability, checkErr, whoisInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, event := range events {
+		event.Result = result
+		m.emit(event)
+	}
+	return entry, nil
+}
+
+// record applies the outcome of a check to an entry, stores the transition
+// if its status changed and returns the events the check triggers
+func (m *WatchlistManager) record(id int, availability models.AvailabilityStatus, checkErr string, whoisInfo *models.WhoisInfo) (*models.WatchEntry, []models.WatchEvent, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	// Re-read the entry, it may have been changed or removed while checking
+	entry, err := m.store.GetEntry(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	entry.LastCheckedAt = &now
+	entry.NextCheckAt = now.Add(entry.Interval())
+
+	if !availability.IsConclusive() {
+		// Keep the last known status and try again sooner
+		entry.LastError = checkErr
+		if entry.LastError == "" {
+			entry.LastError = string(availability)
+		}
+		if retry := now.Add(watchRetryInterval); retry.Before(entry.NextCheckAt) {
+			entry.NextCheckAt = retry
+		}
+		if err := m.store.SaveEntry(entry); err != nil {
+			return nil, nil, err
+		}
+		return entry, nil, nil
+	}
+
+	entry.LastError = ""
+	if whoisInfo != nil {
+		entry.RegistryStatus = whoisInfo.Status
+		entry.ExpirationDate = whoisInfo.ExpirationDate
+	} else if availability.IsAvailable() {
+		entry.RegistryStatus = nil
+		entry.ExpirationDate = nil
+	}
+
+	var events []models.WatchEvent
+
+	// Warn once per expiration date; a renewal moves the date and re-arms the warning
+	if m.expiring(entry, now) {
+		entry.ExpiryWarnedFor = entry.ExpirationDate
+		events = append(events, models.WatchEvent{Type: models.WatchEventExpiryApproaching})
+	}
+
+	previous := entry.Availability
+	if previous != availability {
+		entry.Availability = availability
+		entry.Status = availability.LegacyStatus()
+		entry.LastChangedAt = &now
+	}
+	if err := m.store.SaveEntry(entry); err != nil {
+		return nil, nil, err
+	}
+
+	if previous != availability {
+		transition := &models.WatchTransition{
+			EntryID: entry.ID,
+			Domain:  entry.Domain,
+			From:    previous,
+			To:      availability,
+			At:      now,
+		}
+		if err := m.store.AddTransition(transition); err != nil {
+			log.Printf("Failed to record transition of %s: %v", entry.Domain, err)
+		}
+
+		if eventType := watchEventType(previous, availability); eventType != "" {
+			events = append(events, models.WatchEvent{Type: eventType, Transition: transition})
+		}
+	}
+
+	for i := range events {
+		events[i].Entry = *entry
+	}
+	return entry, events, nil
+}
+
+// expiring reports whether a registered entry expires within the warning
+// period and has not been warned about for its current expiration date
+func (m *WatchlistManager) expiring(entry *models.WatchEntry, now time.Time) bool {
+	expiresAt := entry.ExpirationDate
+	if expiresAt == nil || (entry.ExpiryWarnedFor != nil && entry.ExpiryWarnedFor.Equal(*expiresAt)) {
+		return false
+	}
+
+	return expiresAt.After(now) && expiresAt.Sub(now) <= m.expiryWarning
+}
+
+// emit passes an event to every listener
+func (m *WatchlistManager) emit(event models.WatchEvent) {
+	log.Printf("Watchlist: %s %s", event.Type, event.Entry.Domain)
+
+	m.listenersMutex.RLock()
+	defer m.listenersMutex.RUnlock()
+
+	for _, listener := range m.listeners {
+		listener(event)
+	}
+}
+
+// watchEventType returns the event a transition triggers, or "" if none.
+// The first check of an entry has no previous status and triggers nothing.
+func watchEventType(from, to models.AvailabilityStatus) string {
+	switch {
+	case from == "":
+		return ""
+	case to.IsAvailable() && !from.IsAvailable():
+		return models.WatchEventDomainAvailable
+	case to == models.StatusPendingDelete:
+		return models.WatchEventDomainPendingDelete
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,682 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"domaincheck/internal/config"
+	"domaincheck/internal/models"
+	"domaincheck/internal/storage"
+)
+
+// ErrWebhookNotFound is returned for unknown webhook IDs
+var ErrWebhookNotFound = storage.ErrWebhookNotFound
+
+// ErrDeliveryNotFound is returned for unknown webhook delivery IDs
+var ErrDeliveryNotFound = storage.ErrDeliveryNotFound
+
+// ErrInvalidWebhook is returned for a webhook request with an invalid URL or event filter
+var ErrInvalidWebhook = errors.New("invalid webhook")
+
+// Headers sent with every webhook delivery
+const (
+	WebhookSignatureHeader = "X-Webhook-Signature" // "sha256=" + hex HMAC of "<timestamp>.<body>"
+	WebhookTimestampHeader = "X-Webhook-Timestamp" // Unix seconds the delivery attempt was signed at
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+)
+
+const (
+	// Defaults used when the configuration leaves a setting out
+	defaultWebhookTimeout        = 10 * time.Second
+	defaultWebhookMaxAttempts    = 6
+	defaultWebhookInitialBackoff = 10 * time.Second
+	defaultWebhookMaxBackoff     = time.Hour
+	defaultDeliveryRetention     = 7 * 24 * time.Hour
+	defaultErrorRateThreshold    = 0.5
+	defaultErrorRateMinChecks    = 20
+	defaultErrorRateWindow       = 5 * time.Minute
+
+	// webhookConcurrency limits how many deliveries are attempted at once
+	webhookConcurrency = 4
+	// webhookMaxWait is the longest the delivery loop sleeps before looking for due deliveries again
+	webhookMaxWait = time.Minute
+	// webhookMonitorInterval is how often checker error rates are evaluated
+	webhookMonitorInterval = 30 * time.Second
+	// webhookCleanupInterval is how often old deliveries are pruned
+	webhookCleanupInterval = time.Hour
+	// defaultDeliveryLimit is how many deliveries are listed unless asked otherwise
+	defaultDeliveryLimit = 100
+	// webhookResponseLimit is how much of a receiver's response body is read
+	webhookResponseLimit = 64 << 10
+)
+
+// WebhookManager posts HMAC-signed event notifications to registered URLs,
+// retrying failed deliveries with exponential backoff
+type WebhookManager struct {
+	service  *DomainService
+	store    storage.WebhookStore
+	client   *http.Client
+	cfg      config.WebhooksConfig
+	pending  map[int]time.Time // Next attempt of every pending delivery, by delivery ID
+	inFlight map[int]bool
+	alerting map[string]bool // Checkers whose high error rate was already reported
+	mutex    sync.Mutex
+	slots    chan struct{}
+	wake     chan struct{}
+	ctx      context.Context // Cancelled on shutdown
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// NewWebhookManager creates a new webhook manager, resumes pending deliveries
+// and starts watching checker error rates
+func NewWebhookManager(service *DomainService, cfg config.WebhooksConfig) (*WebhookManager, error) {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultWebhookTimeout
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaultWebhookMaxAttempts
+	}
+	if cfg.InitialBackoff <= 0 {
+		cfg.InitialBackoff = defaultWebhookInitialBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = defaultWebhookMaxBackoff
+	}
+	if cfg.DeliveryRetention <= 0 {
+		cfg.DeliveryRetention = defaultDeliveryRetention
+	}
+	if cfg.ErrorRateThreshold <= 0 {
+		cfg.ErrorRateThreshold = defaultErrorRateThreshold
+	}
+	if cfg.ErrorRateMinChecks <= 0 {
+		cfg.ErrorRateMinChecks = defaultErrorRateMinChecks
+	}
+	if cfg.ErrorRateWindow <= 0 {
+		cfg.ErrorRateWindow = defaultErrorRateWindow
+	}
+
+	store, err := storage.NewWebhookStore(cfg.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize webhook store: %w", err)
+	}
+
+	pending, err := store.PendingDeliveries()
+	if err != nil {
+		store.Close()
+		return nil, fmt.Errorf("failed to load pending webhook deliveries: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	manager := &WebhookManager{
+		service:  service,
+		store:    store,
+		client:   &http.Client{Timeout: cfg.Timeout},
+		cfg:      cfg,
+		pending:  make(map[int]time.Time),
+		inFlight: make(map[int]bool),
+		alerting: make(map[string]bool),
+		slots:    make(chan struct{}, webhookConcurrency),
+		wake:     make(chan struct{}, 1),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+
+	for _, delivery := range pending {
+		next := delivery.CreatedAt
+		if delivery.NextAttemptAt != nil {
+			next = *delivery.NextAttemptAt
+		}
+		manager.pending[delivery.ID] = next
+	}
+
+	manager.wg.Add(2)
+	go manager.deliverLoop()
+	go manager.monitorLoop()
+
+	return manager, nil
+}
+
+// Create registers a webhook; the returned webhook carries its secret
+func (m *WebhookManager) Create(request models.WebhookRequest) (*models.Webhook, error) {
+	if err := validateWebhook(request.URL, request.Events); err != nil {
+		return nil, err
+	}
+
+	secret := request.Secret
+	if secret == "" {
+		var err error
+		if secret, err = newWebhookSecret(); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	webhook := &models.Webhook{
+		URL:         request.URL,
+		Events:      request.Events,
+		Secret:      secret,
+		Description: request.Description,
+		Active:      request.Active == nil || *request.Active,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := m.store.SaveWebhook(webhook); err != nil {
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+// List returns all webhooks without their secrets
+func (m *WebhookManager) List() ([]models.Webhook, error) {
+	webhooks, err := m.store.ListWebhooks()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+	return webhooks, nil
+}
+
+// Get returns one webhook without its secret
+func (m *WebhookManager) Get(id int) (*models.Webhook, error) {
+	webhook, err := m.store.GetWebhook(id)
+	if err != nil {
+		return nil, err
+	}
+
+	webhook.Secret = ""
+	return webhook, nil
+}
+
+// Update changes a webhook
+func (m *WebhookManager) Update(id int, request models.WebhookUpdateRequest) (*models.Webhook, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	webhook, err := m.store.GetWebhook(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.URL != nil {
+		webhook.URL = *request.URL
+	}
+	if request.Events != nil {
+		webhook.Events = request.Events
+	}
+	if request.Secret != nil && *request.Secret != "" {
+		webhook.Secret = *request.Secret
+	}
+	if request.Description != nil {
+		webhook.Description = *request.Description
+	}
+	if request.Active != nil {
+		webhook.Active = *request.Active
+	}
+	if err := validateWebhook(webhook.URL, webhook.Events); err != nil {
+		return nil, err
+	}
+	webhook.UpdatedAt = time.Now()
+
+	if err := m.store.SaveWebhook(webhook); err != nil {
+		return nil, err
+	}
+
+	webhook.Secret = ""
+	return webhook, nil
+}
+
+// Delete removes a webhook and its delivery log; pending deliveries are dropped
+func (m *WebhookManager) Delete(id int) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.store.DeleteWebhook(id)
+}
+
+// Deliveries returns the delivery log of a webhook, newest first
+func (m *WebhookManager) Deliveries(webhookID int, limit int) ([]models.WebhookDelivery, error) {
+	if _, err := m.store.GetWebhook(webhookID); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultDeliveryLimit
+	}
+
+	return m.store.ListDeliveries(webhookID, limit)
+}
+
+// Delivery returns one delivery of a webhook
+func (m *WebhookManager) Delivery(webhookID, deliveryID int) (*models.WebhookDelivery, error) {
+	delivery, err := m.store.GetDelivery(deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if delivery.WebhookID != webhookID {
+		return nil, ErrDeliveryNotFound
+	}
+	return delivery, nil
+}
+
+// Redeliver queues a new delivery with the same payload as an earlier one
+func (m *WebhookManager) Redeliver(webhookID, deliveryID int) (*models.WebhookDelivery, error) {
+	original, err := m.Delivery(webhookID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.enqueue(webhookID, original.EventID, original.Event, original.Payload, original.ID)
+}
+
+// Ping queues a test event for a webhook, even if it is inactive
+func (m *WebhookManager) Ping(webhookID int) (*models.WebhookDelivery, error) {
+	if _, err := m.store.GetWebhook(webhookID); err != nil {
+		return nil, err
+	}
+
+	payload, eventID, err := newWebhookPayload(models.WebhookEventPing, map[string]interface{}{
+		"webhook_id": webhookID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return m.enqueue(webhookID, eventID, models.WebhookEventPing, payload, 0)
+}
+
+// Dispatch queues an event for every active webhook subscribed to it
+func (m *WebhookManager) Dispatch(event string, data interface{}) {
+	webhooks, err := m.store.ListWebhooks()
+	if err != nil {
+		log.Printf("Failed to load webhooks for %s: %v", event, err)
+		return
+	}
+
+	var payload []byte
+	var eventID string
+	for _, webhook := range webhooks {
+		if !webhook.Active || !webhook.Subscribes(event) {
+			continue
+		}
+
+		// Every receiver gets the same payload and event ID
+		if payload == nil {
+			if payload, eventID, err = newWebhookPayload(event, data); err != nil {
+				log.Printf("Failed to encode %s webhook payload: %v", event, err)
+				return
+			}
+		}
+
+		if _, err := m.enqueue(webhook.ID, eventID, event, payload, 0); err != nil {
+			log.Printf("Failed to queue %s for webhook %d: %v", event, webhook.ID, err)
+		}
+	}
+}
+
+// HandleWatchEvent dispatches a watchlist event
+func (m *WebhookManager) HandleWatchEvent(event models.WatchEvent) {
+	data := &models.WebhookDomainEvent{
+		Domain:     event.Result,
+		Entry:      &event.Entry,
+		Transition: event.Transition,
+	}
+
+	switch event.Type {
+	case models.WatchEventDomainAvailable:
+		m.Dispatch(models.WebhookEventDomainAvailable, data)
+	case models.WatchEventDomainPendingDelete:
+		m.Dispatch(models.WebhookEventDomainPendingDelete, data)
+	case models.WatchEventExpiryApproaching:
+		data.ExpirationDate = event.Entry.ExpirationDate
+		if data.ExpirationDate != nil {
+			days := daysUntil(*data.ExpirationDate, time.Now())
+			data.DaysLeft = &days
+		}
+		m.Dispatch(models.WebhookEventExpiryApproaching, data)
+	}
+}
+
+// HandleRenewalReminder dispatches a renewal reminder of an owned domain
+func (m *WebhookManager) HandleRenewalReminder(reminder models.PortfolioReminder) {
+	m.Dispatch(models.WebhookEventRenewalReminder, &reminder)
+}
+
+// HandleJobFinished dispatches the results of a finished bulk check job
+func (m *WebhookManager) HandleJobFinished(job models.Job) {
+	result := m.service.jobCheckResult(&job)
+	job.Results = nil
+
+	m.Dispatch(models.WebhookEventJobCompleted, &models.WebhookJobEvent{
+		Job:    &job,
+		Result: result,
+	})
+}
+
+// Close stops delivering; pending deliveries resume on the next start
+func (m *WebhookManager) Close() error {
+	m.cancel()
+	m.wg.Wait()
+	return m.store.Close()
+}
+
+// enqueue stores a new pending delivery and wakes the delivery loop
+func (m *WebhookManager) enqueue(webhookID int, eventID, event string, payload []byte, redeliveryOf int) (*models.WebhookDelivery, error) {
+	now := time.Now()
+	delivery := &models.WebhookDelivery{
+		WebhookID:     webhookID,
+		EventID:       eventID,
+		Event:         event,
+		Payload:       payload,
+		Status:        models.DeliveryStatusPending,
+		RedeliveryOf:  redeliveryOf,
+		CreatedAt:     now,
+		NextAttemptAt: &now,
+	}
+
+	m.mutex.Lock()
+	err := m.store.SaveDelivery(delivery)
+	if err == nil {
+		m.pending[delivery.ID] = now
+	}
+	m.mutex.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	m.signal()
+	return delivery, nil
+}
+
+// signal wakes the delivery loop without blocking
+func (m *WebhookManager) signal() {
+	select {
+	case m.wake <- struct{}{}:
+	default:
+		// A wake-up is already pending
+	}
+}
+
+// deliverLoop attempts due deliveries and sleeps until the next one is due
+func (m *WebhookManager) deliverLoop() {
+	defer m.wg.Done()
+
+	for {
+		timer := time.NewTimer(m.dispatchDue())
+		select {
+		case <-m.ctx.Done():
+			timer.Stop()
+			return
+		case <-m.wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// dispatchDue starts an attempt for every due delivery and returns how long
+// to wait until the next one is due
+func (m *WebhookManager) dispatchDue() time.Duration {
+	now := time.Now()
+	wait := webhookMaxWait
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for id, next := range m.pending {
+		if m.inFlight[id] {
+			continue
+		}
+
+		if until := next.Sub(now); until > 0 {
+			if until < wait {
+				wait = until
+			}
+			continue
+		}
+
+		m.inFlight[id] = true
+		m.wg.Add(1)
+		go func(id int) {
+			defer m.wg.Done()
+
+			select {
+			case m.slots <- struct{}{}:
+			case <-m.ctx.Done():
+				return
+			}
+			m.attempt(id)
+			<-m.slots
+
+			m.mutex.Lock()
+			delete(m.inFlight, id)
+			m.mutex.Unlock()
+			m.signal()
+		}(id)
+	}
+
+	return wait
+}
+
+// attempt posts a delivery once and schedules a retry if it failed
+func (m *WebhookManager) attempt(id int) {
+	delivery, err := m.store.GetDelivery(id)
+	if err != nil {
+		// Deleted along with its webhook
+		m.mutex.Lock()
+		delete(m.pending, id)
+		m.mutex.Unlock()
+		return
+	}
+
+	webhook, err := m.store.GetWebhook(delivery.WebhookID)
+	if err != nil {
+		m.mutex.Lock()
+		delete(m.pending, id)
+		m.mutex.Unlock()
+		return
+	}
+
+	statusCode, err := m.post(webhook, delivery)
+	if m.ctx.Err() != nil {
+		// Interrupted by shutdown: the attempt does not count and resumes on the next start
+		return
+	}
+
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.ResponseStatus = statusCode
+	delivery.LastError = ""
+	delivery.NextAttemptAt = nil
+
+	switch {
+	case err == nil:
+		delivery.Status = models.DeliveryStatusSucceeded
+	case delivery.Attempts >= m.cfg.MaxAttempts:
+		delivery.Status = models.DeliveryStatusFailed
+		delivery.LastError = err.Error()
+	default:
+		next := now.Add(m.backoff(delivery.Attempts))
+		delivery.NextAttemptAt = &next
+		delivery.LastError = err.Error()
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if err := m.store.SaveDelivery(delivery); err != nil {
+		log.Printf("Failed to record webhook delivery %d: %v", id, err)
+	}
+	if delivery.NextAttemptAt != nil {
+		m.pending[id] = *delivery.NextAttemptAt
+	} else {
+		delete(m.pending, id)
+	}
+}
+
+// post sends a signed delivery and returns the response status code
+func (m *WebhookManager) post(webhook *models.Webhook, delivery *models.WebhookDelivery) (int, error) {
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(m.ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "DomainCheck-Webhook/1.0")
+	req.Header.Set(WebhookEventHeader, delivery.Event)
+	req.Header.Set(WebhookDeliveryHeader, strconv.Itoa(delivery.ID))
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(webhook.Secret, timestamp, delivery.Payload))
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, webhookResponseLimit))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver answered %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff returns the wait before the retry following the given number of attempts
+func (m *WebhookManager) backoff(attempts int) time.Duration {
+	wait := m.cfg.InitialBackoff
+	for i := 1; i < attempts && wait < m.cfg.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > m.cfg.MaxBackoff {
+		wait = m.cfg.MaxBackoff
+	}
+	return wait
+}
+
+// monitorLoop reports checkers with a high error rate and prunes old deliveries
+func (m *WebhookManager) monitorLoop() {
+	defer m.wg.Done()
+
+	monitor := time.NewTicker(webhookMonitorInterval)
+	defer monitor.Stop()
+	cleanup := time.NewTicker(webhookCleanupInterval)
+	defer cleanup.Stop()
+
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-monitor.C:
+			m.checkErrorRates()
+		case now := <-cleanup.C:
+			if err := m.store.PruneDeliveries(now.Add(-m.cfg.DeliveryRetention)); err != nil {
+				log.Printf("Failed to prune webhook deliveries: %v", err)
+			}
+		}
+	}
+}
+
+// checkErrorRates dispatches an event when a checker's error rate rises
+// above the threshold; it is reported again only after it has recovered
+func (m *WebhookManager) checkErrorRates() {
+	for _, rate := range m.service.checkerStats.rates(m.cfg.ErrorRateWindow) {
+		high := rate.Checks >= m.cfg.ErrorRateMinChecks && rate.ErrorRate() > m.cfg.ErrorRateThreshold
+
+		m.mutex.Lock()
+		report := high && !m.alerting[rate.Checker]
+		m.alerting[rate.Checker] = high
+		m.mutex.Unlock()
+
+		if report {
+			log.Printf("Checker %s failed %d of %d checks", rate.Checker, rate.Failures, rate.Checks)
+			m.Dispatch(models.WebhookEventCheckerErrorRate, &models.WebhookCheckerEvent{
+				Checker:   rate.Checker,
+				Checks:    rate.Checks,
+				Failures:  rate.Failures,
+				ErrorRate: rate.ErrorRate(),
+				Threshold: m.cfg.ErrorRateThreshold,
+				WindowMs:  m.cfg.ErrorRateWindow.Milliseconds(),
+			})
+		}
+	}
+}
+
+// SignWebhookPayload returns the signature header value of a payload,
+// which receivers recompute with their secret to verify a delivery
+func SignWebhookPayload(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// newWebhookPayload encodes an event with a new event ID
+func newWebhookPayload(event string, data interface{}) ([]byte, string, error) {
+	eventID, err := newJobID()
+	if err != nil {
+		return nil, "", err
+	}
+
+	payload, err := json.Marshal(models.WebhookPayload{
+		ID:        eventID,
+		Event:     event,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return payload, eventID, nil
+}
+
+// newWebhookSecret returns a random signing secret
+func newWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// validateWebhook checks the URL and event filter of a webhook
+func validateWebhook(rawURL string, events []string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidWebhook)
+	}
+
+	if len(events) == 0 {
+		return fmt.Errorf("%w: at least one event is required", ErrInvalidWebhook)
+	}
+	for _, event := range events {
+		known := event == models.WebhookEventAll
+		for _, e := range models.WebhookEvents {
+			known = known || e == event
+		}
+		if !known {
+			return fmt.Errorf("%w: unknown event %q", ErrInvalidWebhook, event)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,311 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"domaincheck/internal/config"
+	"domaincheck/internal/models"
+)
+
+// receivedWebhook records one request received by the webhook receiver
+type receivedWebhook struct {
+	header http.Header
+	body   []byte
+	at     time.Time
+}
+
+// webhookReceiver is a local webhook endpoint answering with a queue of
+// status codes; once the queue is empty it answers 200
+type webhookReceiver struct {
+	server   *httptest.Server
+	statuses []int
+	requests []receivedWebhook
+	mutex    sync.Mutex
+	received chan struct{}
+}
+
+func newWebhookReceiver(t *testing.T, statuses ...int) *webhookReceiver {
+	t.Helper()
+
+	r := &webhookReceiver{statuses: statuses, received: make(chan struct{}, 100)}
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+
+		r.mutex.Lock()
+		r.requests = append(r.requests, receivedWebhook{header: req.Header.Clone(), body: body, at: time.Now()})
+		status := http.StatusOK
+		if len(r.statuses) > 0 {
+			status, r.statuses = r.statuses[0], r.statuses[1:]
+		}
+		r.mutex.Unlock()
+
+		w.WriteHeader(status)
+		r.received <- struct{}{}
+	}))
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+// all returns the requests received so far
+func (r *webhookReceiver) all() []receivedWebhook {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]receivedWebhook(nil), r.requests...)
+}
+
+// newTestWebhookManager returns a manager with an in-memory store and the
+// given retry settings
+func newTestWebhookManager(t *testing.T, cfg config.WebhooksConfig) *WebhookManager {
+	t.Helper()
+
+	manager, err := NewWebhookManager(&DomainService{checkerStats: newCheckerStats()}, cfg)
+	if err != nil {
+		t.Fatalf("NewWebhookManager: %v", err)
+	}
+	t.Cleanup(func() { manager.Close() })
+	return manager
+}
+
+// createTestWebhook registers the receiver for every event
+func createTestWebhook(t *testing.T, manager *WebhookManager, receiver *webhookReceiver, secret string) *models.Webhook {
+	t.Helper()
+
+	webhook, err := manager.Create(models.WebhookRequest{
+		URL:    receiver.server.URL,
+		Events: []string{models.WebhookEventAll},
+		Secret: secret,
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	return webhook
+}
+
+// waitForDelivery polls a delivery until it leaves the pending state
+func waitForDelivery(t *testing.T, manager *WebhookManager, webhookID, deliveryID int) *models.WebhookDelivery {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		delivery, err := manager.Delivery(webhookID, deliveryID)
+		if err != nil {
+			t.Fatalf("Delivery: %v", err)
+		}
+		if delivery.Status != models.DeliveryStatusPending {
+			return delivery
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("delivery %d still pending after %d attempts", deliveryID, delivery.Attempts)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestWebhookDeliveryIsSigned(t *testing.T) {
+	const secret = "test-secret"
+
+	receiver := newWebhookReceiver(t)
+	manager := newTestWebhookManager(t, config.WebhooksConfig{})
+	webhook := createTestWebhook(t, manager, receiver, secret)
+
+	manager.Dispatch(models.WebhookEventJobCompleted, map[string]string{"job": "abc"})
+	<-receiver.received
+
+	request := receiver.all()[0]
+	timestamp := request.header.Get(WebhookTimestampHeader)
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		t.Fatalf("timestamp header %q: %v", timestamp, err)
+	}
+	if age := time.Since(time.Unix(seconds, 0)); age < -time.Second || age > time.Minute {
+		t.Errorf("timestamp is %s old", age)
+	}
+
+	// Recompute the signature the way a receiver would
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + string(request.body)))
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if got := request.header.Get(WebhookSignatureHeader); got != want {
+		t.Errorf("signature = %q, want %q", got, want)
+	}
+	if got := SignWebhookPayload("other-secret", seconds, request.body); got == want {
+		t.Errorf("signature does not depend on the secret")
+	}
+
+	if got := request.header.Get(WebhookEventHeader); got != models.WebhookEventJobCompleted {
+		t.Errorf("event header = %q, want %q", got, models.WebhookEventJobCompleted)
+	}
+
+	var payload models.WebhookPayload
+	if err := json.Unmarshal(request.body, &payload); err != nil {
+		t.Fatalf("payload: %v", err)
+	}
+	if payload.Event != models.WebhookEventJobCompleted || payload.ID == "" {
+		t.Errorf("payload = %+v", payload)
+	}
+
+	deliveries, err := manager.Deliveries(webhook.ID, 0)
+	if err != nil || len(deliveries) != 1 {
+		t.Fatalf("deliveries = %v, %v; want one", deliveries, err)
+	}
+	if got := request.header.Get(WebhookDeliveryHeader); got != strconv.Itoa(deliveries[0].ID) {
+		t.Errorf("delivery header = %q, want %d", got, deliveries[0].ID)
+	}
+}
+
+func TestWebhookDeliveryRetriesServerErrors(t *testing.T) {
+	const initialBackoff = 30 * time.Millisecond
+
+	receiver := newWebhookReceiver(t, http.StatusInternalServerError, http.StatusBadGateway)
+	manager := newTestWebhookManager(t, config.WebhooksConfig{
+		MaxAttempts:    5,
+		InitialBackoff: initialBackoff,
+		MaxBackoff:     time.Second,
+	})
+	webhook := createTestWebhook(t, manager, receiver, "")
+
+	queued, err := manager.Ping(webhook.ID)
+	if err != nil {
+		t.Fatalf("Ping: %v", err)
+	}
+
+	delivery := waitForDelivery(t, manager, webhook.ID, queued.ID)
+	if delivery.Status != models.DeliveryStatusSucceeded {
+		t.Fatalf("status = %q, want %q", delivery.Status, models.DeliveryStatusSucceeded)
+	}
+	if delivery.Attempts != 3 || delivery.ResponseStatus != http.StatusOK || delivery.LastError != "" {
+		t.Errorf("delivery = %+v, want success on the third attempt", delivery)
+	}
+
+	// The waits between attempts double
+	requests := receiver.all()
+	if len(requests) != 3 {
+		t.Fatalf("receiver got %d requests, want 3", len(requests))
+	}
+	if wait := requests[1].at.Sub(requests[0].at); wait < initialBackoff {
+		t.Errorf("first retry after %s, want at least %s", wait, initialBackoff)
+	}
+	if wait := requests[2].at.Sub(requests[1].at); wait < 2*initialBackoff {
+		t.Errorf("second retry after %s, want at least %s", wait, 2*initialBackoff)
+	}
+
+	// Every attempt is signed anew with the same delivery ID
+	for _, request := range requests {
+		if request.header.Get(WebhookDeliveryHeader) != strconv.Itoa(queued.ID) {
+			t.Errorf("delivery header = %q, want %d", request.header.Get(WebhookDeliveryHeader), queued.ID)
+		}
+	}
+}
+
+func TestWebhookDeliveryGivesUpAfterMaxAttempts(t *testing.T) {
+	receiver := newWebhookReceiver(t,
+		http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+	manager := newTestWebhookManager(t, config.WebhooksConfig{
+		MaxAttempts:    3,
+		InitialBackoff: 5 * time.Millisecond,
+		MaxBackoff:     10 * time.Millisecond,
+	})
+	webhook := createTestWebhook(t, manager, receiver, "")
+
+	queued, err := manager.Ping(webhook.ID)
+	if err != nil {
+		t.Fatalf("Ping: %v", err)
+	}
+
+	delivery := waitForDelivery(t, manager, webhook.ID, queued.ID)
+	if delivery.Status != models.DeliveryStatusFailed {
+		t.Fatalf("status = %q, want %q", delivery.Status, models.DeliveryStatusFailed)
+	}
+	if delivery.Attempts != 3 || delivery.ResponseStatus != http.StatusServiceUnavailable {
+		t.Errorf("attempts = %d, response status = %d; want 3 and 503", delivery.Attempts, delivery.ResponseStatus)
+	}
+	if delivery.LastError == "" || delivery.NextAttemptAt != nil {
+		t.Errorf("delivery = %+v, want an error and no further attempt", delivery)
+	}
+
+	// No attempt beyond the limit
+	time.Sleep(50 * time.Millisecond)
+	if requests := len(receiver.all()); requests != 3 {
+		t.Errorf("receiver got %d requests, want 3", requests)
+	}
+}
+
+func TestWebhookBackoffDoublesUpToMax(t *testing.T) {
+	manager := &WebhookManager{cfg: config.WebhooksConfig{
+		InitialBackoff: 10 * time.Second,
+		MaxBackoff:     time.Minute,
+	}}
+
+	want := []time.Duration{10 * time.Second, 20 * time.Second, 40 * time.Second, time.Minute, time.Minute}
+	for i, wait := range want {
+		if got := manager.backoff(i + 1); got != wait {
+			t.Errorf("backoff after %d attempts = %s, want %s", i+1, got, wait)
+		}
+	}
+}
+
+func TestWebhookDeliveryLogAndRedelivery(t *testing.T) {
+	receiver := newWebhookReceiver(t, http.StatusInternalServerError)
+	manager := newTestWebhookManager(t, config.WebhooksConfig{MaxAttempts: 1})
+	webhook := createTestWebhook(t, manager, receiver, "")
+
+	manager.Dispatch(models.WebhookEventJobCompleted, map[string]string{"job": "abc"})
+	<-receiver.received
+
+	deliveries, err := manager.Deliveries(webhook.ID, 0)
+	if err != nil || len(deliveries) != 1 {
+		t.Fatalf("deliveries = %v, %v; want one", deliveries, err)
+	}
+	original := waitForDelivery(t, manager, webhook.ID, deliveries[0].ID)
+	if original.Status != models.DeliveryStatusFailed || original.ResponseStatus != http.StatusInternalServerError {
+		t.Fatalf("original = %+v, want a failed delivery answered with 500", original)
+	}
+
+	redelivery, err := manager.Redeliver(webhook.ID, original.ID)
+	if err != nil {
+		t.Fatalf("Redeliver: %v", err)
+	}
+	if redelivery.ID == original.ID || redelivery.RedeliveryOf != original.ID {
+		t.Errorf("redelivery = %+v, want a new delivery of %d", redelivery, original.ID)
+	}
+	if redelivery.EventID != original.EventID || string(redelivery.Payload) != string(original.Payload) {
+		t.Errorf("redelivery does not repeat the original event")
+	}
+
+	if done := waitForDelivery(t, manager, webhook.ID, redelivery.ID); done.Status != models.DeliveryStatusSucceeded {
+		t.Errorf("redelivery status = %q, want %q", done.Status, models.DeliveryStatusSucceeded)
+	}
+	requests := receiver.all()
+	if len(requests) != 2 || string(requests[1].body) != string(requests[0].body) {
+		t.Fatalf("receiver got %d requests, want the original payload twice", len(requests))
+	}
+	if got := requests[1].header.Get(WebhookDeliveryHeader); got != strconv.Itoa(redelivery.ID) {
+		t.Errorf("delivery header = %q, want %d", got, redelivery.ID)
+	}
+
+	// The log lists both, newest first, and keeps the original untouched
+	deliveries, err = manager.Deliveries(webhook.ID, 0)
+	if err != nil || len(deliveries) != 2 {
+		t.Fatalf("deliveries = %v, %v; want two", deliveries, err)
+	}
+	if deliveries[0].ID != redelivery.ID || deliveries[1].ID != original.ID {
+		t.Errorf("deliveries are listed as %d, %d; want %d, %d", deliveries[0].ID, deliveries[1].ID, redelivery.ID, original.ID)
+	}
+	if deliveries[1].Status != models.DeliveryStatusFailed || deliveries[1].Attempts != 1 {
+		t.Errorf("original changed to %+v", deliveries[1])
+	}
+
+	if _, err := manager.Redeliver(webhook.ID+1, original.ID); err == nil {
+		t.Errorf("redelivered through another webhook")
+	}
+}
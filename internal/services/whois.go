@@ -0,0 +1,352 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"domaincheck/internal/models"
+)
+
+// ErrWhoisNotFound is returned when the WHOIS server has no record of the domain
+var ErrWhoisNotFound = errors.New("domain not found in WHOIS")
+
+// ErrWhoisReserved is returned when the registry reports the domain as reserved
+var ErrWhoisReserved = errors.New("domain is reserved by the registry")
+
+// ErrWhoisUnrecognized is returned for a WHOIS response that holds neither
+// registration data nor a known not-found or reserved phrase, such as an
+// empty, truncated or unfamiliar answer
+var ErrWhoisUnrecognized = errors.New("WHOIS response has no registration data")
+
+const (
+	// ianaWhoisServer is queried to discover the WHOIS server of unknown TLDs
+	ianaWhoisServer = "whois.iana.org"
+	// maxWhoisReferrals limits how many referrals are followed per lookup
+	maxWhoisReferrals = 2
+	// maxWhoisResponseSize limits how much of a WHOIS response is read
+	maxWhoisResponseSize = 1 << 20
+	// defaultWhoisTimeout is used when the context carries no deadline
+	defaultWhoisTimeout = 10 * time.Second
+)
+
+// whoisQueryFormats holds registry-specific query syntax; "%s" is the domain
+var whoisQueryFormats = map[string]string{
+	"whois.verisign-grs.com": "domain %s",
+	"whois.denic.de":         "-T dn,ace %s",
+	"whois.jprs.jp":          "%s/e",
+}
+
+// WhoisClient queries port-43 WHOIS servers
+type WhoisClient struct {
+	servers        map[string]string // TLD without dot -> WHOIS server host
+	mutex          sync.RWMutex
+	referralHost   string
+	dialer         net.Dialer
+	defaultTimeout time.Duration
+	limiter        *rateLimiter
+}
+
+// NewWhoisClient creates a new WHOIS client
+func NewWhoisClient() *WhoisClient {
+	return &WhoisClient{
+		servers:        make(map[string]string),
+		referralHost:   ianaWhoisServer,
+		defaultTimeout: defaultWhoisTimeout,
+	}
+}
+
+// SetServers replaces the TLD to WHOIS server map
+func (c *WhoisClient) SetServers(servers map[string]string) {
+	normalized := make(map[string]string, len(servers))
+	for tld, server := range servers {
+		normalized[strings.ToLower(strings.TrimPrefix(tld, "."))] = server
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.servers = normalized
+}
+
+// SetServer overrides the WHOIS server used for a TLD
+func (c *WhoisClient) SetServer(tld, server string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.servers[strings.ToLower(strings.TrimPrefix(tld, "."))] = server
+}
+
+// ServerFor returns the WHOIS server for a TLD, asking IANA for unknown TLDs
+func (c *WhoisClient) ServerFor(ctx context.Context, tld string) (string, error) {
+	tld = strings.ToLower(strings.TrimPrefix(tld, "."))
+
+	c.mutex.RLock()
+	server, ok := c.servers[tld]
+	c.mutex.RUnlock()
+	if ok {
+		if server == "" {
+			return "", ErrCheckerNotApplicable
+		}
+		return server, nil
+	}
+
+	raw, err := c.Query(ctx, c.referralHost, tld)
+	if err != nil {
+		return "", fmt.Errorf("failed to discover WHOIS server for .%s: %w", tld, err)
+	}
+	if err := c.checkRateLimited(c.referralHost, raw); err != nil {
+		return "", err
+	}
+
+	// Remember the answer, including TLDs that have no WHOIS service
+	server = whoisReferral(raw)
+	c.SetServer(tld, server)
+
+	if server == "" {
+		return "", ErrCheckerNotApplicable
+	}
+	return server, nil
+}
+
+// Query sends a raw query to a WHOIS server and returns the full response.
+// It waits for the server's rate limit and fails with an error wrapping
+// ErrRateLimited if that takes too long.
+func (c *WhoisClient) Query(ctx context.Context, server, query string) (string, error) {
+	address := server
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		address = net.JoinHostPort(server, "43")
+	}
+
+	if err := c.limiter.wait(ctx, models.RateLimitScopeServer, whoisHost(server)); err != nil {
+		return "", err
+	}
+
+	conn, err := c.dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to WHOIS server %s: %w", server, err)
+	}
+	defer conn.Close()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(c.defaultTimeout)
+	}
+	conn.SetDeadline(deadline)
+
+	if _, err := conn.Write([]byte(query + "\r\n")); err != nil {
+		return "", fmt.Errorf("failed to send WHOIS query to %s: %w", server, err)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(conn, maxWhoisResponseSize))
+	if err != nil {
+		return "", fmt.Errorf("failed to read WHOIS response from %s: %w", server, err)
+	}
+
+	return string(data), nil
+}
+
+// LookupDomain queries the registry WHOIS server for a domain, follows referrals
+// to the registrar's server and parses the combined result. It returns
+// ErrWhoisNotFound if the registry reports the domain as not registered and
+// ErrWhoisReserved if it cannot be registered at all. Errors wrapping
+// ErrRateLimited mean the registry refused the query for now, and errors
+// wrapping ErrWhoisUnrecognized that its answer could not be understood.
+func (c *WhoisClient) LookupDomain(ctx context.Context, domain string) (*models.WhoisInfo, error) {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+
+	if !strings.Contains(domain, ".") {
+		return nil, fmt.Errorf("domain must have an extension")
+	}
+
+	server, err := c.serverForDomain(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := c.Query(ctx, server, whoisQuery(server, domain))
+	if err != nil {
+		return nil, err
+	}
+
+	whoisInfo := ParseWhoisResponse(domain, raw)
+	if !hasRegistrationData(whoisInfo) {
+		if err := c.checkRateLimited(server, raw); err != nil {
+			return nil, err
+		}
+	}
+	c.limiter.succeed(models.RateLimitScopeServer, whoisHost(server))
+	if !hasRegistrationData(whoisInfo) && IsWhoisReserved(raw) {
+		return nil, ErrWhoisReserved
+	}
+	if isUnregistered(whoisInfo) {
+		return nil, ErrWhoisNotFound
+	}
+	if !hasRegistrationData(whoisInfo) {
+		// Not proof of a registration: the answer may be empty, cut off or in an unknown format
+		return nil, fmt.Errorf("%w: %s answered for %s without a known not-found phrase", ErrWhoisUnrecognized, server, domain)
+	}
+
+	// Follow referrals, e.g. from a thin registry to the registrar's WHOIS server
+	visited := map[string]bool{strings.ToLower(server): true}
+	for i := 0; i < maxWhoisReferrals; i++ {
+		referral := whoisReferral(raw)
+		if referral == "" || visited[referral] {
+			break
+		}
+		visited[referral] = true
+
+		referralRaw, err := c.Query(ctx, referral, whoisQuery(referral, domain))
+		if err != nil {
+			// The registry answer is still authoritative
+			break
+		}
+		referralInfo := ParseWhoisResponse(domain, referralRaw)
+		if !hasRegistrationData(referralInfo) && c.checkRateLimited(referral, referralRaw) != nil {
+			break
+		}
+		c.limiter.succeed(models.RateLimitScopeServer, whoisHost(referral))
+
+		mergeWhoisInfo(whoisInfo, referralInfo)
+		whoisInfo.RawData += "\n" + referralRaw
+		raw = referralRaw
+	}
+
+	return whoisInfo, nil
+}
+
+// serverForDomain returns the WHOIS server configured for the longest suffix
+// of a domain, so that e.g. "co.uk" may use another server than "uk", and
+// the server of the domain's TLD otherwise
+func (c *WhoisClient) serverForDomain(ctx context.Context, domain string) (string, error) {
+	labels := strings.Split(domain, ".")
+
+	c.mutex.RLock()
+	for i := 1; i < len(labels)-1; i++ {
+		if server := c.servers[strings.Join(labels[i:], ".")]; server != "" {
+			c.mutex.RUnlock()
+			return server, nil
+		}
+	}
+	c.mutex.RUnlock()
+
+	return c.ServerFor(ctx, labels[len(labels)-1])
+}
+
+// checkRateLimited backs off a server whose response refuses further queries
+// and returns an error wrapping ErrRateLimited, or nil for any other response
+func (c *WhoisClient) checkRateLimited(server, raw string) error {
+	if !IsWhoisRateLimited(raw) {
+		return nil
+	}
+	c.limiter.backoff(models.RateLimitScopeServer, whoisHost(server), 0)
+	return fmt.Errorf("%w: WHOIS server %s refused the query", ErrRateLimited, server)
+}
+
+// whoisHost returns the host of a WHOIS server given with or without a port
+func whoisHost(server string) string {
+	if host, _, err := net.SplitHostPort(server); err == nil {
+		return host
+	}
+	return server
+}
+
+// whoisQuery formats the query for a specific WHOIS server
+func whoisQuery(server, domain string) string {
+	if format, ok := whoisQueryFormats[strings.ToLower(server)]; ok {
+		return fmt.Sprintf(format, domain)
+	}
+	return domain
+}
+
+// isUnregistered reports whether a parsed response denotes an unregistered domain.
+// Not-found phrases only count if the response carries no registration data,
+// since disclaimers of registered domains may contain the same words.
+func isUnregistered(whoisInfo *models.WhoisInfo) bool {
+	return !hasRegistrationData(whoisInfo) && IsWhoisNotFound(whoisInfo.RawData)
+}
+
+// hasRegistrationData reports whether a parsed response describes an existing registration
+func hasRegistrationData(whoisInfo *models.WhoisInfo) bool {
+	return whoisInfo.Registrar != "" || whoisInfo.CreationDate != nil || len(whoisInfo.NameServers) > 0
+}
+
+// mergeWhoisInfo prefers the more detailed registrar data over registry data
+func mergeWhoisInfo(dst, src *models.WhoisInfo) {
+	if src.Registrar != "" {
+		dst.Registrar = src.Registrar
+	}
+	if src.CreationDate != nil {
+		dst.CreationDate = src.CreationDate
+	}
+	if src.ExpirationDate != nil {
+		dst.ExpirationDate = src.ExpirationDate
+	}
+	if src.UpdatedDate != nil {
+		dst.UpdatedDate = src.UpdatedDate
+	}
+	if len(src.NameServers) > 0 {
+		dst.NameServers = src.NameServers
+	}
+	if len(src.Status) > 0 {
+		dst.Status = src.Status
+	}
+	if src.AdminContact != "" {
+		dst.AdminContact = src.AdminContact
+	}
+	if src.TechContact != "" {
+		dst.TechContact = src.TechContact
+	}
+}
+
+// WhoisChecker checks availability by querying the registry's WHOIS server
+type WhoisChecker struct {
+	client *WhoisClient
+}
+
+// NewWhoisChecker creates a new WHOIS checker
+func NewWhoisChecker(client *WhoisClient) *WhoisChecker {
+	return &WhoisChecker{client: client}
+}
+
+// Name returns the checker name
+func (c *WhoisChecker) Name() string {
+	return "whois"
+}
+
+// Check derives availability from the registry's not-found response
+// and maps the registry status codes of existing domains. Answers without
+// registration data are errors, so the strategy chain moves on.
+func (c *WhoisChecker) Check(ctx context.Context, domain string) (*CheckResult, error) {
+	whoisInfo, err := c.client.LookupDomain(ctx, domain)
+	if errors.Is(err, ErrWhoisNotFound) {
+		return &CheckResult{
+			Availability: models.StatusAvailable,
+			Confidence:   models.ConfidenceHigh,
+			Evidence:     []string{"registry WHOIS server reported no match"},
+		}, nil
+	}
+	if errors.Is(err, ErrWhoisReserved) {
+		return &CheckResult{
+			Availability: models.StatusReserved,
+			Confidence:   models.ConfidenceHigh,
+			Evidence:     []string{"registry WHOIS server reported the name as reserved"},
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CheckResult{
+		Availability: availabilityFromEPPStatus(whoisInfo.Status),
+		Confidence:   models.ConfidenceHigh,
+		Evidence:     []string{"registry WHOIS server returned registration data"},
+	}
+	if len(whoisInfo.Status) > 0 {
+		result.Evidence = append(result.Evidence, "registry status: "+strings.Join(whoisInfo.Status, ", "))
+	}
+	return result, nil
+}
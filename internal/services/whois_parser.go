@@ -0,0 +1,306 @@
+package services
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"domaincheck/internal/models"
+)
+
+// whoisFieldKeys maps WHOIS response keys (lowercased) to WhoisInfo fields.
+// Registries label the same data differently, so each field lists every known variant.
+var whoisFieldKeys = map[string][]string{
+	"registrar": {
+		"registrar", "registrar name", "sponsoring registrar", "registrar organization",
+	},
+	"creation_date": {
+		"creation date", "created", "created on", "created date", "registered", "registered on",
+		"registration time", "registration date", "domain registration date", "domain record activated",
+	},
+	"expiration_date": {
+		"registry expiry date", "registrar registration expiration date", "expiration date",
+		"expiry date", "expire date", "expires", "expires on", "paid-till", "expiration time",
+		"renewal date", "domain expiration date",
+	},
+	"updated_date": {
+		"updated date", "last updated", "last updated on", "last modified", "last update",
+		"changed", "modified", "domain record last updated",
+	},
+	"name_servers": {
+		"name server", "name servers", "nameserver", "nameservers", "nserver", "dns", "domain servers",
+	},
+	"status": {
+		"domain status", "status", "state", "registration status",
+	},
+	"admin_contact": {
+		"admin name", "admin organization", "administrative contact", "admin-c",
+	},
+	"tech_contact": {
+		"tech name", "tech organization", "technical contact", "tech-c",
+	},
+}
+
+// whoisNotFoundPatterns are registry responses meaning the domain is not registered
+var whoisNotFoundPatterns = []string{
+	"no match for",
+	"not found",
+	"no data found",
+	"no entries found",
+	"no object found",
+	"nothing found",
+	"object does not exist",
+	"no information available",
+	"is available for registration",
+	"this query returned 0 objects",
+	"status: free",
+	"status: available",
+	"status:\tavailable",
+	// Registry-specific wording
+	"no match found",          // .tr
+	"no match!!",              // .jp
+	"no matching record",      // .cn
+	"object_not_found",        // .mx
+	"has not been registered", // .hk
+	"no such domain",          // .lu
+	" is free",                // .nl
+}
+
+// whoisReservedPatterns are registry responses meaning the domain cannot be registered
+var whoisReservedPatterns = []string{
+	"is reserved",
+	"has been reserved",
+	"reserved by the registry",
+	"reserved domain name",
+	"registry reserved",
+	"status: reserved",
+}
+
+// whoisRateLimitPatterns are responses of servers refusing further queries for now
+var whoisRateLimitPatterns = []string{
+	"limit exceeded",
+	"limit reached",
+	"quota exceeded",
+	"too many queries",
+	"too many requests",
+	"exceeded the maximum allowable number",
+}
+
+// whoisReferralKeys are keys pointing to a more specific WHOIS server
+var whoisReferralKeys = []string{
+	"registrar whois server", "whois server", "referralserver", "refer", "whois",
+}
+
+// whoisLine represents a single "key: value" pair from a WHOIS response
+type whoisLine struct {
+	key   string
+	value string
+}
+
+// parseWhoisLines splits a WHOIS response into key/value pairs.
+// Keys with an empty value take the following indented lines, and lines
+// without a colon, as values, which covers block-style registries such as
+// Nominet (.uk) and nic.tr.
+func parseWhoisLines(raw string) []whoisLine {
+	var lines []whoisLine
+	var blockKey string
+
+	for _, line := range strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			blockKey = ""
+			continue
+		}
+		if strings.HasPrefix(trimmed, "%") || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, ">>>") {
+			continue
+		}
+
+		indented := line[0] == ' ' || line[0] == '\t'
+		key, value, found := strings.Cut(trimmed, ":")
+		if blockKey != "" && (!found || (indented && !isWhoisKey(key))) {
+			lines = append(lines, whoisLine{key: blockKey, value: trimmed})
+			continue
+		}
+		if !found {
+			continue
+		}
+
+		// Some registries mark keys with asterisks or pad them with dots, e.g. "** Created on......:"
+		key = strings.ToLower(strings.Trim(key, "*. \t"))
+		value = strings.TrimSpace(value)
+		if value == "" {
+			blockKey = key
+			continue
+		}
+
+		blockKey = ""
+		lines = append(lines, whoisLine{key: key, value: value})
+	}
+
+	return lines
+}
+
+// isWhoisKey reports whether text before a colon looks like a field label
+// rather than part of a value such as a timestamp
+func isWhoisKey(key string) bool {
+	for _, r := range key {
+		if !unicode.IsLetter(r) && r != ' ' && r != '-' && r != '_' && r != '.' && r != '(' && r != ')' && r != '/' {
+			return false
+		}
+	}
+	return key != ""
+}
+
+// ParseWhoisResponse extracts registration data from a raw WHOIS response
+func ParseWhoisResponse(domain, raw string) *models.WhoisInfo {
+	whoisInfo := &models.WhoisInfo{
+		Domain:    domain,
+		RawData:   raw,
+		CheckedAt: time.Now(),
+	}
+
+	fieldForKey := make(map[string]string)
+	for field, keys := range whoisFieldKeys {
+		for _, key := range keys {
+			fieldForKey[key] = field
+		}
+	}
+
+	seenNameServers := make(map[string]bool)
+	seenStatus := make(map[string]bool)
+
+	for _, line := range parseWhoisLines(raw) {
+		switch fieldForKey[line.key] {
+		case "registrar":
+			if whoisInfo.Registrar == "" {
+				whoisInfo.Registrar = line.value
+			}
+		case "creation_date":
+			if whoisInfo.CreationDate == nil {
+				whoisInfo.CreationDate = parseRegistryDate(line.value)
+			}
+		case "expiration_date":
+			if whoisInfo.ExpirationDate == nil {
+				whoisInfo.ExpirationDate = parseRegistryDate(line.value)
+			}
+		case "updated_date":
+			if whoisInfo.UpdatedDate == nil {
+				whoisInfo.UpdatedDate = parseRegistryDate(line.value)
+			}
+		case "name_servers":
+			// Some registries append glue addresses after the host name
+			ns := strings.ToLower(strings.TrimSuffix(strings.Fields(line.value)[0], "."))
+			if !seenNameServers[ns] {
+				seenNameServers[ns] = true
+				whoisInfo.NameServers = append(whoisInfo.NameServers, ns)
+			}
+		case "status":
+			// Strip the ICANN EPP status URL, e.g. "clientTransferProhibited https://icann.org/epp#..."
+			status := strings.Fields(line.value)[0]
+			if !seenStatus[status] {
+				seenStatus[status] = true
+				whoisInfo.Status = append(whoisInfo.Status, status)
+			}
+		case "admin_contact":
+			if whoisInfo.AdminContact == "" {
+				whoisInfo.AdminContact = line.value
+			}
+		case "tech_contact":
+			if whoisInfo.TechContact == "" {
+				whoisInfo.TechContact = line.value
+			}
+		}
+	}
+
+	return whoisInfo
+}
+
+// IsWhoisNotFound reports whether a WHOIS response says the domain is not registered
+func IsWhoisNotFound(raw string) bool {
+	lower := strings.ToLower(raw)
+	for _, pattern := range whoisNotFoundPatterns {
+		if strings.Contains(lower, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsWhoisReserved reports whether a WHOIS response says the domain is reserved
+func IsWhoisReserved(raw string) bool {
+	lower := strings.ToLower(raw)
+	for _, pattern := range whoisReservedPatterns {
+		if strings.Contains(lower, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsWhoisRateLimited reports whether a WHOIS response says the server refuses further queries for now
+func IsWhoisRateLimited(raw string) bool {
+	lower := strings.ToLower(raw)
+	for _, pattern := range whoisRateLimitPatterns {
+		if strings.Contains(lower, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// whoisReferral returns the WHOIS server a response refers to, if any
+func whoisReferral(raw string) string {
+	lines := parseWhoisLines(raw)
+	for _, referralKey := range whoisReferralKeys {
+		for _, line := range lines {
+			if line.key != referralKey {
+				continue
+			}
+
+			server := strings.TrimPrefix(line.value, "whois://")
+			server = strings.TrimPrefix(server, "rwhois://")
+			server = strings.TrimSuffix(strings.TrimSpace(server), "/")
+			if server != "" && !strings.Contains(server, " ") {
+				return strings.ToLower(server)
+			}
+		}
+	}
+	return ""
+}
+
+// registryDateLayouts are the date formats seen in RDAP events and WHOIS responses
+var registryDateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"2006.01.02",
+	"2006/01/02",
+	"02-Jan-2006",
+	"2006-Jan-02",
+	"02.01.2006",
+	"January 2 2006",
+}
+
+// parseRegistryDate parses a registration date as published by a registry,
+// returning nil if it is in none of the known formats
+func parseRegistryDate(value string) *time.Time {
+	value = strings.TrimSpace(value)
+	// Some registries append a time zone name in brackets, e.g. "2024-05-01 (UTC)"
+	if i := strings.Index(value, " ("); i > 0 {
+		value = value[:i]
+	}
+	// or end the date with a full stop, e.g. "2001-Aug-23."
+	value = strings.TrimSuffix(value, ".")
+
+	for _, layout := range registryDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			t = t.UTC()
+			return &t
+		}
+	}
+	return nil
+}
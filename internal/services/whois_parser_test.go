@@ -0,0 +1,193 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Abridged answers of real registry WHOIS servers
+const (
+	verisignRegistered = `   Domain Name: GOOGLE.COM
+   Registry Domain ID: 2138514_DOMAIN_COM-VRSN
+   Registrar WHOIS Server: whois.markmonitor.com
+   Registrar URL: http://www.markmonitor.com
+   Updated Date: 2019-09-09T15:39:04Z
+   Creation Date: 1997-09-15T04:00:00Z
+   Registry Expiry Date: 2028-09-14T04:00:00Z
+   Registrar: MarkMonitor Inc.
+   Registrar IANA ID: 292
+   Domain Status: clientDeleteProhibited https://icann.org/epp#clientDeleteProhibited
+   Domain Status: serverUpdateProhibited https://icann.org/epp#serverUpdateProhibited
+   Name Server: NS1.GOOGLE.COM
+   Name Server: NS2.GOOGLE.COM
+   DNSSEC: unsigned
+>>> Last update of whois database: 2024-05-01T10:00:00Z <<<
+
+NOTICE: The expiration date displayed in this record is the date the
+registrar's sponsorship of the domain name registration in the registry is
+currently set to expire.
+`
+
+	verisignNotFound = `No match for "EXAMPLE-UNREGISTERED-4821.COM".
+>>> Last update of whois database: 2024-05-01T10:00:00Z <<<
+
+NOTICE: The expiration date displayed in this record is the date the
+registrar's sponsorship of the domain name registration in the registry is
+currently set to expire.
+`
+
+	nominetRegistered = `
+    Domain name:
+        google.co.uk
+
+    Registrar:
+        Markmonitor Inc. t/a MarkMonitor Inc. [Tag = MARKMONITOR]
+        URL: http://www.markmonitor.com
+
+    Relevant dates:
+        Registered on: 14-Feb-1999
+        Expiry date:  14-Feb-2025
+        Last updated:  13-Jan-2024
+
+    Registration status:
+        Registered until expiry date.
+
+    Name servers:
+        ns1.google.com
+        ns2.google.com
+
+    WHOIS lookup made at 10:00:00 01-May-2024
+`
+
+	nominetNotFound = `
+    No match for "example-unregistered-4821.co.uk".
+
+    This domain name has not been registered.
+
+    WHOIS lookup made at 10:00:00 01-May-2024
+`
+
+	nicTRRegistered = `** Domain Name: google.com.tr
+** Domain Status: Active
+
+** Registrant:
+   Google LLC
+   Hidden upon user request
+
+** Registrar:
+NIC Handle		: mmi9-metu
+Organization Name	: MarkMonitor, Inc.
+
+** Domain Servers:
+ns1.google.com
+ns2.google.com
+
+** Additional Info:
+Created on..............: 2001-Aug-23.
+Expires on..............: 2025-Aug-22.
+`
+
+	nicTRNotFound = "** No match found for example-unregistered-4821.com.tr\n"
+
+	denicFree = `Domain: example-unregistered-4821.de
+Status: free
+`
+
+	jprsNotFound = `[ JPRS database provides information on network administration. Its use is    ]
+[ restricted to network administration purposes.                             ]
+
+No match!!
+
+Don't add "/e" at the end of the query to get the Japanese format.
+`
+
+	sidnFree = "example-unregistered-4821.nl is free\n"
+
+	cnnicNotFound = "No matching record.\n"
+)
+
+func TestParseWhoisResponseRegistered(t *testing.T) {
+	tests := []struct {
+		name        string
+		raw         string
+		registrar   string
+		created     string
+		expires     string
+		nameServers []string
+	}{
+		{"verisign", verisignRegistered, "MarkMonitor Inc.", "1997-09-15", "2028-09-14", []string{"ns1.google.com", "ns2.google.com"}},
+		{"nominet", nominetRegistered, "Markmonitor Inc. t/a MarkMonitor Inc. [Tag = MARKMONITOR]", "1999-02-14", "2025-02-14", []string{"ns1.google.com", "ns2.google.com"}},
+		{"nic.tr", nicTRRegistered, "", "2001-08-23", "2025-08-22", []string{"ns1.google.com", "ns2.google.com"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := ParseWhoisResponse("example", tt.raw)
+			if info.Registrar != tt.registrar {
+				t.Errorf("Registrar = %q, want %q", info.Registrar, tt.registrar)
+			}
+			if info.CreationDate == nil || info.CreationDate.Format("2006-01-02") != tt.created {
+				t.Errorf("CreationDate = %v, want %s", info.CreationDate, tt.created)
+			}
+			if info.ExpirationDate == nil || info.ExpirationDate.Format("2006-01-02") != tt.expires {
+				t.Errorf("ExpirationDate = %v, want %s", info.ExpirationDate, tt.expires)
+			}
+			if !reflect.DeepEqual(info.NameServers, tt.nameServers) {
+				t.Errorf("NameServers = %v, want %v", info.NameServers, tt.nameServers)
+			}
+			if !hasRegistrationData(info) || isUnregistered(info) {
+				t.Error("registered answer not recognised as a registration")
+			}
+		})
+	}
+}
+
+func TestParseWhoisResponseNotFound(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+	}{
+		{"verisign", verisignNotFound},
+		{"nominet", nominetNotFound},
+		{"nic.tr", nicTRNotFound},
+		{"denic", denicFree},
+		{"jprs", jprsNotFound},
+		{"sidn", sidnFree},
+		{"cnnic", cnnicNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := ParseWhoisResponse("example", tt.raw)
+			if hasRegistrationData(info) {
+				t.Errorf("parsed registration data from a not-found answer: %+v", info)
+			}
+			if !isUnregistered(info) {
+				t.Error("not-found answer not recognised")
+			}
+		})
+	}
+}
+
+func TestParseWhoisResponseUnrecognized(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+	}{
+		{"empty", ""},
+		{"truncated", "   Domain Name: GOOGLE.COM\n   Registry Domain ID: 2138514_DOM"},
+		{"unknown format", "Service temporarily unavailable, please retry.\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := ParseWhoisResponse("example", tt.raw)
+			if hasRegistrationData(info) {
+				t.Errorf("parsed registration data from an unrecognised answer: %+v", info)
+			}
+			if isUnregistered(info) || IsWhoisReserved(tt.raw) {
+				t.Error("unrecognised answer matched a not-found or reserved phrase")
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"domaincheck/internal/config"
+	"domaincheck/internal/models"
+)
+
+// newWhoisStandIn starts a local WHOIS server giving every query the same
+// answer and returns a checker sending ".test" lookups to it
+func newWhoisStandIn(t *testing.T, answer string) *WhoisChecker {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				if _, err := bufio.NewReader(conn).ReadString('\n'); err != nil {
+					return
+				}
+				conn.Write([]byte(answer))
+			}()
+		}
+	}()
+
+	client := NewWhoisClient()
+	client.SetServer(".test", listener.Addr().String())
+	client.limiter = newRateLimiter(config.RateLimitConfig{})
+	return NewWhoisChecker(client)
+}
+
+func TestWhoisCheckerNotFound(t *testing.T) {
+	checker := newWhoisStandIn(t, nicTRNotFound)
+
+	result, err := checker.Check(context.Background(), "example.test")
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if result.Availability != models.StatusAvailable || result.Confidence != models.ConfidenceHigh {
+		t.Errorf("got %s with %s confidence, want available with high confidence", result.Availability, result.Confidence)
+	}
+}
+
+func TestWhoisCheckerRegistered(t *testing.T) {
+	checker := newWhoisStandIn(t, verisignRegistered)
+
+	result, err := checker.Check(context.Background(), "example.test")
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if result.Availability != models.StatusRegistered {
+		t.Errorf("Availability = %s, want %s", result.Availability, models.StatusRegistered)
+	}
+}
+
+func TestWhoisCheckerUnrecognizedAnswer(t *testing.T) {
+	checker := newWhoisStandIn(t, "Service temporarily unavailable, please retry.\n")
+
+	result, err := checker.Check(context.Background(), "example.test")
+	if !errors.Is(err, ErrWhoisUnrecognized) {
+		t.Fatalf("Check = %+v, %v; want an error wrapping ErrWhoisUnrecognized", result, err)
+	}
+}
@@ -0,0 +1,226 @@
+package storage
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"domaincheck/internal/models"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// historyBucket holds check results keyed by their big-endian ID
+var historyBucket = []byte("history")
+
+// BoltHistoryStore keeps history in an embedded bbolt database file
+type BoltHistoryStore struct {
+	db        *bolt.DB
+	retention RetentionPolicy
+	count     int        // Number of stored entries
+	mutex     sync.Mutex // Held across write transactions so count matches the committed bucket
+}
+
+// NewBoltHistoryStore opens (or creates) a bbolt history database
+func NewBoltHistoryStore(path string, retention RetentionPolicy) (*BoltHistoryStore, error) {
+	if path == "" {
+		return nil, fmt.Errorf("history database path is required")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history database: %w", err)
+	}
+
+	store := &BoltHistoryStore{db: db, retention: retention}
+	err = db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(historyBucket)
+		if err != nil {
+			return err
+		}
+		store.count = bucket.Stats().KeyN
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize history database: %w", err)
+	}
+
+	return store, nil
+}
+
+// Add stores a check result under the bucket's persistent sequence
+func (s *BoltHistoryStore) Add(domain *models.Domain) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var count int
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(historyBucket)
+
+		id, err := bucket.NextSequence()
+		if err != nil {
+			return fmt.Errorf("failed to allocate history ID: %w", err)
+		}
+		domain.ID = int(id)
+
+		data, err := json.Marshal(domain)
+		if err != nil {
+			return fmt.Errorf("failed to encode history entry: %w", err)
+		}
+		if err := bucket.Put(sequenceKey(id), data); err != nil {
+			return fmt.Errorf("failed to store history entry: %w", err)
+		}
+
+		deleted, err := s.applyRetention(bucket, s.count+1)
+		count = s.count + 1 - deleted
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s.count = count
+	return nil
+}
+
+// List returns the stored results, newest first
+func (s *BoltHistoryStore) List() ([]models.Domain, error) {
+	result := []models.Domain{}
+	now := time.Now()
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(historyBucket).Cursor()
+		for k, v := cursor.Last(); k != nil; k, v = cursor.Prev() {
+			var domain models.Domain
+			if err := json.Unmarshal(v, &domain); err != nil {
+				return fmt.Errorf("failed to decode history entry: %w", err)
+			}
+			if s.retention.expired(domain.CheckedAt, now) {
+				// Entries are ordered by ID, so everything older is expired too
+				break
+			}
+			result = append(result, domain)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Scan returns up to limit results older than beforeID, newest first
+func (s *BoltHistoryStore) Scan(beforeID int, limit int) ([]models.Domain, error) {
+	result := []models.Domain{}
+	now := time.Now()
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(historyBucket).Cursor()
+
+		var k, v []byte
+		if beforeID > 0 {
+			// Seek lands on the first key at or after beforeID, step back from there
+			if k, _ = cursor.Seek(sequenceKey(uint64(beforeID))); k == nil {
+				k, v = cursor.Last()
+			} else {
+				k, v = cursor.Prev()
+			}
+		} else {
+			k, v = cursor.Last()
+		}
+
+		for ; k != nil && len(result) < limit; k, v = cursor.Prev() {
+			var domain models.Domain
+			if err := json.Unmarshal(v, &domain); err != nil {
+				return fmt.Errorf("failed to decode history entry: %w", err)
+			}
+			if s.retention.expired(domain.CheckedAt, now) {
+				break
+			}
+			result = append(result, domain)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Clear removes all stored results while keeping the ID sequence
+func (s *BoltHistoryStore) Clear() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(historyBucket)
+		sequence := bucket.Sequence()
+
+		if err := tx.DeleteBucket(historyBucket); err != nil {
+			return err
+		}
+		bucket, err := tx.CreateBucket(historyBucket)
+		if err != nil {
+			return err
+		}
+		return bucket.SetSequence(sequence)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.count = 0
+	return nil
+}
+
+// Close closes the database file
+func (s *BoltHistoryStore) Close() error {
+	return s.db.Close()
+}
+
+// applyRetention deletes the oldest entries beyond the count or age limits
+// and returns how many were deleted
+func (s *BoltHistoryStore) applyRetention(bucket *bolt.Bucket, count int) (int, error) {
+	now := time.Now()
+	excess := 0
+	if s.retention.MaxEntries > 0 {
+		excess = count - s.retention.MaxEntries
+	}
+
+	// Collect keys first, deleting while iterating makes the cursor skip entries
+	var expired [][]byte
+	cursor := bucket.Cursor()
+	for k, v := cursor.First(); k != nil; k, v = cursor.Next() {
+		if len(expired) >= excess {
+			var domain models.Domain
+			if err := json.Unmarshal(v, &domain); err != nil || !s.retention.expired(domain.CheckedAt, now) {
+				break
+			}
+		}
+		expired = append(expired, k)
+	}
+
+	for _, k := range expired {
+		if err := bucket.Delete(k); err != nil {
+			return 0, fmt.Errorf("failed to prune history: %w", err)
+		}
+	}
+	return len(expired), nil
+}
+
+// sequenceKey encodes an ID so that keys sort in insertion order
+func sequenceKey(id uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, id)
+	return key
+}
@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"domaincheck/internal/config"
+	"domaincheck/internal/models"
+)
+
+// HistoryStore persists domain check history
+type HistoryStore interface {
+	// Add stores a check result and assigns it the next monotonic ID
+	Add(domain *models.Domain) error
+	// List returns the stored results, newest first
+	List() ([]models.Domain, error)
+	// Scan returns up to limit results with an ID below beforeID, newest first.
+	// A beforeID of zero starts from the newest result.
+	Scan(beforeID int, limit int) ([]models.Domain, error)
+	// Clear removes all stored results; IDs keep increasing afterwards
+	Clear() error
+	// Close releases the resources held by the store
+	Close() error
+}
+
+// RetentionPolicy limits how much history is kept
+type RetentionPolicy struct {
+	MaxEntries int           // Zero keeps any number of entries
+	MaxAge     time.Duration // Zero keeps entries forever
+}
+
+// expired reports whether an entry checked at the given time is past its retention age
+func (p RetentionPolicy) expired(checkedAt time.Time, now time.Time) bool {
+	return p.MaxAge > 0 && now.Sub(checkedAt) > p.MaxAge
+}
+
+// NewHistoryStore creates the history store selected in configuration
+func NewHistoryStore(cfg config.HistoryConfig) (HistoryStore, error) {
+	retention := RetentionPolicy{
+		MaxEntries: cfg.MaxEntries,
+		MaxAge:     cfg.MaxAge,
+	}
+
+	switch cfg.Backend {
+	case "", "memory":
+		return NewMemoryHistoryStore(retention), nil
+	case "bolt":
+		return NewBoltHistoryStore(cfg.Path, retention)
+	default:
+		return nil, fmt.Errorf("unsupported history backend: %s", cfg.Backend)
+	}
+}
@@ -0,0 +1,238 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"domaincheck/internal/models"
+)
+
+// JobStore persists bulk check jobs so that they can resume after a restart
+type JobStore interface {
+	// Save writes the job metadata, input list and configuration snapshot
+	Save(state *models.JobState) error
+	// AppendResult checkpoints the result of one domain of a job
+	AppendResult(id string, result models.JobResult) error
+	// Load returns every stored job with its checkpointed results
+	Load() ([]models.JobState, error)
+	// Delete removes a job and its results
+	Delete(id string) error
+	// Close releases the resources held by the store
+	Close() error
+}
+
+// NewJobStore creates a file job store in dir, or a store that keeps nothing if dir is empty
+func NewJobStore(dir string) (JobStore, error) {
+	if dir == "" {
+		return MemoryJobStore{}, nil
+	}
+	return NewFileJobStore(dir)
+}
+
+// MemoryJobStore persists nothing; jobs only live in the job manager's memory
+type MemoryJobStore struct{}
+
+// Save does nothing
+func (MemoryJobStore) Save(*models.JobState) error { return nil }
+
+// AppendResult does nothing
+func (MemoryJobStore) AppendResult(string, models.JobResult) error { return nil }
+
+// Load returns no jobs
+func (MemoryJobStore) Load() ([]models.JobState, error) { return nil, nil }
+
+// Delete does nothing
+func (MemoryJobStore) Delete(string) error { return nil }
+
+// Close does nothing
+func (MemoryJobStore) Close() error { return nil }
+
+const (
+	// jobStateSuffix names the file holding a job's metadata and input list
+	jobStateSuffix = ".json"
+	// jobResultsSuffix names the append-only file of a job's results, one JSON object per line
+	jobResultsSuffix = ".results.jsonl"
+)
+
+// FileJobStore keeps each job in a directory as a state file, rewritten on
+// status changes, and a results file that every finished domain is appended to
+type FileJobStore struct {
+	dir     string
+	results map[string]*os.File // Open results files by job ID
+	mutex   sync.Mutex
+}
+
+// NewFileJobStore creates a new file job store, creating dir if needed
+func NewFileJobStore(dir string) (*FileJobStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create job directory: %w", err)
+	}
+
+	return &FileJobStore{
+		dir:     dir,
+		results: make(map[string]*os.File),
+	}, nil
+}
+
+// Save atomically replaces the state file of a job
+func (s *FileJobStore) Save(state *models.JobState) error {
+	saved := *state
+	saved.Job.Results = nil // Results live in their own file
+
+	data, err := json.Marshal(saved)
+	if err != nil {
+		return fmt.Errorf("failed to encode job state: %w", err)
+	}
+
+	path := s.path(state.Job.ID, jobStateSuffix)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write job state: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write job state: %w", err)
+	}
+
+	// Finished jobs get no more results
+	if state.Job.Status.IsFinished() {
+		s.closeResults(state.Job.ID)
+	}
+	return nil
+}
+
+// AppendResult appends one result line to the results file of a job
+func (s *FileJobStore) AppendResult(id string, result models.JobResult) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to encode job result: %w", err)
+	}
+	data = append(data, '\n')
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	f, ok := s.results[id]
+	if !ok {
+		f, err = os.OpenFile(s.path(id, jobResultsSuffix), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			return fmt.Errorf("failed to open job results: %w", err)
+		}
+		s.results[id] = f
+	}
+
+	if _, err := f.Write(data); err != nil {
+		return fmt.Errorf("failed to write job result: %w", err)
+	}
+	return nil
+}
+
+// Load reads every job in the directory. A results line cut short by a crash
+// is skipped, so that domain is simply checked again.
+func (s *FileJobStore) Load() ([]models.JobState, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read job directory: %w", err)
+	}
+
+	var states []models.JobState
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, jobStateSuffix) || strings.HasSuffix(name, jobResultsSuffix) {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(s.dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read job state: %w", err)
+		}
+
+		var state models.JobState
+		if err := json.Unmarshal(data, &state); err != nil {
+			return nil, fmt.Errorf("failed to parse job state %s: %w", name, err)
+		}
+
+		state.Job.Results, err = s.loadResults(state.Job.ID)
+		if err != nil {
+			return nil, err
+		}
+		states = append(states, state)
+	}
+
+	return states, nil
+}
+
+// loadResults reads the checkpointed results of a job
+func (s *FileJobStore) loadResults(id string) ([]models.JobResult, error) {
+	f, err := os.Open(s.path(id, jobResultsSuffix))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open job results: %w", err)
+	}
+	defer f.Close()
+
+	var results []models.JobResult
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	for scanner.Scan() {
+		var result models.JobResult
+		if err := json.Unmarshal(scanner.Bytes(), &result); err != nil {
+			continue
+		}
+		results = append(results, result)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read job results: %w", err)
+	}
+
+	return results, nil
+}
+
+// Delete removes the files of a job
+func (s *FileJobStore) Delete(id string) error {
+	s.closeResults(id)
+
+	for _, suffix := range []string{jobStateSuffix, jobResultsSuffix} {
+		if err := os.Remove(s.path(id, suffix)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to delete job: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close closes the open results files
+func (s *FileJobStore) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var firstErr error
+	for id, f := range s.results {
+		if err := f.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(s.results, id)
+	}
+	return firstErr
+}
+
+// closeResults closes the results file of a job if it is open
+func (s *FileJobStore) closeResults(id string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if f, ok := s.results[id]; ok {
+		f.Close()
+		delete(s.results, id)
+	}
+}
+
+// path returns the file of a job with the given suffix
+func (s *FileJobStore) path(id, suffix string) string {
+	return filepath.Join(s.dir, id+suffix)
+}
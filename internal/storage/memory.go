@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"domaincheck/internal/models"
+)
+
+// MemoryHistoryStore keeps history in memory; it is lost on restart
+type MemoryHistoryStore struct {
+	entries   []models.Domain // oldest first
+	nextID    int
+	retention RetentionPolicy
+	mutex     sync.RWMutex
+}
+
+// NewMemoryHistoryStore creates a new in-memory history store
+func NewMemoryHistoryStore(retention RetentionPolicy) *MemoryHistoryStore {
+	return &MemoryHistoryStore{
+		entries:   []models.Domain{},
+		nextID:    1,
+		retention: retention,
+	}
+}
+
+// Add stores a check result and assigns it the next ID
+func (s *MemoryHistoryStore) Add(domain *models.Domain) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	domain.ID = s.nextID
+	s.nextID++
+
+	s.entries = append(s.entries, *domain)
+	s.applyRetention()
+
+	return nil
+}
+
+// List returns the stored results, newest first
+func (s *MemoryHistoryStore) List() ([]models.Domain, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	// Return a copy to avoid race conditions
+	now := time.Now()
+	result := make([]models.Domain, 0, len(s.entries))
+	for i := len(s.entries) - 1; i >= 0; i-- {
+		if s.retention.expired(s.entries[i].CheckedAt, now) {
+			break
+		}
+		result = append(result, s.entries[i])
+	}
+	return result, nil
+}
+
+// Scan returns up to limit results older than beforeID, newest first
+func (s *MemoryHistoryStore) Scan(beforeID int, limit int) ([]models.Domain, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	// Entries are ordered by ascending ID
+	end := len(s.entries)
+	if beforeID > 0 {
+		end = sort.Search(len(s.entries), func(i int) bool {
+			return s.entries[i].ID >= beforeID
+		})
+	}
+
+	now := time.Now()
+	result := []models.Domain{}
+	for i := end - 1; i >= 0 && len(result) < limit; i-- {
+		if s.retention.expired(s.entries[i].CheckedAt, now) {
+			break
+		}
+		result = append(result, s.entries[i])
+	}
+	return result, nil
+}
+
+// Clear removes all stored results
+func (s *MemoryHistoryStore) Clear() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.entries = []models.Domain{}
+	return nil
+}
+
+// Close is a no-op for the in-memory store
+func (s *MemoryHistoryStore) Close() error {
+	return nil
+}
+
+// applyRetention drops entries beyond the count limit or older than the age limit
+func (s *MemoryHistoryStore) applyRetention() {
+	drop := 0
+	if s.retention.MaxEntries > 0 && len(s.entries) > s.retention.MaxEntries {
+		drop = len(s.entries) - s.retention.MaxEntries
+	}
+
+	now := time.Now()
+	for drop < len(s.entries) && s.retention.expired(s.entries[drop].CheckedAt, now) {
+		drop++
+	}
+
+	// Clear dropped entries so the backing array does not keep them alive
+	for i := 0; i < drop; i++ {
+		s.entries[i] = models.Domain{}
+	}
+	s.entries = s.entries[drop:]
+}
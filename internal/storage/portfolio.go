@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"domaincheck/internal/models"
+)
+
+// ErrPortfolioDomainNotFound is returned for an unknown portfolio domain ID
+var ErrPortfolioDomainNotFound = errors.New("portfolio domain not found")
+
+// PortfolioStore persists owned domains
+type PortfolioStore interface {
+	// SaveDomain stores a domain, assigning it the next ID if it has none
+	SaveDomain(domain *models.PortfolioDomain) error
+	// GetDomain returns one domain or ErrPortfolioDomainNotFound
+	GetDomain(id int) (*models.PortfolioDomain, error)
+	// ListDomains returns all domains ordered by ID
+	ListDomains() ([]models.PortfolioDomain, error)
+	// DeleteDomain removes a domain
+	DeleteDomain(id int) error
+	// Close releases the resources held by the store
+	Close() error
+}
+
+// NewPortfolioStore creates a bolt portfolio store at path, or an in-memory one if path is empty
+func NewPortfolioStore(path string) (PortfolioStore, error) {
+	if path == "" {
+		return NewMemoryPortfolioStore(), nil
+	}
+	return NewBoltPortfolioStore(path)
+}
+
+// MemoryPortfolioStore keeps the portfolio in memory; it is lost on restart
+type MemoryPortfolioStore struct {
+	domains map[int]models.PortfolioDomain
+	nextID  int
+	mutex   sync.RWMutex
+}
+
+// NewMemoryPortfolioStore creates a new in-memory portfolio store
+func NewMemoryPortfolioStore() *MemoryPortfolioStore {
+	return &MemoryPortfolioStore{
+		domains: make(map[int]models.PortfolioDomain),
+		nextID:  1,
+	}
+}
+
+// SaveDomain stores a domain, assigning it the next ID if it has none
+func (s *MemoryPortfolioStore) SaveDomain(domain *models.PortfolioDomain) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if domain.ID == 0 {
+		domain.ID = s.nextID
+		s.nextID++
+	} else if _, ok := s.domains[domain.ID]; !ok {
+		return ErrPortfolioDomainNotFound
+	}
+
+	s.domains[domain.ID] = *domain
+	return nil
+}
+
+// GetDomain returns one domain
+func (s *MemoryPortfolioStore) GetDomain(id int) (*models.PortfolioDomain, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	domain, ok := s.domains[id]
+	if !ok {
+		return nil, ErrPortfolioDomainNotFound
+	}
+	return &domain, nil
+}
+
+// ListDomains returns all domains ordered by ID
+func (s *MemoryPortfolioStore) ListDomains() ([]models.PortfolioDomain, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	result := make([]models.PortfolioDomain, 0, len(s.domains))
+	for _, domain := range s.domains {
+		result = append(result, domain)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+	return result, nil
+}
+
+// DeleteDomain removes a domain
+func (s *MemoryPortfolioStore) DeleteDomain(id int) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.domains[id]; !ok {
+		return ErrPortfolioDomainNotFound
+	}
+	delete(s.domains, id)
+	return nil
+}
+
+// Close does nothing for the in-memory store
+func (s *MemoryPortfolioStore) Close() error {
+	return nil
+}
@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"domaincheck/internal/models"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// portfolioBucket holds owned domains keyed by their big-endian ID
+var portfolioBucket = []byte("portfolio")
+
+// BoltPortfolioStore keeps the portfolio in an embedded bbolt database file
+type BoltPortfolioStore struct {
+	db *bolt.DB
+}
+
+// NewBoltPortfolioStore opens (or creates) a bbolt portfolio database
+func NewBoltPortfolioStore(path string) (*BoltPortfolioStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create portfolio directory: %w", err)
+	}
+
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open portfolio database: %w", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(portfolioBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize portfolio database: %w", err)
+	}
+
+	return &BoltPortfolioStore{db: db}, nil
+}
+
+// SaveDomain stores a domain, assigning it the next ID if it has none
+func (s *BoltPortfolioStore) SaveDomain(domain *models.PortfolioDomain) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(portfolioBucket)
+
+		if domain.ID == 0 {
+			id, err := bucket.NextSequence()
+			if err != nil {
+				return fmt.Errorf("failed to allocate portfolio domain ID: %w", err)
+			}
+			domain.ID = int(id)
+		} else if bucket.Get(sequenceKey(uint64(domain.ID))) == nil {
+			return ErrPortfolioDomainNotFound
+		}
+
+		data, err := json.Marshal(domain)
+		if err != nil {
+			return fmt.Errorf("failed to encode portfolio domain: %w", err)
+		}
+		if err := bucket.Put(sequenceKey(uint64(domain.ID)), data); err != nil {
+			return fmt.Errorf("failed to store portfolio domain: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetDomain returns one domain
+func (s *BoltPortfolioStore) GetDomain(id int) (*models.PortfolioDomain, error) {
+	var domain models.PortfolioDomain
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(portfolioBucket).Get(sequenceKey(uint64(id)))
+		if data == nil {
+			return ErrPortfolioDomainNotFound
+		}
+		if err := json.Unmarshal(data, &domain); err != nil {
+			return fmt.Errorf("failed to decode portfolio domain: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain, nil
+}
+
+// ListDomains returns all domains ordered by ID
+func (s *BoltPortfolioStore) ListDomains() ([]models.PortfolioDomain, error) {
+	result := []models.PortfolioDomain{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(portfolioBucket).ForEach(func(k, v []byte) error {
+			var domain models.PortfolioDomain
+			if err := json.Unmarshal(v, &domain); err != nil {
+				return fmt.Errorf("failed to decode portfolio domain: %w", err)
+			}
+			result = append(result, domain)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DeleteDomain removes a domain
+func (s *BoltPortfolioStore) DeleteDomain(id int) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(portfolioBucket)
+		key := sequenceKey(uint64(id))
+		if bucket.Get(key) == nil {
+			return ErrPortfolioDomainNotFound
+		}
+		if err := bucket.Delete(key); err != nil {
+			return fmt.Errorf("failed to delete portfolio domain: %w", err)
+		}
+		return nil
+	})
+}
+
+// Close closes the database file
+func (s *BoltPortfolioStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"domaincheck/internal/models"
+)
+
+// ErrPresetNotFound is returned for an unknown extension preset name
+var ErrPresetNotFound = errors.New("extension preset not found")
+
+// PresetStore persists extension presets created via the API
+type PresetStore interface {
+	// SavePreset creates or replaces a preset by name
+	SavePreset(preset *models.ExtensionPreset) error
+	// GetPreset returns one preset or ErrPresetNotFound
+	GetPreset(name string) (*models.ExtensionPreset, error)
+	// ListPresets returns all presets ordered by name
+	ListPresets() ([]models.ExtensionPreset, error)
+	// DeletePreset removes a preset
+	DeletePreset(name string) error
+	// Close releases the resources held by the store
+	Close() error
+}
+
+// NewPresetStore creates a bolt preset store at path, or an in-memory one if path is empty
+func NewPresetStore(path string) (PresetStore, error) {
+	if path == "" {
+		return NewMemoryPresetStore(), nil
+	}
+	return NewBoltPresetStore(path)
+}
+
+// MemoryPresetStore keeps presets in memory; they are lost on restart
+type MemoryPresetStore struct {
+	presets map[string]models.ExtensionPreset
+	mutex   sync.RWMutex
+}
+
+// NewMemoryPresetStore creates a new in-memory preset store
+func NewMemoryPresetStore() *MemoryPresetStore {
+	return &MemoryPresetStore{presets: make(map[string]models.ExtensionPreset)}
+}
+
+// SavePreset creates or replaces a preset by name
+func (s *MemoryPresetStore) SavePreset(preset *models.ExtensionPreset) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.presets[preset.Name] = *preset
+	return nil
+}
+
+// GetPreset returns one preset
+func (s *MemoryPresetStore) GetPreset(name string) (*models.ExtensionPreset, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	preset, ok := s.presets[name]
+	if !ok {
+		return nil, ErrPresetNotFound
+	}
+	return &preset, nil
+}
+
+// ListPresets returns all presets ordered by name
+func (s *MemoryPresetStore) ListPresets() ([]models.ExtensionPreset, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	result := make([]models.ExtensionPreset, 0, len(s.presets))
+	for _, preset := range s.presets {
+		result = append(result, preset)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+// DeletePreset removes a preset
+func (s *MemoryPresetStore) DeletePreset(name string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.presets[name]; !ok {
+		return ErrPresetNotFound
+	}
+	delete(s.presets, name)
+	return nil
+}
+
+// Close does nothing for the in-memory store
+func (s *MemoryPresetStore) Close() error {
+	return nil
+}
@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"domaincheck/internal/models"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// presetsBucket holds extension presets keyed by name
+var presetsBucket = []byte("presets")
+
+// BoltPresetStore keeps extension presets in an embedded bbolt database file
+type BoltPresetStore struct {
+	db *bolt.DB
+}
+
+// NewBoltPresetStore opens (or creates) a bbolt preset database
+func NewBoltPresetStore(path string) (*BoltPresetStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create preset directory: %w", err)
+	}
+
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open preset database: %w", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(presetsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize preset database: %w", err)
+	}
+
+	return &BoltPresetStore{db: db}, nil
+}
+
+// SavePreset creates or replaces a preset by name
+func (s *BoltPresetStore) SavePreset(preset *models.ExtensionPreset) error {
+	data, err := json.Marshal(preset)
+	if err != nil {
+		return fmt.Errorf("failed to encode extension preset: %w", err)
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(presetsBucket).Put([]byte(preset.Name), data); err != nil {
+			return fmt.Errorf("failed to store extension preset: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetPreset returns one preset
+func (s *BoltPresetStore) GetPreset(name string) (*models.ExtensionPreset, error) {
+	var preset models.ExtensionPreset
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(presetsBucket).Get([]byte(name))
+		if data == nil {
+			return ErrPresetNotFound
+		}
+		if err := json.Unmarshal(data, &preset); err != nil {
+			return fmt.Errorf("failed to decode extension preset: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &preset, nil
+}
+
+// ListPresets returns all presets ordered by name
+func (s *BoltPresetStore) ListPresets() ([]models.ExtensionPreset, error) {
+	result := []models.ExtensionPreset{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(presetsBucket).ForEach(func(k, v []byte) error {
+			var preset models.ExtensionPreset
+			if err := json.Unmarshal(v, &preset); err != nil {
+				return fmt.Errorf("failed to decode extension preset: %w", err)
+			}
+			result = append(result, preset)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DeletePreset removes a preset
+func (s *BoltPresetStore) DeletePreset(name string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(presetsBucket)
+		if bucket.Get([]byte(name)) == nil {
+			return ErrPresetNotFound
+		}
+		if err := bucket.Delete([]byte(name)); err != nil {
+			return fmt.Errorf("failed to delete extension preset: %w", err)
+		}
+		return nil
+	})
+}
+
+// Close closes the database file
+func (s *BoltPresetStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,150 @@
+package storage
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"domaincheck/internal/models"
+)
+
+// ErrWatchEntryNotFound is returned for an unknown watch entry ID
+var ErrWatchEntryNotFound = errors.New("watch entry not found")
+
+// WatchlistStore persists watched domains and their status transitions
+type WatchlistStore interface {
+	// SaveEntry stores an entry, assigning it the next ID if it has none
+	SaveEntry(entry *models.WatchEntry) error
+	// GetEntry returns one entry or ErrWatchEntryNotFound
+	GetEntry(id int) (*models.WatchEntry, error)
+	// ListEntries returns all entries ordered by ID
+	ListEntries() ([]models.WatchEntry, error)
+	// DeleteEntry removes an entry and its transitions
+	DeleteEntry(id int) error
+	// AddTransition stores a transition and assigns it the next ID
+	AddTransition(transition *models.WatchTransition) error
+	// ListTransitions returns up to limit transitions of an entry, newest first.
+	// An entryID of zero returns the transitions of all entries.
+	ListTransitions(entryID int, limit int) ([]models.WatchTransition, error)
+	// Close releases the resources held by the store
+	Close() error
+}
+
+// NewWatchlistStore creates a bolt watchlist store at path, or an in-memory one if path is empty
+func NewWatchlistStore(path string) (WatchlistStore, error) {
+	if path == "" {
+		return NewMemoryWatchlistStore(), nil
+	}
+	return NewBoltWatchlistStore(path)
+}
+
+// MemoryWatchlistStore keeps the watchlist in memory; it is lost on restart
+type MemoryWatchlistStore struct {
+	entries          map[int]models.WatchEntry
+	transitions      []models.WatchTransition // oldest first
+	nextEntryID      int
+	nextTransitionID int
+	mutex            sync.RWMutex
+}
+
+// NewMemoryWatchlistStore creates a new in-memory watchlist store
+func NewMemoryWatchlistStore() *MemoryWatchlistStore {
+	return &MemoryWatchlistStore{
+		entries:          make(map[int]models.WatchEntry),
+		nextEntryID:      1,
+		nextTransitionID: 1,
+	}
+}
+
+// SaveEntry stores an entry, assigning it the next ID if it has none
+func (s *MemoryWatchlistStore) SaveEntry(entry *models.WatchEntry) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if entry.ID == 0 {
+		entry.ID = s.nextEntryID
+		s.nextEntryID++
+	} else if _, ok := s.entries[entry.ID]; !ok {
+		return ErrWatchEntryNotFound
+	}
+
+	s.entries[entry.ID] = *entry
+	return nil
+}
+
+// GetEntry returns one entry
+func (s *MemoryWatchlistStore) GetEntry(id int) (*models.WatchEntry, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	entry, ok := s.entries[id]
+	if !ok {
+		return nil, ErrWatchEntryNotFound
+	}
+	return &entry, nil
+}
+
+// ListEntries returns all entries ordered by ID
+func (s *MemoryWatchlistStore) ListEntries() ([]models.WatchEntry, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	result := make([]models.WatchEntry, 0, len(s.entries))
+	for _, entry := range s.entries {
+		result = append(result, entry)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+	return result, nil
+}
+
+// DeleteEntry removes an entry and its transitions
+func (s *MemoryWatchlistStore) DeleteEntry(id int) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.entries[id]; !ok {
+		return ErrWatchEntryNotFound
+	}
+	delete(s.entries, id)
+
+	kept := s.transitions[:0]
+	for _, transition := range s.transitions {
+		if transition.EntryID != id {
+			kept = append(kept, transition)
+		}
+	}
+	s.transitions = kept
+	return nil
+}
+
+// AddTransition stores a transition and assigns it the next ID
+func (s *MemoryWatchlistStore) AddTransition(transition *models.WatchTransition) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	transition.ID = s.nextTransitionID
+	s.nextTransitionID++
+	s.transitions = append(s.transitions, *transition)
+	return nil
+}
+
+// ListTransitions returns up to limit transitions, newest first
+func (s *MemoryWatchlistStore) ListTransitions(entryID int, limit int) ([]models.WatchTransition, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	result := []models.WatchTransition{}
+	for i := len(s.transitions) - 1; i >= 0 && len(result) < limit; i-- {
+		if entryID == 0 || s.transitions[i].EntryID == entryID {
+			result = append(result, s.transitions[i])
+		}
+	}
+	return result, nil
+}
+
+// Close does nothing for the in-memory store
+func (s *MemoryWatchlistStore) Close() error {
+	return nil
+}
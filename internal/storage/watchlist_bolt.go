@@ -0,0 +1,203 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"domaincheck/internal/models"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	// watchEntriesBucket holds watch entries keyed by their big-endian ID
+	watchEntriesBucket = []byte("watch_entries")
+	// watchTransitionsBucket holds status transitions keyed by their big-endian ID
+	watchTransitionsBucket = []byte("watch_transitions")
+)
+
+// BoltWatchlistStore keeps the watchlist in an embedded bbolt database file
+type BoltWatchlistStore struct {
+	db *bolt.DB
+}
+
+// NewBoltWatchlistStore opens (or creates) a bbolt watchlist database
+func NewBoltWatchlistStore(path string) (*BoltWatchlistStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create watchlist directory: %w", err)
+	}
+
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open watchlist database: %w", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{watchEntriesBucket, watchTransitionsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize watchlist database: %w", err)
+	}
+
+	return &BoltWatchlistStore{db: db}, nil
+}
+
+// SaveEntry stores an entry, assigning it the next ID if it has none
+func (s *BoltWatchlistStore) SaveEntry(entry *models.WatchEntry) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(watchEntriesBucket)
+
+		if entry.ID == 0 {
+			id, err := bucket.NextSequence()
+			if err != nil {
+				return fmt.Errorf("failed to allocate watch entry ID: %w", err)
+			}
+			entry.ID = int(id)
+		} else if bucket.Get(sequenceKey(uint64(entry.ID))) == nil {
+			return ErrWatchEntryNotFound
+		}
+
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("failed to encode watch entry: %w", err)
+		}
+		if err := bucket.Put(sequenceKey(uint64(entry.ID)), data); err != nil {
+			return fmt.Errorf("failed to store watch entry: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetEntry returns one entry
+func (s *BoltWatchlistStore) GetEntry(id int) (*models.WatchEntry, error) {
+	var entry models.WatchEntry
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(watchEntriesBucket).Get(sequenceKey(uint64(id)))
+		if data == nil {
+			return ErrWatchEntryNotFound
+		}
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return fmt.Errorf("failed to decode watch entry: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// ListEntries returns all entries ordered by ID
+func (s *BoltWatchlistStore) ListEntries() ([]models.WatchEntry, error) {
+	result := []models.WatchEntry{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(watchEntriesBucket).ForEach(func(k, v []byte) error {
+			var entry models.WatchEntry
+			if err := json.Unmarshal(v, &entry); err != nil {
+				return fmt.Errorf("failed to decode watch entry: %w", err)
+			}
+			result = append(result, entry)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DeleteEntry removes an entry and its transitions
+func (s *BoltWatchlistStore) DeleteEntry(id int) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		entries := tx.Bucket(watchEntriesBucket)
+		key := sequenceKey(uint64(id))
+		if entries.Get(key) == nil {
+			return ErrWatchEntryNotFound
+		}
+		if err := entries.Delete(key); err != nil {
+			return fmt.Errorf("failed to delete watch entry: %w", err)
+		}
+
+		// Collect keys first, deleting while iterating makes the cursor skip entries
+		transitions := tx.Bucket(watchTransitionsBucket)
+		var keys [][]byte
+		err := transitions.ForEach(func(k, v []byte) error {
+			var transition models.WatchTransition
+			if err := json.Unmarshal(v, &transition); err == nil && transition.EntryID == id {
+				keys = append(keys, k)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, k := range keys {
+			if err := transitions.Delete(k); err != nil {
+				return fmt.Errorf("failed to delete watch transition: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// AddTransition stores a transition under the bucket's persistent sequence
+func (s *BoltWatchlistStore) AddTransition(transition *models.WatchTransition) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(watchTransitionsBucket)
+
+		id, err := bucket.NextSequence()
+		if err != nil {
+			return fmt.Errorf("failed to allocate watch transition ID: %w", err)
+		}
+		transition.ID = int(id)
+
+		data, err := json.Marshal(transition)
+		if err != nil {
+			return fmt.Errorf("failed to encode watch transition: %w", err)
+		}
+		if err := bucket.Put(sequenceKey(id), data); err != nil {
+			return fmt.Errorf("failed to store watch transition: %w", err)
+		}
+		return nil
+	})
+}
+
+// ListTransitions returns up to limit transitions, newest first
+func (s *BoltWatchlistStore) ListTransitions(entryID int, limit int) ([]models.WatchTransition, error) {
+	result := []models.WatchTransition{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(watchTransitionsBucket).Cursor()
+		for k, v := cursor.Last(); k != nil && len(result) < limit; k, v = cursor.Prev() {
+			var transition models.WatchTransition
+			if err := json.Unmarshal(v, &transition); err != nil {
+				return fmt.Errorf("failed to decode watch transition: %w", err)
+			}
+			if entryID == 0 || transition.EntryID == entryID {
+				result = append(result, transition)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Close closes the database file
+func (s *BoltWatchlistStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,223 @@
+package storage
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"domaincheck/internal/models"
+)
+
+// ErrWebhookNotFound is returned for an unknown webhook ID
+var ErrWebhookNotFound = errors.New("webhook not found")
+
+// ErrDeliveryNotFound is returned for an unknown webhook delivery ID
+var ErrDeliveryNotFound = errors.New("webhook delivery not found")
+
+// WebhookStore persists registered webhooks and their delivery log
+type WebhookStore interface {
+	// SaveWebhook stores a webhook, assigning it the next ID if it has none
+	SaveWebhook(webhook *models.Webhook) error
+	// GetWebhook returns one webhook or ErrWebhookNotFound
+	GetWebhook(id int) (*models.Webhook, error)
+	// ListWebhooks returns all webhooks ordered by ID
+	ListWebhooks() ([]models.Webhook, error)
+	// DeleteWebhook removes a webhook and its deliveries
+	DeleteWebhook(id int) error
+	// SaveDelivery stores a delivery, assigning it the next ID if it has none
+	SaveDelivery(delivery *models.WebhookDelivery) error
+	// GetDelivery returns one delivery or ErrDeliveryNotFound
+	GetDelivery(id int) (*models.WebhookDelivery, error)
+	// ListDeliveries returns up to limit deliveries of a webhook, newest first.
+	// A webhookID of zero returns the deliveries of all webhooks.
+	ListDeliveries(webhookID int, limit int) ([]models.WebhookDelivery, error)
+	// PendingDeliveries returns the deliveries that still have attempts left
+	PendingDeliveries() ([]models.WebhookDelivery, error)
+	// PruneDeliveries removes finished deliveries created before the given time
+	PruneDeliveries(before time.Time) error
+	// Close releases the resources held by the store
+	Close() error
+}
+
+// NewWebhookStore creates a bolt webhook store at path, or an in-memory one if path is empty
+func NewWebhookStore(path string) (WebhookStore, error) {
+	if path == "" {
+		return NewMemoryWebhookStore(), nil
+	}
+	return NewBoltWebhookStore(path)
+}
+
+// MemoryWebhookStore keeps webhooks in memory; they are lost on restart
+type MemoryWebhookStore struct {
+	webhooks       map[int]models.Webhook
+	deliveries     []models.WebhookDelivery // oldest first
+	nextWebhookID  int
+	nextDeliveryID int
+	mutex          sync.RWMutex
+}
+
+// NewMemoryWebhookStore creates a new in-memory webhook store
+func NewMemoryWebhookStore() *MemoryWebhookStore {
+	return &MemoryWebhookStore{
+		webhooks:       make(map[int]models.Webhook),
+		nextWebhookID:  1,
+		nextDeliveryID: 1,
+	}
+}
+
+// SaveWebhook stores a webhook, assigning it the next ID if it has none
+func (s *MemoryWebhookStore) SaveWebhook(webhook *models.Webhook) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if webhook.ID == 0 {
+		webhook.ID = s.nextWebhookID
+		s.nextWebhookID++
+	} else if _, ok := s.webhooks[webhook.ID]; !ok {
+		return ErrWebhookNotFound
+	}
+
+	s.webhooks[webhook.ID] = *webhook
+	return nil
+}
+
+// GetWebhook returns one webhook
+func (s *MemoryWebhookStore) GetWebhook(id int) (*models.Webhook, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	webhook, ok := s.webhooks[id]
+	if !ok {
+		return nil, ErrWebhookNotFound
+	}
+	return &webhook, nil
+}
+
+// ListWebhooks returns all webhooks ordered by ID
+func (s *MemoryWebhookStore) ListWebhooks() ([]models.Webhook, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	result := make([]models.Webhook, 0, len(s.webhooks))
+	for _, webhook := range s.webhooks {
+		result = append(result, webhook)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+	return result, nil
+}
+
+// DeleteWebhook removes a webhook and its deliveries
+func (s *MemoryWebhookStore) DeleteWebhook(id int) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.webhooks[id]; !ok {
+		return ErrWebhookNotFound
+	}
+	delete(s.webhooks, id)
+
+	kept := s.deliveries[:0]
+	for _, delivery := range s.deliveries {
+		if delivery.WebhookID != id {
+			kept = append(kept, delivery)
+		}
+	}
+	s.deliveries = kept
+	return nil
+}
+
+// SaveDelivery stores a delivery, assigning it the next ID if it has none
+func (s *MemoryWebhookStore) SaveDelivery(delivery *models.WebhookDelivery) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if delivery.ID == 0 {
+		delivery.ID = s.nextDeliveryID
+		s.nextDeliveryID++
+		s.deliveries = append(s.deliveries, *delivery)
+		return nil
+	}
+
+	i := s.deliveryIndex(delivery.ID)
+	if i < 0 {
+		return ErrDeliveryNotFound
+	}
+	s.deliveries[i] = *delivery
+	return nil
+}
+
+// GetDelivery returns one delivery
+func (s *MemoryWebhookStore) GetDelivery(id int) (*models.WebhookDelivery, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	i := s.deliveryIndex(id)
+	if i < 0 {
+		return nil, ErrDeliveryNotFound
+	}
+	delivery := s.deliveries[i]
+	return &delivery, nil
+}
+
+// ListDeliveries returns up to limit deliveries, newest first
+func (s *MemoryWebhookStore) ListDeliveries(webhookID int, limit int) ([]models.WebhookDelivery, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	result := []models.WebhookDelivery{}
+	for i := len(s.deliveries) - 1; i >= 0 && len(result) < limit; i-- {
+		if webhookID == 0 || s.deliveries[i].WebhookID == webhookID {
+			result = append(result, s.deliveries[i])
+		}
+	}
+	return result, nil
+}
+
+// PendingDeliveries returns the deliveries that still have attempts left
+func (s *MemoryWebhookStore) PendingDeliveries() ([]models.WebhookDelivery, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	result := []models.WebhookDelivery{}
+	for _, delivery := range s.deliveries {
+		if delivery.Status == models.DeliveryStatusPending {
+			result = append(result, delivery)
+		}
+	}
+	return result, nil
+}
+
+// PruneDeliveries removes finished deliveries created before the given time
+func (s *MemoryWebhookStore) PruneDeliveries(before time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	kept := s.deliveries[:0]
+	for _, delivery := range s.deliveries {
+		if delivery.Status == models.DeliveryStatusPending || !delivery.CreatedAt.Before(before) {
+			kept = append(kept, delivery)
+		}
+	}
+	s.deliveries = kept
+	return nil
+}
+
+// Close does nothing for the in-memory store
+func (s *MemoryWebhookStore) Close() error {
+	return nil
+}
+
+// deliveryIndex finds a delivery by ID; deliveries are ordered by ID.
+// The caller holds the lock.
+func (s *MemoryWebhookStore) deliveryIndex(id int) int {
+	i := sort.Search(len(s.deliveries), func(i int) bool {
+		return s.deliveries[i].ID >= id
+	})
+	if i < len(s.deliveries) && s.deliveries[i].ID == id {
+		return i
+	}
+	return -1
+}
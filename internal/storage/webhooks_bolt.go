@@ -0,0 +1,264 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"domaincheck/internal/models"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	// webhooksBucket holds webhooks keyed by their big-endian ID
+	webhooksBucket = []byte("webhooks")
+	// deliveriesBucket holds webhook deliveries keyed by their big-endian ID
+	deliveriesBucket = []byte("webhook_deliveries")
+)
+
+// BoltWebhookStore keeps webhooks and their deliveries in an embedded bbolt database file
+type BoltWebhookStore struct {
+	db *bolt.DB
+}
+
+// NewBoltWebhookStore opens (or creates) a bbolt webhook database
+func NewBoltWebhookStore(path string) (*BoltWebhookStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create webhook directory: %w", err)
+	}
+
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open webhook database: %w", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{webhooksBucket, deliveriesBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize webhook database: %w", err)
+	}
+
+	return &BoltWebhookStore{db: db}, nil
+}
+
+// SaveWebhook stores a webhook, assigning it the next ID if it has none
+func (s *BoltWebhookStore) SaveWebhook(webhook *models.Webhook) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(webhooksBucket)
+
+		if webhook.ID == 0 {
+			id, err := bucket.NextSequence()
+			if err != nil {
+				return fmt.Errorf("failed to allocate webhook ID: %w", err)
+			}
+			webhook.ID = int(id)
+		} else if bucket.Get(sequenceKey(uint64(webhook.ID))) == nil {
+			return ErrWebhookNotFound
+		}
+
+		data, err := json.Marshal(webhook)
+		if err != nil {
+			return fmt.Errorf("failed to encode webhook: %w", err)
+		}
+		if err := bucket.Put(sequenceKey(uint64(webhook.ID)), data); err != nil {
+			return fmt.Errorf("failed to store webhook: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetWebhook returns one webhook
+func (s *BoltWebhookStore) GetWebhook(id int) (*models.Webhook, error) {
+	var webhook models.Webhook
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(webhooksBucket).Get(sequenceKey(uint64(id)))
+		if data == nil {
+			return ErrWebhookNotFound
+		}
+		if err := json.Unmarshal(data, &webhook); err != nil {
+			return fmt.Errorf("failed to decode webhook: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
+// ListWebhooks returns all webhooks ordered by ID
+func (s *BoltWebhookStore) ListWebhooks() ([]models.Webhook, error) {
+	result := []models.Webhook{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(webhooksBucket).ForEach(func(k, v []byte) error {
+			var webhook models.Webhook
+			if err := json.Unmarshal(v, &webhook); err != nil {
+				return fmt.Errorf("failed to decode webhook: %w", err)
+			}
+			result = append(result, webhook)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DeleteWebhook removes a webhook and its deliveries
+func (s *BoltWebhookStore) DeleteWebhook(id int) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		webhooks := tx.Bucket(webhooksBucket)
+		key := sequenceKey(uint64(id))
+		if webhooks.Get(key) == nil {
+			return ErrWebhookNotFound
+		}
+		if err := webhooks.Delete(key); err != nil {
+			return fmt.Errorf("failed to delete webhook: %w", err)
+		}
+
+		return deleteDeliveries(tx.Bucket(deliveriesBucket), func(delivery *models.WebhookDelivery) bool {
+			return delivery.WebhookID == id
+		})
+	})
+}
+
+// SaveDelivery stores a delivery, assigning it the next ID if it has none
+func (s *BoltWebhookStore) SaveDelivery(delivery *models.WebhookDelivery) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(deliveriesBucket)
+
+		if delivery.ID == 0 {
+			id, err := bucket.NextSequence()
+			if err != nil {
+				return fmt.Errorf("failed to allocate delivery ID: %w", err)
+			}
+			delivery.ID = int(id)
+		} else if bucket.Get(sequenceKey(uint64(delivery.ID))) == nil {
+			return ErrDeliveryNotFound
+		}
+
+		data, err := json.Marshal(delivery)
+		if err != nil {
+			return fmt.Errorf("failed to encode delivery: %w", err)
+		}
+		if err := bucket.Put(sequenceKey(uint64(delivery.ID)), data); err != nil {
+			return fmt.Errorf("failed to store delivery: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetDelivery returns one delivery
+func (s *BoltWebhookStore) GetDelivery(id int) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(deliveriesBucket).Get(sequenceKey(uint64(id)))
+		if data == nil {
+			return ErrDeliveryNotFound
+		}
+		if err := json.Unmarshal(data, &delivery); err != nil {
+			return fmt.Errorf("failed to decode delivery: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+// ListDeliveries returns up to limit deliveries, newest first
+func (s *BoltWebhookStore) ListDeliveries(webhookID int, limit int) ([]models.WebhookDelivery, error) {
+	result := []models.WebhookDelivery{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(deliveriesBucket).Cursor()
+		for k, v := cursor.Last(); k != nil && len(result) < limit; k, v = cursor.Prev() {
+			var delivery models.WebhookDelivery
+			if err := json.Unmarshal(v, &delivery); err != nil {
+				return fmt.Errorf("failed to decode delivery: %w", err)
+			}
+			if webhookID == 0 || delivery.WebhookID == webhookID {
+				result = append(result, delivery)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// PendingDeliveries returns the deliveries that still have attempts left
+func (s *BoltWebhookStore) PendingDeliveries() ([]models.WebhookDelivery, error) {
+	result := []models.WebhookDelivery{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(deliveriesBucket).ForEach(func(k, v []byte) error {
+			var delivery models.WebhookDelivery
+			if err := json.Unmarshal(v, &delivery); err != nil {
+				return fmt.Errorf("failed to decode delivery: %w", err)
+			}
+			if delivery.Status == models.DeliveryStatusPending {
+				result = append(result, delivery)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// PruneDeliveries removes finished deliveries created before the given time
+func (s *BoltWebhookStore) PruneDeliveries(before time.Time) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return deleteDeliveries(tx.Bucket(deliveriesBucket), func(delivery *models.WebhookDelivery) bool {
+			return delivery.Status != models.DeliveryStatusPending && delivery.CreatedAt.Before(before)
+		})
+	})
+}
+
+// Close closes the database file
+func (s *BoltWebhookStore) Close() error {
+	return s.db.Close()
+}
+
+// deleteDeliveries removes the deliveries matching a condition
+func deleteDeliveries(bucket *bolt.Bucket, match func(*models.WebhookDelivery) bool) error {
+	// Collect keys first, deleting while iterating makes the cursor skip entries
+	var keys [][]byte
+	err := bucket.ForEach(func(k, v []byte) error {
+		var delivery models.WebhookDelivery
+		if err := json.Unmarshal(v, &delivery); err == nil && match(&delivery) {
+			keys = append(keys, k)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, k := range keys {
+		if err := bucket.Delete(k); err != nil {
+			return fmt.Errorf("failed to delete delivery: %w", err)
+		}
+	}
+	return nil
+}
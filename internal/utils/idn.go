@@ -0,0 +1,157 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+)
+
+// idnaProfile converts domains for lookup with the UTS-46 mapping: upper case
+// and compatibility characters are folded, and labels with bad hyphens,
+// misplaced joiners or mixed bidi directions are rejected
+var idnaProfile = idna.Lookup
+
+// contextualRunes are punctuation characters IDNA2008 allows in U-labels
+// under contextual rules (RFC 5892, CONTEXTO)
+var contextualRunes = map[rune]bool{
+	0x0375: true, // Greek lower numeral sign
+	0x05F3: true, // Hebrew punctuation geresh
+	0x05F4: true, // Hebrew punctuation gershayim
+	0x30FB: true, // Katakana middle dot
+}
+
+// NormalizeDomain cleans domain input and returns the ASCII form used for
+// lookups, converting Unicode labels to A-labels, e.g. "xn--iek-qoa0b.com"
+// for "çiçek.com". Errors name the offending character where possible.
+func NormalizeDomain(domain string) (string, error) {
+	domain = SanitizeDomain(domain)
+	if domain == "" {
+		return "", fmt.Errorf("domain name is required")
+	}
+
+	// Plain ASCII names keep the historic validation
+	if isASCII(domain) && !strings.Contains(domain, "xn--") {
+		if !ValidateDomainFormat(domain) {
+			return "", fmt.Errorf("invalid domain format: %s", domain)
+		}
+		return domain, nil
+	}
+
+	ascii, err := toASCII(domain)
+	if err != nil {
+		return "", idnaError(domain, err)
+	}
+	if !ValidateDomainFormat(ascii) {
+		return "", fmt.Errorf("invalid domain format: %s", domain)
+	}
+	return ascii, nil
+}
+
+// NormalizeExtension returns the ASCII form of an extension with a leading
+// dot, e.g. ".xn--p1ai" for "рф", or an empty string if it is not valid
+func NormalizeExtension(extension string) string {
+	extension = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(extension)), ".")
+	if extension == "" {
+		return ""
+	}
+	ascii, err := toASCII(extension)
+	if err != nil || !ValidateDomainFormat(ascii) {
+		return ""
+	}
+	return "." + ascii
+}
+
+// UnicodeDomain returns the Unicode form of a domain, converting A-labels to
+// U-labels. Domains that cannot be converted are returned unchanged.
+func UnicodeDomain(domain string) string {
+	if !strings.Contains(domain, "xn--") {
+		return domain
+	}
+	unicodeName, err := idnaProfile.ToUnicode(domain)
+	if err != nil {
+		return domain
+	}
+	return unicodeName
+}
+
+// IsInternationalized reports whether a domain in ASCII form has an A-label
+func IsInternationalized(domain string) bool {
+	for _, label := range strings.Split(domain, ".") {
+		if strings.HasPrefix(label, "xn--") {
+			return true
+		}
+	}
+	return false
+}
+
+// toASCII converts a domain to A-labels and checks them against IDNA2008
+func toASCII(domain string) (string, error) {
+	ascii, err := idnaProfile.ToASCII(strings.TrimSuffix(domain, "."))
+	if err != nil {
+		return "", err
+	}
+	if !IsInternationalized(ascii) {
+		return ascii, nil
+	}
+	mapped, err := idnaProfile.ToUnicode(ascii)
+	if err != nil {
+		return "", err
+	}
+	if err := checkIDNA2008(mapped); err != nil {
+		return "", err
+	}
+	return ascii, nil
+}
+
+// checkIDNA2008 rejects code points IDNA2008 disallows, such as symbols and
+// punctuation, which the UTS-46 mapping still accepts
+func checkIDNA2008(domain string) error {
+	runes := []rune(domain)
+	for i, r := range runes {
+		switch {
+		case r < utf8.RuneSelf, r == 0x200C, r == 0x200D, contextualRunes[r]:
+			// ASCII and joiners are checked by the UTS-46 profile
+			continue
+		case unicode.In(r, unicode.Ll, unicode.Lo, unicode.Lm, unicode.Mn, unicode.Mc, unicode.Nd):
+			continue
+		case r == 0x00B7 && i > 0 && i+1 < len(runes) && runes[i-1] == 'l' && runes[i+1] == 'l':
+			// A middle dot is only allowed between two l's, as in Catalan
+			continue
+		}
+		return fmt.Errorf("disallowed rune %U", r)
+	}
+	return nil
+}
+
+// idnaError explains why a domain has no ASCII form, naming the first
+// character that is not allowed in a domain name on its own
+func idnaError(domain string, err error) error {
+	for _, r := range domain {
+		if r == '.' || unicode.Is(unicode.Mn, r) {
+			// Combining marks are only valid after another character
+			continue
+		}
+		label := string(r)
+		if r < utf8.RuneSelf {
+			// A hyphen is only valid inside a label
+			label = "a" + label + "a"
+		}
+		if _, runeErr := toASCII(label); runeErr != nil {
+			return fmt.Errorf("invalid domain %s: character %q (%U) is not allowed in domain names", domain, r, r)
+		}
+	}
+	return fmt.Errorf("invalid domain %s: %s", domain, strings.TrimPrefix(err.Error(), "idna: "))
+}
+
+// isASCII reports whether a string consists of ASCII characters only
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,186 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/idna"
+)
+
+// PublicSuffixList holds the ICANN section of the Public Suffix List
+// (https://publicsuffix.org), i.e. the suffixes under which registries
+// accept registrations. Rules of the private section, such as "github.io",
+// are ignored since names below them are not registered at a registry.
+type PublicSuffixList struct {
+	rules      map[string]bool // Suffixes, e.g. "co.uk"
+	wildcards  map[string]bool // Suffixes whose every child is a suffix, e.g. "ck" for "*.ck"
+	exceptions map[string]bool // Names exempted from a wildcard, e.g. "www.ck" for "!www.ck"
+}
+
+var (
+	publicSuffixList      *PublicSuffixList
+	publicSuffixListMutex sync.RWMutex
+)
+
+// LoadPublicSuffixList reads a list in the format of public_suffix_list.dat
+func LoadPublicSuffixList(path string) (*PublicSuffixList, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open public suffix list: %w", err)
+	}
+	defer file.Close()
+
+	return ParsePublicSuffixList(file)
+}
+
+// ParsePublicSuffixList parses a list in the format of public_suffix_list.dat
+func ParsePublicSuffixList(r io.Reader) (*PublicSuffixList, error) {
+	list := &PublicSuffixList{
+		rules:      make(map[string]bool),
+		wildcards:  make(map[string]bool),
+		exceptions: make(map[string]bool),
+	}
+
+	icann := false
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.Contains(line, "===BEGIN ICANN DOMAINS==="):
+			icann = true
+			continue
+		case strings.Contains(line, "===END ICANN DOMAINS==="):
+			icann = false
+			continue
+		case !icann || line == "" || strings.HasPrefix(line, "//"):
+			continue
+		}
+
+		// Only the first field is the rule
+		rule := strings.Fields(line)[0]
+
+		exception := strings.HasPrefix(rule, "!")
+		wildcard := strings.HasPrefix(rule, "*.")
+		rule = strings.TrimPrefix(strings.TrimPrefix(rule, "!"), "*.")
+
+		// Rules of internationalized suffixes are listed in Unicode
+		ascii, err := idna.Lookup.ToASCII(rule)
+		if err != nil {
+			continue
+		}
+
+		switch {
+		case exception:
+			list.exceptions[ascii] = true
+		case wildcard:
+			list.wildcards[ascii] = true
+		default:
+			list.rules[ascii] = true
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading public suffix list: %w", err)
+	}
+	if len(list.rules) == 0 {
+		return nil, fmt.Errorf("public suffix list contains no ICANN rules")
+	}
+
+	return list, nil
+}
+
+// Len returns the number of rules in the list
+func (l *PublicSuffixList) Len() int {
+	return len(l.rules) + len(l.wildcards) + len(l.exceptions)
+}
+
+// PublicSuffix returns the public suffix of a domain without a leading dot,
+// e.g. "co.uk" for "www.example.co.uk". Domains without a matching rule
+// fall back to their last label, as the list's default rule prescribes.
+func (l *PublicSuffixList) PublicSuffix(domain string) string {
+	domain = strings.TrimSuffix(strings.ToLower(domain), ".")
+	labels := strings.Split(domain, ".")
+
+	// The first match is the longest; exceptions are longer than their wildcard
+	for i := range labels {
+		candidate := strings.Join(labels[i:], ".")
+		if l.exceptions[candidate] {
+			return strings.Join(labels[i+1:], ".")
+		}
+		if l.rules[candidate] {
+			return candidate
+		}
+		if i+1 < len(labels) && l.wildcards[strings.Join(labels[i+1:], ".")] {
+			return candidate
+		}
+	}
+	return labels[len(labels)-1]
+}
+
+// IsPublicSuffix reports whether a name is a public suffix itself, e.g. "co.uk"
+func (l *PublicSuffixList) IsPublicSuffix(name string) bool {
+	name = strings.TrimSuffix(strings.TrimPrefix(strings.ToLower(name), "."), ".")
+	return name != "" && l.PublicSuffix(name) == name
+}
+
+// SetPublicSuffixList makes SplitDomain and ExtractDomainParts use a list.
+// Without one, the last label of a domain is its suffix.
+func SetPublicSuffixList(list *PublicSuffixList) {
+	publicSuffixListMutex.Lock()
+	defer publicSuffixListMutex.Unlock()
+	publicSuffixList = list
+}
+
+// CurrentPublicSuffixList returns the list set with SetPublicSuffixList, or nil
+func CurrentPublicSuffixList() *PublicSuffixList {
+	publicSuffixListMutex.RLock()
+	defer publicSuffixListMutex.RUnlock()
+	return publicSuffixList
+}
+
+// DomainParts is a domain split at its public suffix
+type DomainParts struct {
+	Subdomain string // Labels below the registrable domain, e.g. "blog"
+	Name      string // Label registered at the registry, e.g. "example"
+	Suffix    string // Public suffix with a leading dot, e.g. ".co.uk"
+}
+
+// RegistrableDomain returns the name registered at the registry, e.g.
+// "example.co.uk", or an empty string if the domain is a public suffix
+func (p DomainParts) RegistrableDomain() string {
+	if p.Name == "" {
+		return ""
+	}
+	return p.Name + p.Suffix
+}
+
+// SplitDomain splits a clean, lowercase domain into its subdomain labels,
+// registered name and public suffix
+func SplitDomain(domain string) DomainParts {
+	domain = strings.TrimSuffix(domain, ".")
+	if !strings.Contains(domain, ".") {
+		return DomainParts{Name: domain}
+	}
+
+	var suffix string
+	if list := CurrentPublicSuffixList(); list != nil {
+		suffix = list.PublicSuffix(domain)
+	} else {
+		suffix = domain[strings.LastIndex(domain, ".")+1:]
+	}
+	if suffix == domain {
+		return DomainParts{Suffix: "." + suffix}
+	}
+
+	rest := strings.TrimSuffix(domain, "."+suffix)
+	parts := DomainParts{Name: rest, Suffix: "." + suffix}
+	if lastDot := strings.LastIndex(rest, "."); lastDot != -1 {
+		parts.Subdomain = rest[:lastDot]
+		parts.Name = rest[lastDot+1:]
+	}
+	return parts
+}
@@ -22,7 +22,8 @@ func ValidateDomainFormat(domain string) bool {
 	return domainRegex.MatchString(domain)
 }
 
-// ExtractDomainParts extracts domain name and extension
+// ExtractDomainParts extracts domain name and extension. The extension is the
+// domain's public suffix (e.g. ".co.uk") and the name keeps any subdomain labels.
 func ExtractDomainParts(domain string) (string, string) {
 	// Clean domain
 	domain = strings.ToLower(strings.TrimSpace(domain))
@@ -30,16 +31,16 @@ func ExtractDomainParts(domain string) (string, string) {
 	domain = strings.TrimPrefix(domain, "https://")
 	domain = strings.TrimPrefix(domain, "www.")
 
-	// Find last dot
-	lastDot := strings.LastIndex(domain, ".")
-	if lastDot == -1 {
+	parts := SplitDomain(domain)
+	switch {
+	case parts.Suffix == "":
 		return domain, ""
+	case parts.Name == "":
+		// The domain is a public suffix itself
+		return "", parts.Suffix
 	}
 
-	name := domain[:lastDot]
-	extension := domain[lastDot:]
-
-	return name, extension
+	return strings.TrimSuffix(strings.TrimSuffix(domain, "."), parts.Suffix), parts.Suffix
 }
 
 // SanitizeDomain cleans and formats domain input